@@ -0,0 +1,159 @@
+// Command arena plays bot configurations against each other and reports
+// win/draw/loss with Elo estimates. Engines are given as specs:
+//
+//	heuristic              the built-in priority bot
+//	search:<depth>[:eval]  alpha-beta search with a named evaluator
+//
+// or as a JSON file of [{"name", "type", "depth", "eval"}] entries.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/arena"
+	"connect-four/internal/bot"
+)
+
+// engineConfig describes one entrant
+type engineConfig struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // heuristic | search
+	Depth int    `json:"depth"`
+	Eval  string `json:"eval"`
+}
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	engines := flag.String("engines", "heuristic,search:4,search:6", "comma-separated engine specs")
+	configPath := flag.String("config", "", "JSON file of engine configurations (overrides -engines)")
+	games := flag.Int("games", 1000, "games per pairing (colors swapped, so rounded up to even)")
+	openings := flag.Int("openings", 4, "random plies before the engines take over")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played in parallel")
+	seed := flag.Int64("seed", 1, "random seed; the same seed gives the same results")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	configs, err := loadConfigs(*engines, *configPath)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid engine configuration")
+	}
+
+	entrants := make([]arena.Entrant, 0, len(configs))
+	for _, c := range configs {
+		entrant, err := newEntrant(c)
+		if err != nil {
+			log.Fatal().Err(err).Str("engine", c.Name).Msg("Invalid engine configuration")
+		}
+		entrants = append(entrants, entrant)
+	}
+	if len(entrants) < 2 {
+		log.Fatal().Msg("At least two engines are needed")
+	}
+
+	start := time.Now()
+	report := arena.Run(entrants, arena.Config{
+		GamesPerPairing: *games,
+		OpeningMoves:    *openings,
+		Workers:         *workers,
+		Seed:            *seed,
+	})
+	log.Info().Dur("took", time.Since(start)).Int("engines", len(entrants)).Msg("Arena finished")
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+	printReport(report)
+}
+
+// loadConfigs reads engine configurations from a file or the specs flag
+func loadConfigs(specs, path string) ([]engineConfig, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var configs []engineConfig
+		if err := json.Unmarshal(data, &configs); err != nil {
+			return nil, err
+		}
+		return configs, nil
+	}
+
+	var configs []engineConfig
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parts := strings.Split(spec, ":")
+		c := engineConfig{Name: spec, Type: parts[0]}
+		if len(parts) > 1 {
+			depth, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("bad depth in %q", spec)
+			}
+			c.Depth = depth
+		}
+		if len(parts) > 2 {
+			c.Eval = parts[2]
+		}
+		configs = append(configs, c)
+	}
+	return configs, nil
+}
+
+// newEntrant builds an arena entrant from its configuration
+func newEntrant(c engineConfig) (arena.Entrant, error) {
+	switch c.Type {
+	case "heuristic":
+		return arena.Entrant{Name: c.Name, New: func() arena.Engine { return bot.NewBot() }}, nil
+	case "search":
+		if c.Depth < 1 {
+			return arena.Entrant{}, fmt.Errorf("search depth must be at least 1")
+		}
+		if c.Eval == "" {
+			c.Eval = bot.DefaultEvaluator
+		}
+		eval, ok := bot.Evaluators[c.Eval]
+		if !ok {
+			return arena.Entrant{}, fmt.Errorf("unknown evaluator %q", c.Eval)
+		}
+		depth := c.Depth
+		return arena.Entrant{Name: c.Name, New: func() arena.Engine { return bot.NewSearch(depth, eval) }}, nil
+	}
+	return arena.Entrant{}, fmt.Errorf("unknown engine type %q", c.Type)
+}
+
+func printReport(report arena.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENGINE\tW\tD\tL\tSCORE\tELO\t95% CI")
+	for _, s := range report.Standings {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f%%\t%+.0f\t[%+.0f, %+.0f]\n",
+			s.Name, s.Result.Wins, s.Result.Draws, s.Result.Losses, s.Result.Score()*100, s.Elo, s.EloLo, s.EloHi)
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PAIRING\tW\tD\tL\tELO\t95% CI")
+	for _, p := range report.Pairings {
+		fmt.Fprintf(w, "%s vs %s\t%d\t%d\t%d\t%+.0f\t[%+.0f, %+.0f]\n",
+			p.A, p.B, p.Result.Wins, p.Result.Draws, p.Result.Losses, p.Elo, p.EloLo, p.EloHi)
+	}
+	w.Flush()
+}
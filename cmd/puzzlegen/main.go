@@ -0,0 +1,149 @@
+// Command puzzlegen mines "win in N" puzzles from finished games. It replays
+// each stored game and keeps the first position where the side to move can
+// force a win in at least -min and at most -max of its own moves.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/database"
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/rating"
+	"connect-four/internal/repository"
+	"connect-four/internal/solver"
+	"connect-four/pkg/config"
+)
+
+func main() {
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	games := flag.Int("games", 500, "number of recent finished games to scan")
+	minMoves := flag.Int("min", 2, "shortest win to keep, in the solver's moves")
+	maxMoves := flag.Int("max", 3, "longest win to search for (search time grows quickly)")
+	minPly := flag.Int("min-ply", 8, "skip positions with fewer discs on the board")
+	unique := flag.Bool("unique", true, "only keep positions with a single winning first move")
+	dryRun := flag.Bool("dry-run", false, "report puzzles without storing them")
+	flag.Parse()
+
+	if *minMoves < 1 || *maxMoves < *minMoves || *maxMoves > 5 {
+		log.Fatal().Msg("need 1 <= -min <= -max <= 5")
+	}
+
+	cfg := config.Load()
+	db, err := database.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer database.Close(db)
+
+	if err := models.AutoMigrate(db); err != nil {
+		log.Fatal().Err(err).Msg("Failed to run database migrations")
+	}
+
+	ctx := context.Background()
+	gameRepo := repository.NewGameRepository(db)
+	puzzleRepo := repository.NewPuzzleRepository(db)
+
+	records, err := gameRepo.ListFinished(ctx, *games)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load games")
+	}
+
+	found, stored := 0, 0
+	for _, record := range records {
+		// Puzzles are classic positions on a flat board; other rules don't
+		// replay as drops
+		if (record.Variant != "" && record.Variant != string(game.VariantClassic)) || record.Cylinder {
+			continue
+		}
+		var moves []game.Move
+		if err := json.Unmarshal([]byte(record.Moves), &moves); err != nil {
+			log.Warn().Err(err).Str("gameId", record.ID.String()).Msg("Skipping game with unreadable moves")
+			continue
+		}
+
+		puzzle := mine(moves, *minMoves, *maxMoves, *minPly, *unique)
+		if puzzle == nil {
+			continue
+		}
+		found++
+
+		exists, err := puzzleRepo.Exists(ctx, puzzle.Position, puzzle.ToMove)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to check for duplicate puzzle")
+		}
+		if exists {
+			continue
+		}
+
+		id := record.ID
+		puzzle.SourceGameID = &id
+		log.Info().
+			Str("gameId", record.ID.String()).
+			Int("moves", puzzle.Moves).
+			Int("toMove", puzzle.ToMove).
+			Int("solution", puzzle.Solution).
+			Msg("Puzzle found")
+
+		if *dryRun {
+			continue
+		}
+		if err := puzzleRepo.Create(ctx, puzzle); err != nil {
+			log.Error().Err(err).Str("gameId", record.ID.String()).Msg("Failed to store puzzle")
+			continue
+		}
+		stored++
+	}
+
+	log.Info().Int("games", len(records)).Int("found", found).Int("stored", stored).Msg("Puzzle generation finished")
+}
+
+// mine replays a game and returns the first qualifying position, or nil
+func mine(moves []game.Move, minMoves, maxMoves, minPly int, unique bool) *models.Puzzle {
+	board := game.NewBoard()
+	for i, move := range moves {
+		if i >= minPly {
+			if puzzle := puzzleAt(board, move.Player, minMoves, maxMoves, unique); puzzle != nil {
+				return puzzle
+			}
+		}
+		if board.DropDisc(move.Column, move.Player) == -1 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// puzzleAt checks whether player, to move, has a forced win worth a puzzle
+func puzzleAt(board *game.Board, player game.Cell, minMoves, maxMoves int, unique bool) *models.Puzzle {
+	n := solver.WinIn(board, player, maxMoves)
+	if n < minMoves {
+		return nil
+	}
+	winning := solver.WinningMoves(board, player, n)
+	if len(winning) == 0 || (unique && len(winning) > 1) {
+		return nil
+	}
+
+	return &models.Puzzle{
+		Position: board.Encode(),
+		ToMove:   int(player),
+		Moves:    n,
+		Solution: winning[0],
+		Rating:   startingRating(n),
+	}
+}
+
+// startingRating guesses a puzzle's difficulty from its depth; ratings then
+// settle as players attempt it
+func startingRating(moves int) int {
+	return rating.DefaultRating + (moves-3)*200
+}
@@ -16,6 +16,7 @@ import (
 	"connect-four/internal/database"
 	"connect-four/internal/kafka"
 	"connect-four/internal/models"
+	"connect-four/internal/telemetry"
 	"connect-four/pkg/config"
 )
 
@@ -27,6 +28,12 @@ func main() {
 	cfg := config.Load()
 	log.Info().Str("port", cfg.ServerPort).Msg("Starting Connect Four server")
 
+	// Set up tracing before anything creates spans
+	shutdownTracing, err := telemetry.Init(context.Background(), cfg.TraceExporter, cfg.OTLPEndpoint, cfg.TraceServiceName)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+
 	// Connect to database
 	db, err := database.NewDB(cfg.DatabaseURL)
 	if err != nil {
@@ -101,5 +108,10 @@ func main() {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Flush any buffered spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Server exited properly")
 }
@@ -0,0 +1,174 @@
+// Package analysis reviews finished games move by move with the search bot,
+// marking inaccuracies, mistakes and blunders and the ply where the game was
+// decided.
+package analysis
+
+import (
+	"fmt"
+
+	"connect-four/internal/bot"
+	"connect-four/internal/game"
+)
+
+// Judgement grades a move against the best move in the same position
+type Judgement string
+
+const (
+	JudgementBest       Judgement = "best"
+	JudgementGood       Judgement = "good"
+	JudgementInaccuracy Judgement = "inaccuracy"
+	JudgementMistake    Judgement = "mistake"
+	JudgementBlunder    Judgement = "blunder" // throws away a win, or a position that wasn't lost
+)
+
+// Evaluation drops (in evaluator points) that make a move an inaccuracy or
+// a mistake when the outcome doesn't change. An open three is worth 5.
+const (
+	InaccuracyThreshold = 3
+	MistakeThreshold    = 8
+)
+
+// DefaultDepth is how many plies the search looks ahead from each position
+const DefaultDepth = 6
+
+// MoveAnalysis is the verdict on one move. Scores are from the mover's
+// point of view.
+type MoveAnalysis struct {
+	Ply        int       `json:"ply"` // 1-based
+	Player     int       `json:"player"`
+	Column     int       `json:"column"`
+	Score      int       `json:"score"`
+	BestColumn int       `json:"bestColumn"`
+	BestScore  int       `json:"bestScore"`
+	Judgement  Judgement `json:"judgement"`
+}
+
+// PlayerSummary counts a player's poor moves
+type PlayerSummary struct {
+	Player       int `json:"player"`
+	Inaccuracies int `json:"inaccuracies"`
+	Mistakes     int `json:"mistakes"`
+	Blunders     int `json:"blunders"`
+}
+
+// Report is the analysis of a whole game
+type Report struct {
+	Depth   int             `json:"depth"`
+	Moves   []MoveAnalysis  `json:"moves"`
+	Players []PlayerSummary `json:"players"`
+
+	// Ply after which the winner had a forced win for the rest of the game;
+	// 0 for drawn or unfinished games
+	DecidedPly int `json:"decidedPly,omitempty"`
+}
+
+// Analyze replays moves from an empty board and grades each one with a
+// search of the given depth
+func Analyze(moves []game.Move, depth int) (*Report, error) {
+	if depth < 1 {
+		depth = DefaultDepth
+	}
+	search := bot.NewSearch(depth, nil)
+	board := game.NewBoard()
+	report := &Report{
+		Depth:   depth,
+		Moves:   make([]MoveAnalysis, 0, len(moves)),
+		Players: []PlayerSummary{{Player: int(game.Player1)}, {Player: int(game.Player2)}},
+	}
+
+	// decided[i] is true when the position before move i is already a
+	// forced win for the player who ends up winning
+	decided := make([]bool, len(moves)+1)
+	var winner game.Cell
+
+	for i, m := range moves {
+		if m.Player != game.Player1 && m.Player != game.Player2 {
+			return nil, fmt.Errorf("ply %d: unknown player %d", i+1, m.Player)
+		}
+		if m.Type == game.MovePop {
+			return nil, fmt.Errorf("ply %d: pops can't be analyzed", i+1)
+		}
+		scores := search.ScoreMoves(board, m.Player)
+		played, ok := scores[m.Column]
+		if !ok {
+			return nil, fmt.Errorf("ply %d: column %d is not playable", i+1, m.Column)
+		}
+		best, bestScore := bestMove(scores)
+
+		judgement := judge(bestScore, played, m.Column == best)
+		report.Moves = append(report.Moves, MoveAnalysis{
+			Ply:        i + 1,
+			Player:     int(m.Player),
+			Column:     m.Column,
+			Score:      played,
+			BestColumn: best,
+			BestScore:  bestScore,
+			Judgement:  judgement,
+		})
+		report.count(m.Player, judgement)
+
+		row := board.DropDisc(m.Column, m.Player)
+		if board.WinsAt(row, m.Column, m.Player) {
+			winner = m.Player
+		}
+	}
+
+	if winner != game.Empty {
+		// The mover's best outcome tells whether the position was won
+		// for them or lost, from the winner's side
+		for i, m := range report.Moves {
+			outcome := bot.Outcome(m.BestScore)
+			decided[i] = (moves[i].Player == winner && outcome == 1) ||
+				(moves[i].Player != winner && outcome == -1)
+		}
+		decided[len(moves)] = true
+		report.DecidedPly = len(moves)
+		for i := len(moves) - 1; i >= 0 && decided[i]; i-- {
+			report.DecidedPly = i
+		}
+	}
+	return report, nil
+}
+
+// bestMove returns the highest scoring column, preferring the center on ties
+func bestMove(scores map[int]int) (int, int) {
+	best, bestScore := -1, 0
+	for _, col := range []int{3, 2, 4, 1, 5, 0, 6} {
+		score, ok := scores[col]
+		if ok && (best == -1 || score > bestScore) {
+			best, bestScore = col, score
+		}
+	}
+	return best, bestScore
+}
+
+// judge grades a move scoring played where the best move scores best
+func judge(best, played int, isBest bool) Judgement {
+	bestOutcome, playedOutcome := bot.Outcome(best), bot.Outcome(played)
+	switch {
+	case isBest || played >= best:
+		return JudgementBest
+	case playedOutcome < bestOutcome:
+		return JudgementBlunder
+	case bestOutcome != 0:
+		// Still won (a slower win) or already lost (a quicker loss)
+		return JudgementGood
+	case best-played >= MistakeThreshold:
+		return JudgementMistake
+	case best-played >= InaccuracyThreshold:
+		return JudgementInaccuracy
+	}
+	return JudgementGood
+}
+
+func (r *Report) count(player game.Cell, j Judgement) {
+	s := &r.Players[int(player)-1]
+	switch j {
+	case JudgementInaccuracy:
+		s.Inaccuracies++
+	case JudgementMistake:
+		s.Mistakes++
+	case JudgementBlunder:
+		s.Blunders++
+	}
+}
@@ -0,0 +1,72 @@
+package analysis
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+)
+
+func play(cols ...int) []game.Move {
+	moves := make([]game.Move, len(cols))
+	player := game.Player1
+	for i, col := range cols {
+		moves[i] = game.Move{Player: player, Column: col}
+		if player == game.Player1 {
+			player = game.Player2
+		} else {
+			player = game.Player1
+		}
+	}
+	return moves
+}
+
+func TestAnalyzeMissedBlock(t *testing.T) {
+	// Player1 builds 0-1-2 along the bottom; Player2 fails to block at 3
+	report, err := Analyze(play(0, 6, 1, 6, 2, 5, 3), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Moves) != 7 {
+		t.Fatalf("got %d moves, want 7", len(report.Moves))
+	}
+
+	missed := report.Moves[5]
+	if missed.Judgement != JudgementBlunder || missed.BestColumn != 3 {
+		t.Errorf("missed block: got %+v, want blunder with best column 3", missed)
+	}
+	if final := report.Moves[6]; final.Judgement != JudgementBest {
+		t.Errorf("winning move judged %s", final.Judgement)
+	}
+	if report.Players[1].Blunders != 1 {
+		t.Errorf("Player2 blunders = %d, want 1", report.Players[1].Blunders)
+	}
+	if report.DecidedPly != 6 {
+		t.Errorf("DecidedPly = %d, want 6", report.DecidedPly)
+	}
+}
+
+func TestAnalyzeRejectsIllegalMoves(t *testing.T) {
+	if _, err := Analyze(play(0, 0, 0, 0, 0, 0, 0), 2); err == nil {
+		t.Error("expected an error for a move into a full column")
+	}
+}
+
+func TestJudge(t *testing.T) {
+	cases := []struct {
+		best, played int
+		want         Judgement
+	}{
+		{10, 10, JudgementBest},
+		{10, 8, JudgementGood},
+		{10, 6, JudgementInaccuracy},
+		{10, 0, JudgementMistake},
+		{1_000_005, 3, JudgementBlunder},
+		{1_000_005, 1_000_003, JudgementGood},
+		{0, -1_000_002, JudgementBlunder},
+	}
+	for _, c := range cases {
+		if got := judge(c.best, c.played, false); got != c.want {
+			t.Errorf("judge(%d, %d) = %s, want %s", c.best, c.played, got, c.want)
+		}
+	}
+}
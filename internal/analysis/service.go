@@ -0,0 +1,127 @@
+package analysis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/repository"
+)
+
+// ErrQueueFull is returned when too many analyses are already waiting
+var ErrQueueFull = errors.New("analysis queue is full")
+
+// Games waiting for a worker before Request starts refusing
+const queueSize = 100
+
+// Service analyzes stored games on a pool of workers and caches each
+// report on its game record
+type Service struct {
+	repo    *repository.GameRepository
+	depth   int
+	workers int
+	jobs    chan uuid.UUID
+	stop    chan struct{}
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]bool // queued or running
+}
+
+// NewService creates an analysis service; call Start to run its workers
+func NewService(repo *repository.GameRepository, workers, depth int) *Service {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Service{
+		repo:    repo,
+		depth:   depth,
+		workers: workers,
+		jobs:    make(chan uuid.UUID, queueSize),
+		stop:    make(chan struct{}),
+		pending: make(map[uuid.UUID]bool),
+	}
+}
+
+// Start launches the workers
+func (s *Service) Start() {
+	for i := 0; i < s.workers; i++ {
+		go s.work()
+	}
+}
+
+// Stop halts the workers once they finish their current game
+func (s *Service) Stop() {
+	close(s.stop)
+}
+
+// Request queues a game for analysis. Asking again while it's queued or
+// running does nothing.
+func (s *Service) Request(gameID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending[gameID] {
+		return nil
+	}
+	select {
+	case s.jobs <- gameID:
+		s.pending[gameID] = true
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (s *Service) work() {
+	for {
+		select {
+		case <-s.stop:
+			return
+		case id := <-s.jobs:
+			s.analyze(context.Background(), id)
+			s.mu.Lock()
+			delete(s.pending, id)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// analyze runs one game and stores its report; failures are logged only,
+// so a later request tries again
+func (s *Service) analyze(ctx context.Context, id uuid.UUID) {
+	record, err := s.repo.GetByID(ctx, id)
+	if err != nil || record == nil {
+		log.Error().Err(err).Str("gameId", id.String()).Msg("Failed to load game for analysis")
+		return
+	}
+	if record.Analysis != nil {
+		return
+	}
+
+	var moves []game.Move
+	if err := json.Unmarshal([]byte(record.Moves), &moves); err != nil {
+		log.Error().Err(err).Str("gameId", id.String()).Msg("Failed to decode moves for analysis")
+		return
+	}
+	report, err := Analyze(moves, s.depth)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", id.String()).Msg("Failed to analyze game")
+		return
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", id.String()).Msg("Failed to encode analysis")
+		return
+	}
+	if err := s.repo.SaveAnalysis(ctx, id, string(data)); err != nil {
+		log.Error().Err(err).Str("gameId", id.String()).Msg("Failed to save analysis")
+		return
+	}
+	log.Info().Str("gameId", id.String()).Int("decidedPly", report.DecidedPly).Msg("Game analyzed")
+}
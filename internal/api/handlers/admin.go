@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"connect-four/internal/apikey"
+	"connect-four/internal/game"
+	"connect-four/internal/matchmaking"
+	"connect-four/internal/models"
+	"connect-four/internal/moderation"
+	"connect-four/internal/repository"
+	ws "connect-four/internal/websocket"
+)
+
+// AdminHandler handles operator requests against the live hub
+type AdminHandler struct {
+	hub        *ws.Hub
+	messages   *ws.MessageHandler
+	matchQueue *matchmaking.Queue
+	playerRepo *repository.PlayerRepository
+	banRepo    *repository.BanRepository
+	reportRepo *repository.ReportRepository
+	chatRepo   *repository.ChatRepository
+	policy     *moderation.Policy
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(hub *ws.Hub, messages *ws.MessageHandler, matchQueue *matchmaking.Queue, playerRepo *repository.PlayerRepository, banRepo *repository.BanRepository, reportRepo *repository.ReportRepository, chatRepo *repository.ChatRepository, policy *moderation.Policy) *AdminHandler {
+	return &AdminHandler{
+		hub:        hub,
+		messages:   messages,
+		matchQueue: matchQueue,
+		playerRepo: playerRepo,
+		banRepo:    banRepo,
+		reportRepo: reportRepo,
+		chatRepo:   chatRepo,
+		policy:     policy,
+	}
+}
+
+// ListGames handles GET /admin/games
+func (h *AdminHandler) ListGames(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.hub.ListSessions())
+}
+
+// GetQueue handles GET /admin/queue
+func (h *AdminHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.matchQueue.Snapshot())
+}
+
+// EndGame handles POST /admin/games/{id}/end
+// Body: {"result": "player1" | "player2" | "draw"}, or "player3" and
+// "player4" for multiplayer games
+func (h *AdminHandler) EndGame(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Result string `json:"result"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err = h.messages.ForceEndGame(r.Context(), id, game.GameResult(req.Result))
+	switch {
+	case errors.Is(err, ws.ErrGameNotFound):
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	case errors.Is(err, ws.ErrGameFinished):
+		http.Error(w, "Game already finished", http.StatusConflict)
+		return
+	case errors.Is(err, ws.ErrInvalidResult):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Failed to end game", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGameChat handles GET /admin/games/{id}/chat
+// Returns the stored chat and emotes of a live or finished game
+func (h *AdminHandler) GetGameChat(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	messages, err := h.chatRepo.GetByGameID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get chat", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// KickPlayer handles POST /admin/players/{username}/kick
+// Body (optional): {"reason": "..."}
+func (h *AdminHandler) KickPlayer(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+	if req.Reason == "" {
+		req.Reason = "Disconnected by an administrator"
+	}
+
+	h.matchQueue.RemovePlayer(username)
+	if err := h.hub.Kick(username, req.Reason); err != nil {
+		http.Error(w, "Player not connected", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListBans handles GET /admin/bans
+func (h *AdminHandler) ListBans(w http.ResponseWriter, r *http.Request) {
+	bans, err := h.banRepo.List(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to get bans", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bans)
+}
+
+// BanPlayer handles POST /admin/bans
+// Body: {"username": "...", "reason": "..."}
+func (h *AdminHandler) BanPlayer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Username == "" {
+		http.Error(w, "Username is required", http.StatusBadRequest)
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "Banned by an administrator"
+	}
+
+	if err := h.banRepo.Create(r.Context(), req.Username, req.Reason); err != nil {
+		http.Error(w, "Failed to ban player", http.StatusInternalServerError)
+		return
+	}
+
+	// Drop the player from matchmaking and the live hub
+	h.matchQueue.RemovePlayer(req.Username)
+	h.hub.Kick(req.Username, req.Reason)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnbanPlayer handles DELETE /admin/bans/{username}
+func (h *AdminHandler) UnbanPlayer(w http.ResponseWriter, r *http.Request) {
+	removed, err := h.banRepo.Delete(r.Context(), mux.Vars(r)["username"])
+	if err != nil {
+		http.Error(w, "Failed to unban player", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "Ban not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListReports handles GET /admin/reports
+// Query: status=open|reviewed (default all), limit (default 50, max 200)
+func (h *AdminHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	reports, err := h.reportRepo.List(r.Context(), models.ReportStatus(r.URL.Query().Get("status")), limit)
+	if err != nil {
+		http.Error(w, "Failed to get reports", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// Broadcast handles POST /admin/broadcast
+// Body: {"message": "..."}
+func (h *AdminHandler) Broadcast(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Message == "" {
+		http.Error(w, "Message is required", http.StatusBadRequest)
+		return
+	}
+
+	recipients := h.hub.BroadcastNotice(req.Message)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"recipients": recipients})
+}
+
+// botKeyResponse carries a bot account's API key, shown only once
+type botKeyResponse struct {
+	Username string `json:"username"`
+	APIKey   string `json:"apiKey"`
+}
+
+// CreateBot handles POST /admin/bots
+// Body: {"username": "..."}. The response holds the bot's API key, which
+// isn't stored and can't be shown again.
+func (h *AdminHandler) CreateBot(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policy.ValidateUsername(req.Username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	existing, err := h.playerRepo.GetByUsername(r.Context(), req.Username)
+	if err != nil {
+		http.Error(w, "Failed to create bot", http.StatusInternalServerError)
+		return
+	}
+	if existing != nil {
+		http.Error(w, "Username is taken", http.StatusConflict)
+		return
+	}
+
+	key, hash, err := apikey.Generate()
+	if err != nil {
+		http.Error(w, "Failed to create bot", http.StatusInternalServerError)
+		return
+	}
+	if _, err := h.playerRepo.CreateBot(r.Context(), req.Username, hash); err != nil {
+		http.Error(w, "Failed to create bot", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(botKeyResponse{Username: req.Username, APIKey: key})
+}
+
+// RotateBotKey handles POST /admin/bots/{username}/key
+// Issues a new API key and disconnects the bot; the old key stops working
+func (h *AdminHandler) RotateBotKey(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)["username"]
+
+	player, err := h.playerRepo.GetByUsername(r.Context(), username)
+	if err != nil {
+		http.Error(w, "Failed to rotate key", http.StatusInternalServerError)
+		return
+	}
+	if player == nil || !player.IsBot {
+		http.Error(w, "Bot not found", http.StatusNotFound)
+		return
+	}
+
+	key, hash, err := apikey.Generate()
+	if err != nil {
+		http.Error(w, "Failed to rotate key", http.StatusInternalServerError)
+		return
+	}
+	if err := h.playerRepo.SetAPIKey(r.Context(), player.ID, hash); err != nil {
+		http.Error(w, "Failed to rotate key", http.StatusInternalServerError)
+		return
+	}
+	h.matchQueue.RemovePlayer(username)
+	h.hub.Kick(username, "API key rotated")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(botKeyResponse{Username: username, APIKey: key})
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"connect-four/internal/correspondence"
+	"connect-four/internal/game"
+)
+
+// CorrespondenceHandler handles correspondence game HTTP requests. Calls
+// that act for a player identify them as the WebSocket endpoint does: bot
+// accounts by their API key, humans by the username in the body.
+type CorrespondenceHandler struct {
+	manager  *correspondence.Manager
+	identity *Identity
+}
+
+// NewCorrespondenceHandler creates a new correspondence game handler
+func NewCorrespondenceHandler(manager *correspondence.Manager, identity *Identity) *CorrespondenceHandler {
+	return &CorrespondenceHandler{manager: manager, identity: identity}
+}
+
+// Create handles POST /api/correspondence
+// Body: {"username": "...", "opponent": "...", "variant": "classic", "cylinder": false, "daysPerMove": 3}
+// The caller challenges the opponent, and moves first if they accept
+func (h *CorrespondenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username    string `json:"username"`
+		Opponent    string `json:"opponent"`
+		Variant     string `json:"variant"`
+		Cylinder    bool   `json:"cylinder"`
+		DaysPerMove int    `json:"daysPerMove"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !validUsername(req.Opponent) {
+		http.Error(w, "Invalid opponent", http.StatusBadRequest)
+		return
+	}
+	username, _, ok := h.identity.Resolve(w, r, req.Username)
+	if !ok {
+		return
+	}
+
+	g, err := h.manager.Create(r.Context(), correspondence.Settings{
+		Player1:     username,
+		Player2:     req.Opponent,
+		Setup:       game.Setup{Variant: game.Variant(req.Variant), Cylinder: req.Cylinder},
+		DaysPerMove: req.DaysPerMove,
+	})
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(g)
+}
+
+// List handles GET /api/correspondence?username=...
+// Returns the player's unfinished correspondence games, the one with the
+// least time left to move first, then their open challenges
+func (h *CorrespondenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	if !validUsername(username) {
+		http.Error(w, "Invalid username", http.StatusBadRequest)
+		return
+	}
+
+	games, err := h.manager.List(r.Context(), username)
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(games)
+}
+
+// Get handles GET /api/correspondence/{id}
+func (h *CorrespondenceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	g, err := h.manager.Get(r.Context(), id)
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// Move handles POST /api/games/{id}/moves
+// Body: {"username": "...", "column": 3, "type": "pop", "disc": "anvil"}
+// type and disc are optional, as in make_move; bots send their API key
+// instead of a username
+func (h *CorrespondenceHandler) Move(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Column   int    `json:"column"`
+		Type     string `json:"type"`
+		Disc     string `json:"disc"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	username, _, ok := h.identity.Resolve(w, r, req.Username)
+	if !ok {
+		return
+	}
+
+	action := game.Action{Type: game.MoveType(req.Type), Column: req.Column, Disc: game.Disc(req.Disc)}
+	g, err := h.manager.Move(r.Context(), id, username, action)
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// Accept handles POST /api/correspondence/{id}/accept
+// Body: {"username": "..."}; only the challenged player can accept
+func (h *CorrespondenceHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	id, username, ok := h.gamePlayer(w, r)
+	if !ok {
+		return
+	}
+
+	g, err := h.manager.Accept(r.Context(), id, username)
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// Decline handles POST /api/correspondence/{id}/decline
+// Body: {"username": "..."}; the challenger declining withdraws the challenge
+func (h *CorrespondenceHandler) Decline(w http.ResponseWriter, r *http.Request) {
+	id, username, ok := h.gamePlayer(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Decline(r.Context(), id, username); err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Resign handles POST /api/correspondence/{id}/resign
+// Body: {"username": "..."}
+func (h *CorrespondenceHandler) Resign(w http.ResponseWriter, r *http.Request) {
+	id, username, ok := h.gamePlayer(w, r)
+	if !ok {
+		return
+	}
+
+	g, err := h.manager.Resign(r.Context(), id, username)
+	if err != nil {
+		writeCorrespondenceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(g)
+}
+
+// gamePlayer reads the game ID and the player acting in that game, for
+// calls whose body only names the player. On failure the response has
+// been written.
+func (h *CorrespondenceHandler) gamePlayer(w http.ResponseWriter, r *http.Request) (uuid.UUID, string, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return uuid.Nil, "", false
+	}
+
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return uuid.Nil, "", false
+	}
+	username, _, ok := h.identity.Resolve(w, r, req.Username)
+	if !ok {
+		return uuid.Nil, "", false
+	}
+	return id, username, true
+}
+
+// writeCorrespondenceError maps correspondence errors to HTTP statuses
+func writeCorrespondenceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, correspondence.ErrNotFound),
+		errors.Is(err, correspondence.ErrPlayerNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, correspondence.ErrInvalidDays),
+		errors.Is(err, correspondence.ErrInvalidVariant),
+		errors.Is(err, correspondence.ErrSamePlayer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, correspondence.ErrNotPlayer),
+		errors.Is(err, correspondence.ErrNotChallenged):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, correspondence.ErrIllegalMove):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, correspondence.ErrNotYourTurn),
+		errors.Is(err, correspondence.ErrNotAccepted),
+		errors.Is(err, correspondence.ErrAnswered),
+		errors.Is(err, correspondence.ErrGameOver),
+		errors.Is(err, correspondence.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, correspondence.ErrUnavailable):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, "Correspondence request failed", http.StatusInternalServerError)
+	}
+}
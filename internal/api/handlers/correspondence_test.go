@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"connect-four/internal/apikey"
+	"connect-four/internal/models"
+	"connect-four/internal/moderation"
+)
+
+// fakeAccounts holds players by username and bot accounts by key hash
+type fakeAccounts struct {
+	players map[string]*models.Player
+	keys    map[string]*models.Player
+}
+
+func (a fakeAccounts) GetByUsername(_ context.Context, username string) (*models.Player, error) {
+	return a.players[username], nil
+}
+
+func (a fakeAccounts) GetByAPIKey(_ context.Context, keyHash string) (*models.Player, error) {
+	return a.keys[keyHash], nil
+}
+
+// fakeBans is the set of banned usernames
+type fakeBans map[string]bool
+
+func (b fakeBans) IsBanned(_ context.Context, username string) (bool, error) {
+	return b[username], nil
+}
+
+const botKey = "c4k_test-key"
+
+func newTestIdentity(t *testing.T) *Identity {
+	t.Helper()
+	policy, err := moderation.NewPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := &models.Player{ID: uuid.New(), Username: "deep-bot", IsBot: true}
+	banned := &models.Player{ID: uuid.New(), Username: "banned-bot", IsBot: true}
+	accounts := fakeAccounts{
+		players: map[string]*models.Player{
+			"alice":      {ID: uuid.New(), Username: "alice"},
+			"deep-bot":   bot,
+			"banned-bot": banned,
+		},
+		keys: map[string]*models.Player{
+			apikey.Hash(botKey):       bot,
+			apikey.Hash("banned-key"): banned,
+		},
+	}
+	return NewIdentity(accounts, fakeBans{"mallory": true, "banned-bot": true}, policy)
+}
+
+func TestIdentityResolve(t *testing.T) {
+	id := newTestIdentity(t)
+
+	tests := []struct {
+		name     string
+		key      string
+		username string
+		want     string
+		isBot    bool
+		status   int
+	}{
+		{"human by username", "", "alice", "alice", false, http.StatusOK},
+		{"new human", "", "newcomer", "newcomer", false, http.StatusOK},
+		{"bot by key", botKey, "", "deep-bot", true, http.StatusOK},
+		{"key wins over a claimed username", botKey, "alice", "deep-bot", true, http.StatusOK},
+		{"bot username without key", "", "deep-bot", "", false, http.StatusForbidden},
+		{"unknown key", "c4k_wrong", "deep-bot", "", false, http.StatusUnauthorized},
+		{"banned human", "", "mallory", "", false, http.StatusForbidden},
+		{"banned bot", "banned-key", "", "", false, http.StatusForbidden},
+		{"reserved username", "", "admin", "", false, http.StatusBadRequest},
+		{"no username", "", "", "", false, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.key != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			w := httptest.NewRecorder()
+			username, isBot, ok := id.Resolve(w, r, tt.username)
+			if ok != (tt.status == http.StatusOK) || username != tt.want || isBot != tt.isBot {
+				t.Errorf("Resolve = %q, %v, %v; want %q, %v", username, isBot, ok, tt.want, tt.isBot)
+			}
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestCorrespondenceRefusesImpersonation(t *testing.T) {
+	// No manager: a request that gets past the identity check panics
+	h := NewCorrespondenceHandler(nil, newTestIdentity(t))
+	router := mux.NewRouter()
+	router.HandleFunc("/api/correspondence", h.Create).Methods("POST")
+	router.HandleFunc("/api/games/{id}/moves", h.Move).Methods("POST")
+	router.HandleFunc("/api/correspondence/{id}/accept", h.Accept).Methods("POST")
+	router.HandleFunc("/api/correspondence/{id}/decline", h.Decline).Methods("POST")
+	router.HandleFunc("/api/correspondence/{id}/resign", h.Resign).Methods("POST")
+
+	gameID := uuid.New().String()
+	endpoints := []struct {
+		path string
+		body string // with %q for the username
+	}{
+		{"/api/correspondence", `{"username": %q, "opponent": "alice"}`},
+		{"/api/games/" + gameID + "/moves", `{"username": %q, "column": 3}`},
+		{"/api/correspondence/" + gameID + "/accept", `{"username": %q}`},
+		{"/api/correspondence/" + gameID + "/decline", `{"username": %q}`},
+		{"/api/correspondence/" + gameID + "/resign", `{"username": %q}`},
+	}
+	attempts := []struct {
+		name     string
+		key      string
+		username string
+		status   int
+	}{
+		{"as a bot without its key", "", "deep-bot", http.StatusForbidden},
+		{"with a made-up key", "c4k_guess", "deep-bot", http.StatusUnauthorized},
+		{"as a banned player", "", "mallory", http.StatusForbidden},
+		{"with a banned bot's key", "banned-key", "", http.StatusForbidden},
+		{"under a reserved name", "", "system", http.StatusBadRequest},
+		{"without a username", "", "", http.StatusBadRequest},
+	}
+
+	for _, e := range endpoints {
+		for _, a := range attempts {
+			t.Run(e.path+" "+a.name, func(t *testing.T) {
+				body := fmt.Sprintf(e.body, a.username)
+				r := httptest.NewRequest(http.MethodPost, e.path, strings.NewReader(body))
+				if a.key != "" {
+					r.Header.Set("Authorization", "Bearer "+a.key)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, r)
+				if w.Code != a.status {
+					t.Errorf("status %d, want %d (%s)", w.Code, a.status, strings.TrimSpace(w.Body.String()))
+				}
+			})
+		}
+	}
+}
@@ -7,18 +7,25 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
 
+	"connect-four/internal/analysis"
+	"connect-four/internal/bot"
+	"connect-four/internal/game"
+	"connect-four/internal/moderation"
 	"connect-four/internal/repository"
 )
 
 // PlayerHandler handles player-related HTTP requests
 type PlayerHandler struct {
-	repo *repository.PlayerRepository
+	repo    *repository.PlayerRepository
+	banRepo *repository.BanRepository
+	policy  *moderation.Policy
 }
 
 // NewPlayerHandler creates a new player handler
-func NewPlayerHandler(repo *repository.PlayerRepository) *PlayerHandler {
-	return &PlayerHandler{repo: repo}
+func NewPlayerHandler(repo *repository.PlayerRepository, banRepo *repository.BanRepository, policy *moderation.Policy) *PlayerHandler {
+	return &PlayerHandler{repo: repo, banRepo: banRepo, policy: policy}
 }
 
 // CreateOrGet handles POST /api/players
@@ -33,17 +40,23 @@ func (h *PlayerHandler) CreateOrGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Username == "" {
-		http.Error(w, "Username is required", http.StatusBadRequest)
+	if err := h.policy.ValidateUsername(req.Username); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if len(req.Username) > 50 {
-		http.Error(w, "Username too long (max 50 chars)", http.StatusBadRequest)
+	banned, err := h.banRepo.IsBanned(r.Context(), req.Username)
+	if err != nil {
+		log.Error().Err(err).Str("username", req.Username).Msg("Failed to check ban list")
+		http.Error(w, "Failed to create player", http.StatusInternalServerError)
+		return
+	}
+	if banned {
+		http.Error(w, "Username is banned", http.StatusForbidden)
 		return
 	}
 
-	player, err := h.repo.Create(req.Username)
+	player, err := h.repo.Create(r.Context(), req.Username)
 	if err != nil {
 		http.Error(w, "Failed to create player", http.StatusInternalServerError)
 		return
@@ -64,7 +77,7 @@ func (h *PlayerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	player, err := h.repo.GetByID(id)
+	player, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Failed to get player", http.StatusInternalServerError)
 		return
@@ -100,7 +113,7 @@ func (h *LeaderboardHandler) GetTopPlayers(w http.ResponseWriter, r *http.Reques
 		}
 	}
 
-	entries, err := h.repo.GetTopPlayers(limit)
+	entries, err := h.repo.GetTopPlayers(r.Context(), limit)
 	if err != nil {
 		http.Error(w, "Failed to get leaderboard", http.StatusInternalServerError)
 		return
@@ -114,11 +127,12 @@ func (h *LeaderboardHandler) GetTopPlayers(w http.ResponseWriter, r *http.Reques
 type GameHandler struct {
 	repo       *repository.GameRepository
 	playerRepo *repository.PlayerRepository
+	analyses   *analysis.Service
 }
 
 // NewGameHandler creates a new game handler
-func NewGameHandler(repo *repository.GameRepository, playerRepo *repository.PlayerRepository) *GameHandler {
-	return &GameHandler{repo: repo, playerRepo: playerRepo}
+func NewGameHandler(repo *repository.GameRepository, playerRepo *repository.PlayerRepository, analyses *analysis.Service) *GameHandler {
+	return &GameHandler{repo: repo, playerRepo: playerRepo, analyses: analyses}
 }
 
 // GetByID handles GET /api/games/{id}
@@ -132,7 +146,7 @@ func (h *GameHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	game, err := h.repo.GetByID(id)
+	game, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Failed to get game", http.StatusInternalServerError)
 		return
@@ -147,6 +161,85 @@ func (h *GameHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(game)
 }
 
+// GetEvaluations handles GET /api/games/{id}/evaluations
+// Returns, for each move of a finished game, the move the bot would have
+// played there and why
+func (h *GameHandler) GetEvaluations(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get game", http.StatusInternalServerError)
+		return
+	}
+	if record == nil {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	if record.InProgress() {
+		http.Error(w, "Game is still being played", http.StatusConflict)
+		return
+	}
+	if (record.Variant != "" && record.Variant != string(game.VariantClassic)) || record.Cylinder {
+		http.Error(w, "Only classic games can be evaluated", http.StatusUnprocessableEntity)
+		return
+	}
+
+	var moves []game.Move
+	if err := json.Unmarshal([]byte(record.Moves), &moves); err != nil {
+		http.Error(w, "Game has no readable moves", http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bot.NewBot().EvaluateGame(moves))
+}
+
+// GetAnalysis handles GET /api/games/{id}/analysis
+// Returns the cached analysis report, or 202 Accepted while it's being run
+func (h *GameHandler) GetAnalysis(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid game ID", http.StatusBadRequest)
+		return
+	}
+
+	record, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get game", http.StatusInternalServerError)
+		return
+	}
+	if record == nil {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	if record.InProgress() {
+		http.Error(w, "Game is still being played", http.StatusConflict)
+		return
+	}
+	if (record.Variant != "" && record.Variant != string(game.VariantClassic)) || record.Cylinder {
+		http.Error(w, "Only classic games can be analyzed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if record.Analysis != nil {
+		w.Write([]byte(*record.Analysis))
+		return
+	}
+
+	if err := h.analyses.Request(id); err != nil {
+		http.Error(w, "Analysis is busy, try again later", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"status": "pending"})
+}
+
 // GetPlayerGames handles GET /api/players/{id}/games
 func (h *GameHandler) GetPlayerGames(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -164,7 +257,7 @@ func (h *GameHandler) GetPlayerGames(w http.ResponseWriter, r *http.Request) {
 		limit = l
 	}
 
-	games, err := h.repo.GetByPlayerID(id, limit)
+	games, err := h.repo.GetByPlayerID(r.Context(), id, limit)
 	if err != nil {
 		http.Error(w, "Failed to get games", http.StatusInternalServerError)
 		return
@@ -173,3 +266,54 @@ func (h *GameHandler) GetPlayerGames(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(games)
 }
+
+// SeriesHandler handles best-of-N series HTTP requests
+type SeriesHandler struct {
+	repo *repository.SeriesRepository
+}
+
+// NewSeriesHandler creates a new series handler
+func NewSeriesHandler(repo *repository.SeriesRepository) *SeriesHandler {
+	return &SeriesHandler{repo: repo}
+}
+
+// GetByID handles GET /api/series/{id}
+// Returns the score, status and games of a series
+func (h *SeriesHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid series ID", http.StatusBadRequest)
+		return
+	}
+
+	series, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get series", http.StatusInternalServerError)
+		return
+	}
+
+	if series == nil {
+		http.Error(w, "Series not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(series)
+}
+
+// EngineHandler lists the bot engines players can choose
+type EngineHandler struct {
+	engines *bot.Registry
+}
+
+// NewEngineHandler creates a new engine handler
+func NewEngineHandler(engines *bot.Registry) *EngineHandler {
+	return &EngineHandler{engines: engines}
+}
+
+// List handles GET /api/engines
+// Returns the names accepted as "engine" in join_queue
+func (h *EngineHandler) List(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.engines.Names())
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/apikey"
+	"connect-four/internal/models"
+	"connect-four/internal/moderation"
+)
+
+// Accounts looks up the players identities belong to
+type Accounts interface {
+	GetByUsername(ctx context.Context, username string) (*models.Player, error)
+	GetByAPIKey(ctx context.Context, keyHash string) (*models.Player, error)
+}
+
+// BanList reports banned usernames
+type BanList interface {
+	IsBanned(ctx context.Context, username string) (bool, error)
+}
+
+// Identity works out who is playing: a bot account by the API key in
+// "Authorization: Bearer <api key>", or a human by the username they give.
+// Bot usernames can't be claimed without the key, human usernames must
+// pass the username policy, and banned usernames are turned away.
+type Identity struct {
+	accounts Accounts
+	bans     BanList
+	policy   *moderation.Policy
+}
+
+// NewIdentity creates an identity resolver
+func NewIdentity(accounts Accounts, bans BanList, policy *moderation.Policy) *Identity {
+	return &Identity{accounts: accounts, bans: bans, policy: policy}
+}
+
+// Resolve returns who is making r: the bot whose API key it carries, in
+// which case username is ignored, or else the human username. On failure
+// the response has been written.
+func (id *Identity) Resolve(w http.ResponseWriter, r *http.Request, username string) (_ string, isBot, ok bool) {
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		bot, err := id.BotByKey(r.Context(), key)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to look up API key")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return "", false, false
+		}
+		if bot == "" {
+			http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			return "", false, false
+		}
+		username, isBot = bot, true
+	} else {
+		if username == "" {
+			http.Error(w, "Username required", http.StatusBadRequest)
+			return "", false, false
+		}
+		if err := id.policy.ValidateUsername(username); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return "", false, false
+		}
+
+		player, err := id.accounts.GetByUsername(r.Context(), username)
+		if err != nil {
+			log.Error().Err(err).Str("username", username).Msg("Failed to look up player")
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return "", false, false
+		}
+		if player != nil && player.IsBot {
+			http.Error(w, "Username belongs to a bot account", http.StatusForbidden)
+			return "", false, false
+		}
+	}
+
+	banned, err := id.bans.IsBanned(r.Context(), username)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to check ban list")
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return "", false, false
+	}
+	if banned {
+		http.Error(w, "Username is banned", http.StatusForbidden)
+		return "", false, false
+	}
+	return username, isBot, true
+}
+
+// BotByKey returns the username of the bot account an API key belongs to,
+// or "" if no account has it
+func (id *Identity) BotByKey(ctx context.Context, key string) (string, error) {
+	bot, err := id.accounts.GetByAPIKey(ctx, apikey.Hash(key))
+	if err != nil || bot == nil {
+		return "", err
+	}
+	return bot.Username, nil
+}
+
+// validUsername reports whether username could belong to a player
+func validUsername(username string) bool {
+	return username != "" && len(username) <= moderation.MaxUsernameLength
+}
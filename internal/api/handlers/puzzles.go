@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/repository"
+)
+
+// PuzzleHandler handles puzzle HTTP requests
+type PuzzleHandler struct {
+	repo *repository.PuzzleRepository
+}
+
+// NewPuzzleHandler creates a new puzzle handler
+func NewPuzzleHandler(repo *repository.PuzzleRepository) *PuzzleHandler {
+	return &PuzzleHandler{repo: repo}
+}
+
+// puzzleResponse is a puzzle with its board; the solution is never sent
+type puzzleResponse struct {
+	models.Puzzle
+	Board [][]int `json:"board"`
+}
+
+// Daily handles GET /api/puzzles/daily
+func (h *PuzzleHandler) Daily(w http.ResponseWriter, r *http.Request) {
+	puzzle, err := h.repo.Daily(r.Context(), time.Now())
+	if err != nil {
+		http.Error(w, "Failed to get puzzle", http.StatusInternalServerError)
+		return
+	}
+	writePuzzle(w, puzzle)
+}
+
+// GetByID handles GET /api/puzzles/{id}
+func (h *PuzzleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid puzzle ID", http.StatusBadRequest)
+		return
+	}
+
+	puzzle, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		http.Error(w, "Failed to get puzzle", http.StatusInternalServerError)
+		return
+	}
+	writePuzzle(w, puzzle)
+}
+
+// ListAttempts handles GET /api/puzzles/attempts/{username}
+// Query: limit (default 20, max 100)
+func (h *PuzzleHandler) ListAttempts(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	attempts, err := h.repo.ListAttempts(r.Context(), mux.Vars(r)["username"], limit)
+	if err != nil {
+		http.Error(w, "Failed to get puzzle attempts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attempts)
+}
+
+func writePuzzle(w http.ResponseWriter, puzzle *models.Puzzle) {
+	if puzzle == nil {
+		http.Error(w, "Puzzle not found", http.StatusNotFound)
+		return
+	}
+
+	board, err := game.DecodeBoard(puzzle.Position)
+	if err != nil {
+		http.Error(w, "Failed to get puzzle", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(puzzleResponse{Puzzle: *puzzle, Board: board.ToSlice()})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"connect-four/internal/models"
+	"connect-four/internal/repository"
+)
+
+// ReportHandler handles player reports submitted over REST. Reporters are
+// identified as the WebSocket endpoint identifies players.
+type ReportHandler struct {
+	repo     *repository.ReportRepository
+	identity *Identity
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(repo *repository.ReportRepository, identity *Identity) *ReportHandler {
+	return &ReportHandler{repo: repo, identity: identity}
+}
+
+// Create handles POST /api/reports
+// Body: {"reporter", "reported", "gameId" (optional), "reason", "details"}
+// Bots send their API key instead of a reporter
+func (h *ReportHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reporter string `json:"reporter"`
+		Reported string `json:"reported"`
+		GameID   string `json:"gameId"`
+		Reason   string `json:"reason"`
+		Details  string `json:"details"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !validUsername(req.Reported) {
+		http.Error(w, "Invalid reported username", http.StatusBadRequest)
+		return
+	}
+	reporter, _, ok := h.identity.Resolve(w, r, req.Reporter)
+	if !ok {
+		return
+	}
+	if reporter == req.Reported {
+		http.Error(w, "Cannot report yourself", http.StatusBadRequest)
+		return
+	}
+	if !models.ReportReasons[req.Reason] {
+		http.Error(w, "Invalid report reason", http.StatusBadRequest)
+		return
+	}
+	if len(req.Details) > 500 {
+		http.Error(w, "Details too long (max 500 chars)", http.StatusBadRequest)
+		return
+	}
+
+	report := &models.PlayerReport{
+		ReporterUsername: reporter,
+		ReportedUsername: req.Reported,
+		Reason:           req.Reason,
+		Details:          req.Details,
+		Status:           models.ReportStatusOpen,
+	}
+	if req.GameID != "" {
+		gameID, err := uuid.Parse(req.GameID)
+		if err != nil {
+			http.Error(w, "Invalid game ID", http.StatusBadRequest)
+			return
+		}
+		report.GameID = &gameID
+	}
+
+	if err := h.repo.Create(r.Context(), report); err != nil {
+		http.Error(w, "Failed to store report", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReportRejectsBadNames(t *testing.T) {
+	// No repository: a report that gets past the checks panics
+	h := NewReportHandler(nil, newTestIdentity(t))
+	long := strings.Repeat("a", 51)
+
+	tests := []struct {
+		name     string
+		reporter string
+		reported string
+		status   int
+	}{
+		{"reporter too long", long, "alice", http.StatusBadRequest},
+		{"reported too long", "alice", long, http.StatusBadRequest},
+		{"no reported player", "alice", "", http.StatusBadRequest},
+		{"banned reporter", "mallory", "alice", http.StatusForbidden},
+		{"reporter posing as a bot", "deep-bot", "alice", http.StatusForbidden},
+		{"reporter under a reserved name", "admin", "alice", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"reporter": %q, "reported": %q, "reason": "abusive_chat"}`, tt.reporter, tt.reported)
+			r := httptest.NewRequest(http.MethodPost, "/api/reports", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			h.Create(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d (%s)", w.Code, tt.status, strings.TrimSpace(w.Body.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"connect-four/internal/models"
+	"connect-four/internal/tournament"
+)
+
+// TournamentHandler handles tournament HTTP requests. Players registering
+// are identified as the WebSocket endpoint identifies them.
+type TournamentHandler struct {
+	manager  *tournament.Manager
+	identity *Identity
+}
+
+// NewTournamentHandler creates a new tournament handler
+func NewTournamentHandler(manager *tournament.Manager, identity *Identity) *TournamentHandler {
+	return &TournamentHandler{manager: manager, identity: identity}
+}
+
+// List handles GET /api/tournaments
+// Query: limit (default 20, max 100)
+func (h *TournamentHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit := 20
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	tournaments, err := h.manager.List(r.Context(), limit)
+	if err != nil {
+		http.Error(w, "Failed to get tournaments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tournaments)
+}
+
+// Get handles GET /api/tournaments/{id}
+// Returns the tournament with its players and every round's pairings
+func (h *TournamentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid tournament ID", http.StatusBadRequest)
+		return
+	}
+
+	t, err := h.manager.Get(r.Context(), id)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// Standings handles GET /api/tournaments/{id}/standings
+func (h *TournamentHandler) Standings(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid tournament ID", http.StatusBadRequest)
+		return
+	}
+
+	standings, err := h.manager.Standings(r.Context(), id)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(standings)
+}
+
+// Register handles POST /api/tournaments/{id}/players
+// Body: {"username": "..."}; bots send their API key instead
+func (h *TournamentHandler) Register(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid tournament ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	username, _, ok := h.identity.Resolve(w, r, req.Username)
+	if !ok {
+		return
+	}
+
+	if err := h.manager.Register(r.Context(), id, username); err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create handles POST /admin/tournaments
+// Body: {"name": "...", "format": "swiss" | "round_robin" | "single_elimination", "rounds": 0,
+// "seeding": "rating" | "wins", "gamesPerMatch": 1, "tiebreaks": 1}
+func (h *TournamentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name          string                   `json:"name"`
+		Format        models.TournamentFormat  `json:"format"`
+		Rounds        int                      `json:"rounds"`
+		Seeding       models.TournamentSeeding `json:"seeding"`
+		GamesPerMatch *int                     `json:"gamesPerMatch"`
+		Tiebreaks     *int                     `json:"tiebreaks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings := tournament.Settings{
+		Name:          req.Name,
+		Format:        req.Format,
+		Rounds:        req.Rounds,
+		Seeding:       req.Seeding,
+		GamesPerMatch: tournament.DefaultGamesPerMatch,
+		Tiebreaks:     tournament.DefaultTiebreaks,
+	}
+	if req.GamesPerMatch != nil {
+		settings.GamesPerMatch = *req.GamesPerMatch
+	}
+	if req.Tiebreaks != nil {
+		settings.Tiebreaks = *req.Tiebreaks
+	}
+
+	t, err := h.manager.Create(r.Context(), settings)
+	if err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// Start handles POST /admin/tournaments/{id}/start
+func (h *TournamentHandler) Start(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid tournament ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.manager.Start(r.Context(), id); err != nil {
+		writeTournamentError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeTournamentError maps tournament errors to HTTP statuses
+func writeTournamentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, tournament.ErrNotFound):
+		http.Error(w, "Tournament not found", http.StatusNotFound)
+	case errors.Is(err, tournament.ErrInvalidName),
+		errors.Is(err, tournament.ErrInvalidFormat),
+		errors.Is(err, tournament.ErrInvalidSeeding),
+		errors.Is(err, tournament.ErrInvalidMatch),
+		errors.Is(err, tournament.ErrNotEnoughPlayers):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, tournament.ErrNotRegistering),
+		errors.Is(err, tournament.ErrAlreadyRegistered):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Tournament request failed", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestTournamentRegisterRejectsBadNames(t *testing.T) {
+	// No manager: a registration that gets past the identity check panics
+	h := NewTournamentHandler(nil, newTestIdentity(t))
+	router := mux.NewRouter()
+	router.HandleFunc("/api/tournaments/{id}/players", h.Register).Methods("POST")
+	path := "/api/tournaments/" + uuid.New().String() + "/players"
+
+	tests := []struct {
+		name     string
+		username string
+		status   int
+	}{
+		{"banned player", "mallory", http.StatusForbidden},
+		{"reserved name", "admin", http.StatusBadRequest},
+		{"disallowed characters", "alice smith", http.StatusBadRequest},
+		{"too long", strings.Repeat("a", 51), http.StatusBadRequest},
+		{"bot without its key", "deep-bot", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(fmt.Sprintf(`{"username": %q}`, tt.username)))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d (%s)", w.Code, tt.status, strings.TrimSpace(w.Body.String()))
+			}
+		})
+	}
+}
@@ -2,32 +2,82 @@ package middleware
 
 import (
 	"bufio"
+	"context"
+	"crypto/subtle"
+	"math"
 	"net"
 	"net/http"
-	"sync"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"connect-four/internal/metrics"
+	"connect-four/internal/ratelimit"
+	"connect-four/internal/telemetry"
 )
 
-// Logging middleware logs incoming requests
+var tracer = telemetry.Tracer("http")
+
+// Logging middleware logs incoming requests and wraps each one in a server span
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		route := routeTemplate(r)
+
+		// Continue any trace started by the caller
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
 
 		// Wrap response writer to capture status code
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
-		next.ServeHTTP(wrapped, r)
+		next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", wrapped.statusCode))
+		if wrapped.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
+		}
+
+		metrics.HTTPRequestDuration.
+			WithLabelValues(r.Method, route, strconv.Itoa(wrapped.statusCode)).
+			Observe(time.Since(start).Seconds())
 
 		log.Info().
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
 			Int("status", wrapped.statusCode).
 			Dur("duration", time.Since(start)).
+			Str("traceId", span.SpanContext().TraceID().String()).
 			Msg("HTTP request")
 	})
 }
 
+// routeTemplate returns the matched mux route pattern so metric labels
+// don't explode with one series per player or game ID
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return "unmatched"
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -46,53 +96,63 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, http.ErrNotSupported
 }
 
-// RateLimiter provides simple rate limiting per IP
-type RateLimiter struct {
-	visitors map[string]*visitor
-	mu       sync.Mutex
-	rate     int           // requests per window
-	window   time.Duration // time window
+// AdminAuth returns a middleware that requires "Authorization: Bearer <token>".
+// An empty token disables the protected routes entirely.
+func AdminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" {
+				http.Error(w, "Admin API disabled", http.StatusNotFound)
+				return
+			}
+
+			provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				log.Warn().Str("path", r.URL.Path).Str("remote", r.RemoteAddr).Msg("Rejected admin request")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
-type visitor struct {
-	count    int
-	lastSeen time.Time
+// KeyFunc extracts the rate limit key from a request. Returning false skips
+// limiting for that request (e.g. no credentials to key on).
+type KeyFunc func(r *http.Request) (string, bool)
+
+// RateLimiter applies a token bucket per key to HTTP requests
+type RateLimiter struct {
+	limiter *ratelimit.Limiter
+	keyFunc KeyFunc
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(rate int, window time.Duration) *RateLimiter {
-	rl := &RateLimiter{
-		visitors: make(map[string]*visitor),
-		rate:     rate,
-		window:   window,
+// NewRateLimiter creates a rate limiter allowing perMinute requests per key
+// with bursts of up to burst requests
+func NewRateLimiter(perMinute, burst int, keyFunc KeyFunc) *RateLimiter {
+	return &RateLimiter{
+		limiter: ratelimit.NewLimiter(float64(perMinute)/60, burst),
+		keyFunc: keyFunc,
 	}
-	go rl.cleanup()
-	return rl
 }
 
 // Limit returns a middleware that rate limits requests
 func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-
-		rl.mu.Lock()
-		v, exists := rl.visitors[ip]
-		if !exists {
-			v = &visitor{}
-			rl.visitors[ip] = v
-		}
-
-		// Reset if window expired
-		if time.Since(v.lastSeen) > rl.window {
-			v.count = 0
+		key, ok := rl.keyFunc(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
 		}
 
-		v.count++
-		v.lastSeen = time.Now()
-		allowed := v.count <= rl.rate
-		rl.mu.Unlock()
-
+		allowed, retryAfter := rl.limiter.Allow(key)
 		if !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
 			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 			return
 		}
@@ -101,16 +161,100 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	})
 }
 
-// cleanup removes old visitors periodically
-func (rl *RateLimiter) cleanup() {
-	ticker := time.NewTicker(5 * time.Minute)
-	for range ticker.C {
-		rl.mu.Lock()
-		for ip, v := range rl.visitors {
-			if time.Since(v.lastSeen) > rl.window*2 {
-				delete(rl.visitors, ip)
+// KeyVerifier returns the account an API key belongs to, or "" if the key
+// is unknown
+type KeyVerifier func(ctx context.Context, key string) (string, error)
+
+// PlayerKey returns a KeyFunc that keys requests on the account whose API
+// key they carry, once verify has confirmed it, so each caller gets its own
+// budget however it spells its credentials. Requests without a verified key
+// are keyed by fallback, e.g. the client IP.
+func PlayerKey(verify KeyVerifier, fallback KeyFunc) KeyFunc {
+	return func(r *http.Request) (string, bool) {
+		if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+			account, err := verify(r.Context(), key)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to verify API key for rate limiting")
+			}
+			if account != "" {
+				return "account:" + account, true
 			}
 		}
-		rl.mu.Unlock()
+		key, ok := fallback(r)
+		return "ip:" + key, ok
+	}
+}
+
+// IPResolver determines the client address, honoring X-Forwarded-For only
+// when the direct peer is a trusted proxy
+type IPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewIPResolver parses a list of trusted proxy CIDRs or bare IPs
+func NewIPResolver(trustedProxies []string) (*IPResolver, error) {
+	resolver := &IPResolver{}
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+// ClientIP returns the originating client IP without the port
+func (res *IPResolver) ClientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+
+	if !res.isTrusted(remote) {
+		return remote
+	}
+
+	// Walk the chain right to left; the first untrusted hop is the client
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if hop == "" {
+			continue
+		}
+		if !res.isTrusted(hop) {
+			return hop
+		}
+		remote = hop
+	}
+	return remote
+}
+
+// IPKey returns a KeyFunc that keys requests on the resolved client IP
+func (res *IPResolver) IPKey(r *http.Request) (string, bool) {
+	return res.ClientIP(r), true
+}
+
+func (res *IPResolver) isTrusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range res.trusted {
+		if network.Contains(ip) {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewIPResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct client strips port", "203.0.113.7:51234", "", "203.0.113.7"},
+		{"untrusted peer ignores header", "203.0.113.7:51234", "1.2.3.4", "203.0.113.7"},
+		{"trusted proxy uses header", "10.1.2.3:80", "198.51.100.9", "198.51.100.9"},
+		{"skips trusted hops right to left", "192.168.1.1:80", "198.51.100.9, 10.0.0.5", "198.51.100.9"},
+		{"spoofed leftmost entry is ignored", "10.1.2.3:80", "6.6.6.6, 198.51.100.9", "198.51.100.9"},
+		{"trusted proxy without header", "10.1.2.3:80", "", "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := resolver.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterRejectsOverBudget(t *testing.T) {
+	resolver, _ := NewIPResolver(nil)
+	limiter := NewRateLimiter(1, 2, resolver.IPKey)
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	codes := make([]int, 3)
+	for i := range codes {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = "203.0.113.7:1000" // port changes must not matter
+		if i == 1 {
+			r.RemoteAddr = "203.0.113.7:2000"
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		codes[i] = w.Code
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("Unexpected status codes: %v", codes)
+	}
+}
+
+func TestPlayerKeyUsesVerifiedAccounts(t *testing.T) {
+	resolver, _ := NewIPResolver(nil)
+	verify := func(_ context.Context, key string) (string, error) {
+		if key == "good-key" || key == "good-key-again" {
+			return "deep-bot", nil
+		}
+		return "", nil
+	}
+	keyFunc := PlayerKey(verify, resolver.IPKey)
+
+	tests := []struct {
+		name string
+		auth string
+		want string
+	}{
+		{"verified key", "Bearer good-key", "account:deep-bot"},
+		{"another key for the same account", "Bearer good-key-again", "account:deep-bot"},
+		{"unknown key", "Bearer made-up", "ip:203.0.113.7"},
+		{"another unknown key", "Bearer made-up-too", "ip:203.0.113.7"},
+		{"no key", "", "ip:203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "203.0.113.7:1000"
+			if tt.auth != "" {
+				r.Header.Set("Authorization", tt.auth)
+			}
+			if got, ok := keyFunc(r); !ok || got != tt.want {
+				t.Errorf("key = %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
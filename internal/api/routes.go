@@ -1,18 +1,26 @@
 package api
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 
+	"connect-four/internal/analysis"
 	"connect-four/internal/api/handlers"
 	"connect-four/internal/api/middleware"
+	"connect-four/internal/bot"
+	"connect-four/internal/correspondence"
 	"connect-four/internal/kafka"
 	"connect-four/internal/matchmaking"
+	"connect-four/internal/moderation"
+	"connect-four/internal/ratelimit"
 	"connect-four/internal/repository"
+	"connect-four/internal/tournament"
 	ws "connect-four/internal/websocket"
 	"connect-four/pkg/config"
 )
@@ -23,7 +31,16 @@ type Server struct {
 	Hub            *ws.Hub
 	MessageHandler *ws.MessageHandler
 	MatchQueue     *matchmaking.Queue
+	Lobbies        *matchmaking.Lobbies
+	Tournaments    *tournament.Manager
+	Correspondence *correspondence.Manager
+	Analyses       *analysis.Service
+	identity       *handlers.Identity
 	upgrader       websocket.Upgrader
+
+	// Per-client WebSocket message budget
+	wsMessagesPerSecond int
+	wsMessageBurst      int
 }
 
 // NewServer creates a new API server with all routes configured
@@ -34,23 +51,74 @@ func NewServer(db *gorm.DB, cfg *config.Config, kafkaProducer *kafka.Producer) *
 	playerRepo := repository.NewPlayerRepository(db)
 	gameRepo := repository.NewGameRepository(db)
 	leaderboardRepo := repository.NewLeaderboardRepository(db)
+	banRepo := repository.NewBanRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+	chatRepo := repository.NewChatRepository(db)
+	seriesRepo := repository.NewSeriesRepository(db)
+	tournamentRepo := repository.NewTournamentRepository(db)
+	puzzleRepo := repository.NewPuzzleRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+
+	// Username and chat policy
+	policy, err := moderation.NewPolicy(cfg.ProfanityListPath)
+	if err != nil {
+		log.Fatal().Err(err).Str("path", cfg.ProfanityListPath).Msg("Failed to load profanity list")
+	}
+
+	// Who is playing, for the WebSocket and REST calls alike
+	identity := handlers.NewIdentity(playerRepo, banRepo, policy)
 
 	// Create handlers
-	playerHandler := handlers.NewPlayerHandler(playerRepo)
-	gameHandler := handlers.NewGameHandler(gameRepo, playerRepo)
+	playerHandler := handlers.NewPlayerHandler(playerRepo, banRepo, policy)
+	analyses := analysis.NewService(gameRepo, cfg.AnalysisWorkers, cfg.AnalysisDepth)
+	gameHandler := handlers.NewGameHandler(gameRepo, playerRepo, analyses)
 	leaderboardHandler := handlers.NewLeaderboardHandler(leaderboardRepo)
+	reportHandler := handlers.NewReportHandler(reportRepo, identity)
+	seriesHandler := handlers.NewSeriesHandler(seriesRepo)
+	puzzleHandler := handlers.NewPuzzleHandler(puzzleRepo)
+
+	// Bot engines players can pick: the built-in bot plus configured processes
+	engines := bot.NewRegistry()
+	if cfg.BotEnginesPath != "" {
+		configs, err := bot.LoadExternalConfigs(cfg.BotEnginesPath)
+		if err != nil {
+			log.Fatal().Err(err).Str("path", cfg.BotEnginesPath).Msg("Failed to load bot engines")
+		}
+		for _, c := range configs {
+			c := c
+			engines.Register(c.Name, func() (bot.Engine, error) { return bot.StartExternal(c) })
+			log.Info().Str("engine", c.Name).Str("command", c.Command).Msg("Registered external engine")
+		}
+	}
+	engineHandler := handlers.NewEngineHandler(engines)
 
 	// Create WebSocket infrastructure
-	hub := ws.NewHub(cfg.MatchmakingTimeout, cfg.ReconnectTimeout, cfg.BotMoveDelay)
-	matchQueue := matchmaking.NewQueue(cfg.MatchmakingTimeout)
-	messageHandler := ws.NewMessageHandler(hub, matchQueue, playerRepo, kafkaProducer)
+	hub := ws.NewHub(cfg.MatchmakingTimeout, cfg.ReconnectTimeout, cfg.BotMoveDelay, cfg.BotMoveBudget, cfg.RatedTakebackLimit, cfg.HintsPerGame, kafkaProducer)
+	matchQueue := matchmaking.NewQueue(cfg.MatchmakingTimeout, kafkaProducer)
+	lobbies := matchmaking.NewLobbies(cfg.MatchmakingTimeout)
+	messageHandler := ws.NewMessageHandler(hub, matchQueue, lobbies, engines, playerRepo, reportRepo, chatRepo, gameRepo, seriesRepo, puzzleRepo, policy, kafkaProducer)
+	tournaments := tournament.NewManager(tournamentRepo, playerRepo, messageHandler, cfg.TournamentRoundDelay)
+	messageHandler.OnGameFinished(tournaments.GameFinished)
+	tournamentHandler := handlers.NewTournamentHandler(tournaments, identity)
+	correspondences := correspondence.NewManager(gameRepo, playerRepo, notificationRepo, messageHandler, cfg.CorrespondenceSweepInterval)
+	messageHandler.OnCorrespondenceMove(correspondences.Move)
+	messageHandler.OnConnect(correspondences.Deliver)
+	correspondenceHandler := handlers.NewCorrespondenceHandler(correspondences, identity)
+	adminHandler := handlers.NewAdminHandler(hub, messageHandler, matchQueue, playerRepo, banRepo, reportRepo, chatRepo, policy)
 
 	// Create server
 	server := &Server{
-		Router:         router,
-		Hub:            hub,
-		MessageHandler: messageHandler,
-		MatchQueue:     matchQueue,
+		Router:              router,
+		Hub:                 hub,
+		MessageHandler:      messageHandler,
+		MatchQueue:          matchQueue,
+		Lobbies:             lobbies,
+		Tournaments:         tournaments,
+		Correspondence:      correspondences,
+		Analyses:            analyses,
+		identity:            identity,
+		wsMessagesPerSecond: cfg.WSMessagesPerSecond,
+		wsMessageBurst:      cfg.WSMessageBurst,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -69,8 +137,23 @@ func NewServer(db *gorm.DB, cfg *config.Config, kafkaProducer *kafka.Producer) *
 		w.Write([]byte(`{"status":"ok"}`))
 	}).Methods("GET", "HEAD")
 
+	// Prometheus metrics
+	router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+
+	// Rate limiting keyed on the real client IP (X-Forwarded-For from trusted
+	// proxies only), and per player on the bot account a verified API key
+	// belongs to, or the IP for callers without one
+	ipResolver, err := middleware.NewIPResolver(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+	apiIPLimiter := middleware.NewRateLimiter(cfg.APIRatePerMinute, cfg.APIRateBurst, ipResolver.IPKey)
+	apiPlayerLimiter := middleware.NewRateLimiter(cfg.PlayerRatePerMinute, cfg.PlayerRateBurst, middleware.PlayerKey(identity.BotByKey, ipResolver.IPKey))
+	wsUpgradeLimiter := middleware.NewRateLimiter(cfg.WSUpgradesPerMinute, cfg.WSUpgradeBurst, ipResolver.IPKey)
+
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
+	api.Use(apiIPLimiter.Limit, apiPlayerLimiter.Limit)
 
 	// Player endpoints
 	api.HandleFunc("/players", playerHandler.CreateOrGet).Methods("POST")
@@ -80,27 +163,84 @@ func NewServer(db *gorm.DB, cfg *config.Config, kafkaProducer *kafka.Producer) *
 	// Leaderboard endpoints
 	api.HandleFunc("/leaderboard", leaderboardHandler.GetTopPlayers).Methods("GET")
 
+	// Bot engines
+	api.HandleFunc("/engines", engineHandler.List).Methods("GET")
+
 	// Game endpoints
 	api.HandleFunc("/games/{id}", gameHandler.GetByID).Methods("GET")
+	api.HandleFunc("/games/{id}/evaluations", gameHandler.GetEvaluations).Methods("GET")
+	api.HandleFunc("/games/{id}/analysis", gameHandler.GetAnalysis).Methods("GET")
+	api.HandleFunc("/games/{id}/moves", correspondenceHandler.Move).Methods("POST")
+	api.HandleFunc("/series/{id}", seriesHandler.GetByID).Methods("GET")
+
+	// Tournament endpoints
+	api.HandleFunc("/tournaments", tournamentHandler.List).Methods("GET")
+	api.HandleFunc("/tournaments/{id}", tournamentHandler.Get).Methods("GET")
+	api.HandleFunc("/tournaments/{id}/standings", tournamentHandler.Standings).Methods("GET")
+	api.HandleFunc("/tournaments/{id}/players", tournamentHandler.Register).Methods("POST")
+
+	// Correspondence game endpoints
+	api.HandleFunc("/correspondence", correspondenceHandler.List).Methods("GET")
+	api.HandleFunc("/correspondence", correspondenceHandler.Create).Methods("POST")
+	api.HandleFunc("/correspondence/{id}", correspondenceHandler.Get).Methods("GET")
+	api.HandleFunc("/correspondence/{id}/accept", correspondenceHandler.Accept).Methods("POST")
+	api.HandleFunc("/correspondence/{id}/decline", correspondenceHandler.Decline).Methods("POST")
+	api.HandleFunc("/correspondence/{id}/resign", correspondenceHandler.Resign).Methods("POST")
+
+	// Puzzle endpoints
+	api.HandleFunc("/puzzles/daily", puzzleHandler.Daily).Methods("GET")
+	api.HandleFunc("/puzzles/attempts/{username}", puzzleHandler.ListAttempts).Methods("GET")
+	api.HandleFunc("/puzzles/{id}", puzzleHandler.GetByID).Methods("GET")
+
+	// Moderation endpoints
+	api.HandleFunc("/reports", reportHandler.Create).Methods("POST")
+
+	// Admin endpoints (bearer token required)
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.AdminAuth(cfg.AdminToken))
+	admin.HandleFunc("/games", adminHandler.ListGames).Methods("GET")
+	admin.HandleFunc("/games/{id}/end", adminHandler.EndGame).Methods("POST")
+	admin.HandleFunc("/games/{id}/chat", adminHandler.GetGameChat).Methods("GET")
+	admin.HandleFunc("/queue", adminHandler.GetQueue).Methods("GET")
+	admin.HandleFunc("/players/{username}/kick", adminHandler.KickPlayer).Methods("POST")
+	admin.HandleFunc("/bans", adminHandler.ListBans).Methods("GET")
+	admin.HandleFunc("/bans", adminHandler.BanPlayer).Methods("POST")
+	admin.HandleFunc("/bans/{username}", adminHandler.UnbanPlayer).Methods("DELETE")
+	admin.HandleFunc("/reports", adminHandler.ListReports).Methods("GET")
+	admin.HandleFunc("/broadcast", adminHandler.Broadcast).Methods("POST")
+	admin.HandleFunc("/bots", adminHandler.CreateBot).Methods("POST")
+	admin.HandleFunc("/bots/{username}/key", adminHandler.RotateBotKey).Methods("POST")
+	admin.HandleFunc("/tournaments", tournamentHandler.Create).Methods("POST")
+	admin.HandleFunc("/tournaments/{id}/start", tournamentHandler.Start).Methods("POST")
 
 	// WebSocket endpoint
-	router.HandleFunc("/ws", server.handleWebSocket).Methods("GET")
+	router.Handle("/ws", wsUpgradeLimiter.Limit(http.HandlerFunc(server.handleWebSocket))).Methods("GET")
 
 	return server
 }
 
-// Start starts the WebSocket hub and matchmaking queue
+// Start starts the WebSocket hub, matchmaking queue, multiplayer lobbies,
+// analysis workers and correspondence deadline sweep and resumes any
+// tournaments that were running when the server last stopped
 func (s *Server) Start() {
 	go s.Hub.Run()
 	s.MatchQueue.Start()
-	log.Info().Msg("WebSocket hub and matchmaking queue started")
+	s.Lobbies.Start()
+	s.Analyses.Start()
+	s.Correspondence.Start()
+	log.Info().Msg("WebSocket hub, matchmaking queue, lobbies, analysis workers and correspondence sweep started")
+
+	if err := s.Tournaments.Resume(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to resume tournaments")
+	}
 }
 
 // handleWebSocket upgrades HTTP to WebSocket and registers the client
+// Bot accounts authenticate with "Authorization: Bearer <api key>" instead
+// of choosing a username.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
-	username := r.URL.Query().Get("username")
-	if username == "" {
-		http.Error(w, "Username required", http.StatusBadRequest)
+	username, isBot, ok := s.identity.Resolve(w, r, r.URL.Query().Get("username"))
+	if !ok {
 		return
 	}
 
@@ -110,7 +250,13 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := ws.NewClient(s.Hub, conn, username)
+	var budget *ratelimit.Bucket
+	if s.wsMessagesPerSecond > 0 {
+		budget = ratelimit.NewBucket(float64(s.wsMessagesPerSecond), s.wsMessageBurst)
+	}
+
+	client := ws.NewClient(s.Hub, conn, username, budget)
+	client.IsBot = isBot
 	s.Hub.Register(client)
 
 	// Start client goroutines
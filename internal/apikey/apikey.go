@@ -0,0 +1,29 @@
+// Package apikey issues the API keys bot accounts use to connect. Only a
+// key's hash is stored, so a lost key has to be replaced, not recovered.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix marks connect-four keys so they are easy to spot in configs and logs
+const Prefix = "c4_"
+
+// Generate returns a new random key and the hash to store for it
+func Generate() (key, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key = Prefix + hex.EncodeToString(buf)
+	return key, Hash(key), nil
+}
+
+// Hash returns the stored form of a key
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,24 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	key, hash, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, Prefix) {
+		t.Errorf("key %q lacks prefix", key)
+	}
+	if Hash(key) != hash || len(hash) != 64 {
+		t.Errorf("hash doesn't match key")
+	}
+
+	other, _, _ := Generate()
+	if other == key {
+		t.Error("keys should be random")
+	}
+}
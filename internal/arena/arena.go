@@ -0,0 +1,245 @@
+// Package arena plays engines against each other to compare their strength.
+// Every pairing plays the same random openings twice with colors swapped,
+// and results depend only on the seed, not on how games are scheduled.
+package arena
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+
+	"connect-four/internal/game"
+)
+
+// Engine chooses a move for player on board
+type Engine interface {
+	SelectMove(board *game.Board, player game.Cell) int
+}
+
+// Entrant is a named engine configuration. New is called once per game so
+// engines with internal state never share it across goroutines.
+type Entrant struct {
+	Name string
+	New  func() Engine
+}
+
+// Config controls an arena run
+type Config struct {
+	GamesPerPairing int   // rounded up to an even number
+	OpeningMoves    int   // random plies played before the engines take over
+	Workers         int   // games played in parallel
+	Seed            int64 // same seed, same results
+}
+
+// Result counts games from one side's point of view
+type Result struct {
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+}
+
+// Games returns the number of games played
+func (r Result) Games() int {
+	return r.Wins + r.Draws + r.Losses
+}
+
+// Score returns the points scored per game, from 0 to 1
+func (r Result) Score() float64 {
+	if r.Games() == 0 {
+		return 0.5
+	}
+	return (float64(r.Wins) + float64(r.Draws)/2) / float64(r.Games())
+}
+
+func (r Result) add(o Result) Result {
+	return Result{Wins: r.Wins + o.Wins, Draws: r.Draws + o.Draws, Losses: r.Losses + o.Losses}
+}
+
+func (r Result) flip() Result {
+	return Result{Wins: r.Losses, Draws: r.Draws, Losses: r.Wins}
+}
+
+// PairResult is the outcome of one pairing, from A's point of view
+type PairResult struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Result Result  `json:"result"`
+	Elo    float64 `json:"elo"` // A's rating advantage over B
+	EloLo  float64 `json:"eloLow"`
+	EloHi  float64 `json:"eloHigh"`
+}
+
+// Standing is an engine's result against the whole field
+type Standing struct {
+	Name   string  `json:"name"`
+	Result Result  `json:"result"`
+	Elo    float64 `json:"elo"` // relative to the average opponent
+	EloLo  float64 `json:"eloLow"`
+	EloHi  float64 `json:"eloHigh"`
+}
+
+// Report is the outcome of an arena run
+type Report struct {
+	Pairings  []PairResult `json:"pairings"`
+	Standings []Standing   `json:"standings"`
+}
+
+// job is one game: entrant indexes, who moves first, and the opening
+type job struct {
+	pairing int
+	first   int // entrant moving first
+	second  int
+	opening []int
+}
+
+// Run plays every pairing of entrants and reports the results
+func Run(entrants []Entrant, cfg Config) Report {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.GamesPerPairing < 2 {
+		cfg.GamesPerPairing = 2
+	}
+	rounds := (cfg.GamesPerPairing + 1) / 2
+
+	type pair struct{ a, b int }
+	var pairs []pair
+	for a := 0; a < len(entrants); a++ {
+		for b := a + 1; b < len(entrants); b++ {
+			pairs = append(pairs, pair{a, b})
+		}
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	var jobs []job
+	for i, p := range pairs {
+		for round := 0; round < rounds; round++ {
+			opening := RandomOpening(rng, cfg.OpeningMoves)
+			jobs = append(jobs,
+				job{pairing: i, first: p.a, second: p.b, opening: opening},
+				job{pairing: i, first: p.b, second: p.a, opening: opening},
+			)
+		}
+	}
+
+	// Each game's winner goes in its own slot, so the tally doesn't depend
+	// on which worker finished first
+	winners := make([]game.Cell, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				j := jobs[i]
+				winners[i] = Play(entrants[j.first].New(), entrants[j.second].New(), j.opening)
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	results := make([]Result, len(pairs))
+	for i, j := range jobs {
+		// Result from the first entrant of the pairing (pairs[j.pairing].a)
+		var r Result
+		switch winners[i] {
+		case game.Player1:
+			r.Wins = 1
+		case game.Player2:
+			r.Losses = 1
+		default:
+			r.Draws = 1
+		}
+		if j.first != pairs[j.pairing].a {
+			r = r.flip()
+		}
+		results[j.pairing] = results[j.pairing].add(r)
+	}
+
+	var report Report
+	totals := make([]Result, len(entrants))
+	for i, p := range pairs {
+		r := results[i]
+		lo, hi := r.EloInterval(1.96)
+		report.Pairings = append(report.Pairings, PairResult{
+			A:      entrants[p.a].Name,
+			B:      entrants[p.b].Name,
+			Result: r,
+			Elo:    EloDiff(r.Score()),
+			EloLo:  lo,
+			EloHi:  hi,
+		})
+		totals[p.a] = totals[p.a].add(r)
+		totals[p.b] = totals[p.b].add(r.flip())
+	}
+	for i, e := range entrants {
+		lo, hi := totals[i].EloInterval(1.96)
+		report.Standings = append(report.Standings, Standing{
+			Name:   e.Name,
+			Result: totals[i],
+			Elo:    EloDiff(totals[i].Score()),
+			EloLo:  lo,
+			EloHi:  hi,
+		})
+	}
+	sort.SliceStable(report.Standings, func(i, j int) bool {
+		return report.Standings[i].Elo > report.Standings[j].Elo
+	})
+	return report
+}
+
+// RandomOpening returns plies random legal moves that don't end the game
+func RandomOpening(rng *rand.Rand, plies int) []int {
+	for {
+		board := game.NewBoard()
+		player := game.Player1
+		opening := make([]int, 0, plies)
+		ok := true
+		for len(opening) < plies {
+			valid := board.ValidColumns()
+			col := valid[rng.Intn(len(valid))]
+			row := board.DropDisc(col, player)
+			if board.WinsAt(row, col, player) || board.IsBoardFull() {
+				ok = false
+				break
+			}
+			opening = append(opening, col)
+			player = other(player)
+		}
+		if ok {
+			return opening
+		}
+	}
+}
+
+// Play plays one game from an opening and returns the winner (game.Empty
+// for a draw). An engine that returns an illegal move loses.
+func Play(first, second Engine, opening []int) game.Cell {
+	g := game.NewGame(&game.PlayerInfo{Username: "first"}, &game.PlayerInfo{Username: "second"})
+	for _, col := range opening {
+		g.MakeMove(g.CurrentTurn, col)
+	}
+
+	engines := map[game.Cell]Engine{game.Player1: first, game.Player2: second}
+	for !g.IsGameOver() {
+		player := g.CurrentTurn
+		board, _ := g.Snapshot()
+		col := engines[player].SelectMove(board, player)
+		if _, errMsg := g.MakeMove(player, col); errMsg != "" {
+			return other(player)
+		}
+	}
+	return g.Winner
+}
+
+func other(player game.Cell) game.Cell {
+	if player == game.Player1 {
+		return game.Player2
+	}
+	return game.Player1
+}
@@ -0,0 +1,82 @@
+package arena
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"connect-four/internal/bot"
+	"connect-four/internal/game"
+)
+
+func TestEloDiff(t *testing.T) {
+	if d := EloDiff(0.5); d != 0 {
+		t.Errorf("EloDiff(0.5) = %v, want 0", d)
+	}
+	if d := EloDiff(0.76); math.Abs(d-200) > 2 {
+		t.Errorf("EloDiff(0.76) = %v, want about 200", d)
+	}
+	if d := EloDiff(1); math.IsInf(d, 0) || d <= 0 {
+		t.Errorf("EloDiff(1) should be large and finite, got %v", d)
+	}
+}
+
+func TestEloInterval(t *testing.T) {
+	r := Result{Wins: 60, Draws: 20, Losses: 20}
+	lo, hi := r.EloInterval(1.96)
+	mid := EloDiff(r.Score())
+	if !(lo < mid && mid < hi) {
+		t.Errorf("interval [%v, %v] should contain %v", lo, hi, mid)
+	}
+
+	// More games, narrower interval
+	big := Result{Wins: 600, Draws: 200, Losses: 200}
+	bigLo, bigHi := big.EloInterval(1.96)
+	if bigHi-bigLo >= hi-lo {
+		t.Errorf("interval didn't narrow with more games: %v vs %v", bigHi-bigLo, hi-lo)
+	}
+}
+
+func TestRandomOpeningIsPlayable(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 100; i++ {
+		board := game.NewBoard()
+		player := game.Player1
+		for _, col := range RandomOpening(rng, 8) {
+			row := board.DropDisc(col, player)
+			if row == -1 || board.WinsAt(row, col, player) {
+				t.Fatalf("opening plays an illegal or winning move")
+			}
+			player = other(player)
+		}
+	}
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	entrants := []Entrant{
+		{Name: "heuristic", New: func() Engine { return bot.NewBot() }},
+		{Name: "search2", New: func() Engine { return bot.NewSearch(2, nil) }},
+		{Name: "center2", New: func() Engine { return bot.NewSearch(2, bot.EvaluateCenter) }},
+	}
+
+	serial := Run(entrants, Config{GamesPerPairing: 10, OpeningMoves: 4, Workers: 1, Seed: 42})
+	parallel := Run(entrants, Config{GamesPerPairing: 10, OpeningMoves: 4, Workers: 4, Seed: 42})
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("results depend on scheduling:\n%+v\n%+v", serial, parallel)
+	}
+
+	if len(serial.Pairings) != 3 {
+		t.Fatalf("expected 3 pairings, got %d", len(serial.Pairings))
+	}
+	for _, p := range serial.Pairings {
+		if p.Result.Games() != 10 {
+			t.Errorf("%s vs %s played %d games, want 10", p.A, p.B, p.Result.Games())
+		}
+	}
+	for _, s := range serial.Standings {
+		if s.Result.Games() != 20 {
+			t.Errorf("%s played %d games, want 20", s.Name, s.Result.Games())
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package arena
+
+import "math"
+
+// Scores this close to 0 or 1 are clamped so a clean sweep gives a large
+// but finite rating difference
+const scoreEpsilon = 0.001
+
+// EloDiff converts a score per game (0 to 1) to the rating difference that
+// predicts it
+func EloDiff(score float64) float64 {
+	score = math.Min(math.Max(score, scoreEpsilon), 1-scoreEpsilon)
+	return -400 * math.Log10(1/score-1)
+}
+
+// EloInterval returns the rating difference bounds at z standard errors
+// (1.96 for 95%), from the spread of per-game scores
+func (r Result) EloInterval(z float64) (lo, hi float64) {
+	n := float64(r.Games())
+	if n == 0 {
+		return EloDiff(0), EloDiff(1)
+	}
+	s := r.Score()
+	variance := (float64(r.Wins)*math.Pow(1-s, 2) +
+		float64(r.Draws)*math.Pow(0.5-s, 2) +
+		float64(r.Losses)*math.Pow(s, 2)) / n
+	margin := z * math.Sqrt(variance/n)
+	return EloDiff(s - margin), EloDiff(s + margin)
+}
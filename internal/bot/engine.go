@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"connect-four/internal/game"
+)
+
+// Engine chooses moves for the bot side of a game. ctx's deadline is the
+// move's time budget: engines that can should answer with the best move
+// they have by then. Move must give up when ctx is cancelled; Close
+// releases whatever the engine holds (such as a process) and may be called
+// more than once.
+type Engine interface {
+	Move(ctx context.Context, board *game.Board, player game.Cell) (int, error)
+	Close() error
+}
+
+// DefaultEngine is the name of the built-in heuristic bot
+const DefaultEngine = "default"
+
+// SearchEngine is the name of the built-in search bot, which looks up to
+// searchEngineDepth plies ahead within the move's time budget
+const SearchEngine = "search"
+
+const searchEngineDepth = 12
+
+// ErrUnknownEngine is returned for an engine name nobody registered
+var ErrUnknownEngine = errors.New("unknown engine")
+
+// Move implements Engine for the built-in heuristic bot
+func (b *Bot) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	return b.SelectMove(board, player), nil
+}
+
+// Close implements Engine; the built-in bot holds nothing
+func (b *Bot) Close() error {
+	return nil
+}
+
+// Close implements Engine; the search bot holds nothing
+func (s *Search) Close() error {
+	return nil
+}
+
+// Factory creates an engine for one game
+type Factory func() (Engine, error)
+
+// Registry holds the engines players can pick as opponents, by name
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates a registry with the built-in bot as DefaultEngine,
+// the search bot as SearchEngine and one engine per personality, named
+// after it
+func NewRegistry() *Registry {
+	r := &Registry{factories: make(map[string]Factory)}
+	r.Register(DefaultEngine, func() (Engine, error) { return NewBot(), nil })
+	r.Register(SearchEngine, func() (Engine, error) { return NewSearch(searchEngineDepth, EvaluateThreats), nil })
+	for name, p := range Personalities {
+		r.Register(name, func() (Engine, error) { return NewPersonal(p, rand.Int63()), nil })
+	}
+	return r
+}
+
+// Register adds or replaces a named engine
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+}
+
+// New creates an engine for one game
+func (r *Registry) New(name string) (Engine, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownEngine
+	}
+	return factory()
+}
+
+// Has reports whether an engine is registered
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.factories[name]
+	return ok
+}
+
+// Names lists the registered engines in alphabetical order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"fmt"
+
+	"connect-four/internal/game"
+)
+
+// Reason is the machine-readable rule behind a suggested move
+type Reason string
+
+const (
+	ReasonWins         Reason = "wins_immediately"
+	ReasonBlocksWin    Reason = "blocks_threat"
+	ReasonDoubleThreat Reason = "creates_double_threat"
+	ReasonParityThreat Reason = "creates_parity_threat"
+	ReasonCreatesThree Reason = "creates_three"
+	ReasonBlocksThree  Reason = "blocks_three"
+	ReasonCenter       Reason = "center_preference"
+	ReasonOnlyMoves    Reason = "no_safe_move"
+)
+
+// Hint is a suggested move with the reason the bot would play it
+type Hint struct {
+	Column int    `json:"column"`
+	Row    int    `json:"row"` // row the disc lands in, 0 = top
+	Reason Reason `json:"reason"`
+	Text   string `json:"text"`
+}
+
+func newHint(board *game.Board, col int, reason Reason) Hint {
+	row := board.GetDropRow(col)
+	return Hint{Column: col, Row: row, Reason: reason, Text: describe(reason, row)}
+}
+
+// describe renders a reason as a short sentence for players
+func describe(reason Reason, row int) string {
+	switch reason {
+	case ReasonWins:
+		return "wins immediately"
+	case ReasonBlocksWin:
+		return fmt.Sprintf("blocks opponent's threat at row %d", row)
+	case ReasonDoubleThreat:
+		return "creates double threat"
+	case ReasonParityThreat:
+		return "creates a threat on a row that parity favours"
+	case ReasonCreatesThree:
+		return "creates three in a row with room to win"
+	case ReasonBlocksThree:
+		return "blocks opponent's three in a row"
+	case ReasonCenter:
+		return "strongest safe move, keeping to the center"
+	}
+	return "every move gives the opponent something"
+}
+
+// MoveEvaluation compares a move that was played with the bot's choice in
+// the same position
+type MoveEvaluation struct {
+	Ply       int  `json:"ply"` // 1-based
+	Player    int  `json:"player"`
+	Column    int  `json:"column"`
+	Suggested Hint `json:"suggested"`
+	Agrees    bool `json:"agrees"`
+}
+
+// EvaluateGame replays moves from an empty board and records what the bot
+// would have played before each one. It stops at the first illegal move
+// or pop.
+func (b *Bot) EvaluateGame(moves []game.Move) []MoveEvaluation {
+	board := game.NewBoard()
+	evals := make([]MoveEvaluation, 0, len(moves))
+	for i, m := range moves {
+		if m.Type == game.MovePop {
+			break
+		}
+		hint := b.Explain(board, m.Player)
+		if board.DropDisc(m.Column, m.Player) == -1 {
+			break
+		}
+		evals = append(evals, MoveEvaluation{
+			Ply:       i + 1,
+			Player:    int(m.Player),
+			Column:    m.Column,
+			Suggested: hint,
+			Agrees:    hint.Column == m.Column,
+		})
+	}
+	return evals
+}
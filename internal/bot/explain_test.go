@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+)
+
+func TestExplainReasons(t *testing.T) {
+	b := NewBot()
+
+	win := game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		win.DropDisc(col, game.Player2)
+	}
+	if h := b.Explain(win, game.Player2); h.Column != 3 || h.Reason != ReasonWins {
+		t.Errorf("winning move: got %+v", h)
+	}
+
+	block := game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		block.DropDisc(col, game.Player1)
+	}
+	h := b.Explain(block, game.Player2)
+	if h.Column != 3 || h.Reason != ReasonBlocksWin || h.Row != game.Rows-1 {
+		t.Errorf("block: got %+v", h)
+	}
+	if h.Text != "blocks opponent's threat at row 5" {
+		t.Errorf("block text = %q", h.Text)
+	}
+
+	double := game.NewBoard()
+	double.DropDisc(2, game.Player1)
+	double.DropDisc(4, game.Player1)
+	double.DropDisc(0, game.Player2)
+	double.DropDisc(6, game.Player2)
+	if h := b.Explain(double, game.Player1); h.Column != 3 || h.Reason != ReasonDoubleThreat {
+		t.Errorf("double threat: got %+v", h)
+	}
+}
+
+func TestExplainMatchesSelectMove(t *testing.T) {
+	b := NewBot()
+	board := game.NewBoard()
+	player := game.Player1
+	for !board.IsBoardFull() {
+		h := b.Explain(board, player)
+		if col := b.SelectMove(board, player); col != h.Column {
+			t.Fatalf("SelectMove = %d, Explain = %d", col, h.Column)
+		}
+		row := board.DropDisc(h.Column, player)
+		if board.WinsAt(row, h.Column, player) {
+			break
+		}
+		player = other(player)
+	}
+}
+
+func TestEvaluateGame(t *testing.T) {
+	moves := []game.Move{
+		{Player: game.Player1, Column: 0},
+		{Player: game.Player2, Column: 3},
+		{Player: game.Player1, Column: 0},
+	}
+	evals := NewBot().EvaluateGame(moves)
+	if len(evals) != 3 {
+		t.Fatalf("got %d evaluations, want 3", len(evals))
+	}
+	if evals[0].Agrees || evals[0].Suggested.Column != 3 {
+		t.Errorf("first move: the bot opens in the center, got %+v", evals[0])
+	}
+	if !evals[1].Agrees || evals[1].Ply != 2 {
+		t.Errorf("second move should agree with the bot, got %+v", evals[1])
+	}
+}
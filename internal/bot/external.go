@@ -0,0 +1,230 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+// External engine protocol, one command per line over stdin/stdout:
+//
+//	server: protocol 1
+//	engine: ready
+//	server: position <board> <player>   board is game.Board.Encode, player 1 or 2
+//	server: go <milliseconds>
+//	engine: bestmove <column>
+//	server: quit
+//
+// Lines the server doesn't expect (logging, "info ...") are ignored. An
+// engine that exits, answers late or writes no bestmove loses the game.
+const ProtocolVersion = 1
+
+// Errors from external engines
+var (
+	ErrEngineCrashed = errors.New("engine exited")
+	ErrEngineTimeout = errors.New("engine did not answer in time")
+)
+
+// Extra time allowed on top of the move budget for process scheduling and
+// pipe latency before an engine is considered hung
+const moveGrace = 500 * time.Millisecond
+
+// Default budget when a configuration doesn't set one
+const defaultMoveTime = 2 * time.Second
+
+// Shortest budget an engine is asked to move in, however little time the
+// server gives it
+const minMoveTime = 100 * time.Millisecond
+
+// ExternalConfig registers an engine that runs as a separate process
+type ExternalConfig struct {
+	Name       string   `json:"name"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args"`
+	MoveTimeMs int      `json:"moveTimeMs"`
+}
+
+// MoveTime returns the per-move budget
+func (c ExternalConfig) MoveTime() time.Duration {
+	if c.MoveTimeMs <= 0 {
+		return defaultMoveTime
+	}
+	return time.Duration(c.MoveTimeMs) * time.Millisecond
+}
+
+// LoadExternalConfigs reads a JSON list of external engine configurations
+func LoadExternalConfigs(path string) ([]ExternalConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var configs []ExternalConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, err
+	}
+	for _, c := range configs {
+		if c.Name == "" || c.Command == "" {
+			return nil, fmt.Errorf("engine needs a name and a command: %+v", c)
+		}
+	}
+	return configs, nil
+}
+
+// External is an engine process speaking the line protocol. Each game gets
+// its own process, started by StartExternal and killed by Close.
+type External struct {
+	cfg   ExternalConfig
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string   // stdout, closed when the process's output ends
+	quit  chan struct{} // closed by Close to release the reader
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// StartExternal starts an engine process and waits for it to be ready
+func StartExternal(cfg ExternalConfig) (*External, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start engine %s: %w", cfg.Name, err)
+	}
+
+	e := &External{
+		cfg:   cfg,
+		cmd:   cmd,
+		stdin: stdin,
+		lines: make(chan string, 16),
+		quit:  make(chan struct{}),
+	}
+	go e.read(stdout)
+
+	if err := e.send(fmt.Sprintf("protocol %d", ProtocolVersion)); err != nil {
+		e.Close()
+		return nil, err
+	}
+	if _, err := e.await(context.Background(), cfg.MoveTime()+moveGrace, "ready"); err != nil {
+		e.Close()
+		return nil, fmt.Errorf("engine %s handshake: %w", cfg.Name, err)
+	}
+	return e, nil
+}
+
+// Move sends the position and waits for the engine's bestmove. The engine
+// gets its configured move time, cut short to answer within ctx's deadline.
+func (e *External) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	budget := e.cfg.MoveTime()
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline) - moveGrace; left < budget {
+			budget = max(left, minMoveTime)
+		}
+	}
+	if err := e.send(fmt.Sprintf("position %s %d\ngo %d", board.Encode(), player, budget.Milliseconds())); err != nil {
+		return -1, err
+	}
+
+	line, err := e.await(ctx, budget+moveGrace, "bestmove")
+	if err != nil {
+		return -1, err
+	}
+	col, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "bestmove")))
+	if err != nil {
+		e.Close()
+		return -1, fmt.Errorf("engine %s sent %q: %w", e.cfg.Name, line, err)
+	}
+	return col, nil
+}
+
+// Close asks the engine to quit and kills it. The reader goroutine exits
+// once the process is gone. An engine that had already failed on its own
+// is reported as ErrEngineCrashed.
+func (e *External) Close() error {
+	e.closeOnce.Do(func() {
+		close(e.quit)
+		io.WriteString(e.stdin, "quit\n")
+		e.stdin.Close()
+		e.cmd.Process.Kill()
+		if err := e.cmd.Wait(); err != nil && !isKilled(err) {
+			e.closeErr = fmt.Errorf("engine %s: %w (%v)", e.cfg.Name, ErrEngineCrashed, err)
+		}
+	})
+	return e.closeErr
+}
+
+// await reads lines until one starts with prefix. The engine is closed if
+// it doesn't answer within timeout, so a hung process never outlives it.
+func (e *External) await(ctx context.Context, timeout time.Duration, prefix string) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				e.Close()
+				return "", ErrEngineCrashed
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line, nil
+			}
+		case <-timer.C:
+			e.Close()
+			return "", ErrEngineTimeout
+		case <-ctx.Done():
+			e.Close()
+			return "", ctx.Err()
+		}
+	}
+}
+
+func (e *External) send(lines string) error {
+	if _, err := io.WriteString(e.stdin, lines+"\n"); err != nil {
+		e.Close()
+		return ErrEngineCrashed
+	}
+	return nil
+}
+
+// read forwards stdout lines until the process exits or Close is called
+func (e *External) read(stdout io.Reader) {
+	defer close(e.lines)
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		select {
+		case e.lines <- scanner.Text():
+		case <-e.quit:
+			return
+		}
+	}
+}
+
+// isKilled reports whether Wait failed only because Close killed the
+// process, rather than because it exited with a failure status
+func isKilled(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	return ok && status.Signaled()
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+// TestHelperEngine is not a real test: it runs as the engine process when
+// the test binary is started with ENGINE_HELPER set to a behaviour
+func TestHelperEngine(t *testing.T) {
+	mode := os.Getenv("ENGINE_HELPER")
+	if mode == "" {
+		return
+	}
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		line := in.Text()
+		switch {
+		case strings.HasPrefix(line, "protocol"):
+			fmt.Println("info helper engine")
+			fmt.Println("ready")
+		case strings.HasPrefix(line, "go"):
+			switch mode {
+			case "crash":
+				os.Exit(3)
+			case "fail":
+				fmt.Println("info giving up")
+				os.Exit(1)
+			case "hang":
+				time.Sleep(time.Hour)
+			default:
+				fmt.Println("info thinking")
+				fmt.Println("bestmove 3")
+			}
+		case line == "quit":
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+func startHelper(t *testing.T, mode string) *External {
+	t.Helper()
+	t.Setenv("ENGINE_HELPER", mode)
+	e, err := StartExternal(ExternalConfig{
+		Name:       mode,
+		Command:    os.Args[0],
+		Args:       []string{"-test.run=TestHelperEngine"},
+		MoveTimeMs: 200,
+	})
+	if err != nil {
+		t.Fatalf("StartExternal: %v", err)
+	}
+	return e
+}
+
+func TestExternalEngineMoves(t *testing.T) {
+	e := startHelper(t, "good")
+	defer e.Close()
+
+	for i := 0; i < 3; i++ {
+		col, err := e.Move(context.Background(), game.NewBoard(), game.Player2)
+		if err != nil || col != 3 {
+			t.Fatalf("Move = %d, %v; want 3", col, err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestExternalEngineFailures(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	crash := startHelper(t, "crash")
+	if _, err := crash.Move(context.Background(), game.NewBoard(), game.Player2); !errors.Is(err, ErrEngineCrashed) {
+		t.Errorf("crashed engine: got %v, want ErrEngineCrashed", err)
+	}
+
+	hang := startHelper(t, "hang")
+	start := time.Now()
+	if _, err := hang.Move(context.Background(), game.NewBoard(), game.Player2); !errors.Is(err, ErrEngineTimeout) {
+		t.Errorf("hung engine: got %v, want ErrEngineTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("timeout took %v", elapsed)
+	}
+
+	cancelled := startHelper(t, "hang")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cancelled.Move(ctx, game.NewBoard(), game.Player2); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled move: got %v, want context.Canceled", err)
+	}
+
+	// Readers and process waiters must all be gone
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("goroutines leaked: %d before, %d after", before, n)
+	}
+}
+
+func TestExternalEngineExitStatus(t *testing.T) {
+	// An engine that exits with a failure status crashed; Close didn't kill it
+	failed := startHelper(t, "fail")
+	if _, err := failed.Move(context.Background(), game.NewBoard(), game.Player2); !errors.Is(err, ErrEngineCrashed) {
+		t.Errorf("failed engine: got %v, want ErrEngineCrashed", err)
+	}
+	if err := failed.Close(); !errors.Is(err, ErrEngineCrashed) || !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("Close after exit status 1 = %v, want a crash", err)
+	}
+
+	// An engine Close has to kill isn't reported as crashed
+	hung := startHelper(t, "hang")
+	hung.send("position " + game.NewBoard().Encode() + " 2\ngo 200")
+	if err := hung.Close(); err != nil {
+		t.Errorf("Close of a killed engine = %v, want nil", err)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if !r.Has(DefaultEngine) {
+		t.Fatal("registry should include the built-in bot")
+	}
+	if _, err := r.New("missing"); !errors.Is(err, ErrUnknownEngine) {
+		t.Errorf("New(missing) = %v, want ErrUnknownEngine", err)
+	}
+
+	engine, err := r.New(DefaultEngine)
+	if err != nil {
+		t.Fatalf("New(default): %v", err)
+	}
+	board := game.NewBoard()
+	if col, err := engine.Move(context.Background(), board, game.Player2); err != nil || col < 0 {
+		t.Errorf("default engine Move = %d, %v", col, err)
+	}
+}
@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"connect-four/internal/game"
+)
+
+// MultiBot plays games with more than two players on a Grid. It follows
+// the two-player bot's priorities where they carry over:
+// 1. Win if possible
+// 2. Block the player who moves next, then anyone after them
+// 3. Don't give the next player a win on top of its own disc
+// 4. Build lines and break up opponents' lines, preferring the center
+type MultiBot struct{}
+
+// NewMultiBot creates a new multiplayer bot
+func NewMultiBot() *MultiBot {
+	return &MultiBot{}
+}
+
+// SelectMove chooses a column for player. order lists the other players
+// still in the game in the order they move after player. Returns -1 if the
+// grid is full.
+func (b *MultiBot) SelectMove(grid *game.Grid, player game.Cell, order []game.Cell) int {
+	valid := grid.ValidColumns()
+	if len(valid) == 0 {
+		return -1
+	}
+
+	// Priority 1: Win if possible
+	for _, col := range valid {
+		if winsWith(grid, player, col) {
+			return col
+		}
+	}
+
+	// Priority 2: Block, most urgent opponent first
+	for _, opponent := range order {
+		for _, col := range valid {
+			if winsWith(grid, opponent, col) {
+				return col
+			}
+		}
+	}
+
+	// Priorities 3 and 4
+	best, bestScore := -1, 0
+	for _, col := range valid {
+		score := b.score(grid, player, col)
+		if len(order) > 0 && b.setsUp(grid, player, order[0], col) {
+			score -= 1000
+		}
+		if best == -1 || score > bestScore {
+			best, bestScore = col, score
+		}
+	}
+	return best
+}
+
+// score rates the cell a disc in col lands on by the lines of four through
+// it: lines only player is building get more valuable with every disc,
+// and a line only one opponent is building is worth breaking up
+func (b *MultiBot) score(grid *game.Grid, player game.Cell, col int) int {
+	row := grid.GetDropRow(col)
+	score := -2 * abs(col-grid.Columns/2)
+
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, dir := range directions {
+		// Every window of four along dir that contains (row, col)
+		for start := -3; start <= 0; start++ {
+			owner, discs, onGrid := game.Empty, 0, true
+			mixed := false
+			for i := 0; i < 4; i++ {
+				r, c := row+dir[0]*(start+i), col+dir[1]*(start+i)
+				if r < 0 || r >= grid.Rows || c < 0 || c >= grid.Columns {
+					onGrid = false
+					break
+				}
+				cell := grid.GetCell(r, c)
+				if cell == game.Empty {
+					continue
+				}
+				if owner != game.Empty && cell != owner {
+					mixed = true
+				}
+				owner = cell
+				discs++
+			}
+			if !onGrid || mixed {
+				continue
+			}
+			switch {
+			case discs == 0:
+				score++
+			case owner == player:
+				score += discs * discs * 4
+			default:
+				score += discs * discs * 2
+			}
+		}
+	}
+	return score
+}
+
+// setsUp reports whether player's disc in col would let next win by
+// dropping on top of it
+func (b *MultiBot) setsUp(grid *game.Grid, player, next game.Cell, col int) bool {
+	after := grid.Clone()
+	if after.DropDisc(col, player) == -1 {
+		return false
+	}
+	return winsWith(after, next, col)
+}
+
+// winsWith reports whether player dropping into col connects four
+func winsWith(grid *game.Grid, player game.Cell, col int) bool {
+	row := grid.GetDropRow(col)
+	if row == -1 {
+		return false
+	}
+	grid.SetCell(row, col, player)
+	wins := grid.WinsAt(row, col, player)
+	grid.SetCell(row, col, game.Empty)
+	return wins
+}
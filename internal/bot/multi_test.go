@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+)
+
+func TestMultiBotWinsAndBlocks(t *testing.T) {
+	b := NewMultiBot()
+	grid := game.NewGrid(7, 9)
+	for _, col := range []int{0, 1, 2} {
+		grid.DropDisc(col, game.Player3)
+	}
+	for _, col := range []int{6, 7, 8} {
+		grid.DropDisc(col, game.Player2)
+	}
+
+	// Player3 moves next after Player1, so its threat comes first
+	if col := b.SelectMove(grid, game.Player1, []game.Cell{game.Player3, game.Player2}); col != 3 {
+		t.Errorf("blocked column %d, want Player3's threat on 3", col)
+	}
+	if col := b.SelectMove(grid, game.Player1, []game.Cell{game.Player2, game.Player3}); col != 5 {
+		t.Errorf("blocked column %d, want Player2's threat on 5", col)
+	}
+	if col := b.SelectMove(grid, game.Player2, []game.Cell{game.Player3, game.Player1}); col != 5 {
+		t.Errorf("played %d, want the win on 5", col)
+	}
+}
+
+func TestMultiBotsFinishGames(t *testing.T) {
+	for _, rule := range []game.MultiRule{game.RuleFirstToConnect, game.RuleElimination} {
+		for seats := game.MinMultiPlayers; seats <= game.MaxMultiPlayers; seats++ {
+			players := make([]*game.PlayerInfo, seats)
+			for i := range players {
+				players[i] = &game.PlayerInfo{Username: string(rune('a' + i)), IsBot: true}
+			}
+			g, err := game.NewMultiGame(players, rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := NewMultiBot()
+			for !g.IsGameOver() {
+				order := g.TurnOrder()
+				grid, player := g.Snapshot()
+				if _, errMsg := g.MakeMove(player, b.SelectMove(grid, player, order[1:])); errMsg != "" {
+					t.Fatalf("%s, %d seats: %s", rule, seats, errMsg)
+				}
+			}
+			for _, p := range g.Placements() {
+				if p.Place < 1 || p.Place > seats {
+					t.Errorf("%s, %d seats: bad placements %v", rule, seats, g.Placements())
+				}
+			}
+		}
+	}
+}
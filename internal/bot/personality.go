@@ -0,0 +1,287 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+// Personality shapes how the built-in bot plays: what it looks for in a
+// move, how much randomness it allows among good moves and how often it
+// slips on purpose
+type Personality struct {
+	Name string `json:"name"`
+
+	Attack  float64 `json:"attack"`  // weight on the bot's own lines and threats
+	Defense float64 `json:"defense"` // weight on taking away the opponent's
+	Traps   float64 `json:"traps"`   // weight on poisoned columns and stacked threats it sets up
+
+	// Randomness among candidate moves, in evaluation points: moves within
+	// a few Temperatures of the best one still get picked now and then
+	Temperature float64 `json:"temperature"`
+
+	MistakeRate float64 `json:"mistakeRate"` // chance of a random move that doesn't lose at once
+	MissRate    float64 `json:"missRate"`    // chance of overlooking an immediate win or block
+
+	Pace float64 `json:"pace"` // think time relative to the base delay
+}
+
+// Built-in personalities
+const (
+	PersonalityAggressive = "aggressive"
+	PersonalityDefensive  = "defensive"
+	PersonalityTrappy     = "trappy"
+	PersonalityBeginner   = "beginner"
+)
+
+// Personalities players can pick, by name
+var Personalities = map[string]Personality{
+	PersonalityAggressive: {
+		Name: PersonalityAggressive, Attack: 1.5, Defense: 0.6, Traps: 0.5,
+		Temperature: 1, MistakeRate: 0.02, Pace: 0.8,
+	},
+	PersonalityDefensive: {
+		Name: PersonalityDefensive, Attack: 0.6, Defense: 1.5, Traps: 0.2,
+		Temperature: 1, MistakeRate: 0.02, Pace: 1.1,
+	},
+	PersonalityTrappy: {
+		Name: PersonalityTrappy, Attack: 1, Defense: 0.8, Traps: 1,
+		Temperature: 1.5, MistakeRate: 0.03, Pace: 1.3,
+	},
+	PersonalityBeginner: {
+		Name: PersonalityBeginner, Attack: 0.8, Defense: 0.8,
+		Temperature: 6, MistakeRate: 0.2, MissRate: 0.25, Pace: 1.6,
+	},
+}
+
+// PersonalityNames lists the personalities in alphabetical order
+func PersonalityNames() []string {
+	names := make([]string, 0, len(Personalities))
+	for name := range Personalities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsPersonality reports whether name is a built-in personality
+func IsPersonality(name string) bool {
+	_, ok := Personalities[name]
+	return ok
+}
+
+// RandomPersonality picks a personality for a player who didn't choose one
+func RandomPersonality() string {
+	names := PersonalityNames()
+	return names[rand.Intn(len(names))]
+}
+
+// Weights of the trap term: each column the opponent can't play because of
+// a threat right above it, and a stacked or double threat
+const (
+	poisonedColumnScore = 10
+	stackedThreatScore  = 30
+)
+
+// Thinker is an engine that picks its own think time. base is the delay
+// for an average position.
+type Thinker interface {
+	ThinkTime(board *game.Board, player game.Cell, base time.Duration) time.Duration
+}
+
+// ThinkTime is how long a bot should appear to think about board: longer
+// with many playable moves and threats on the board, quicker when the move
+// is forced
+func ThinkTime(board *game.Board, player game.Cell, base time.Duration) time.Duration {
+	b := NewBot()
+	m := FindThreats(board)
+	if m.Immediate[player] > 0 || m.Immediate[other(player)] > 0 {
+		return base / 2
+	}
+	safe := 0
+	for _, col := range board.ValidColumns() {
+		if !b.givesOpponentWin(board, player, col) {
+			safe++
+		}
+	}
+	factor := 0.5 + float64(safe)/game.Columns + 0.1*math.Min(float64(len(m.Threats)), 5)
+	return time.Duration(float64(base) * factor)
+}
+
+// Personal is the built-in bot playing with a personality. Its moves are
+// random, so it isn't safe for concurrent use; create one per game.
+type Personal struct {
+	bot         *Bot
+	personality Personality
+	rng         *rand.Rand
+}
+
+// NewPersonal creates a bot with the given personality; the same seed
+// gives the same moves
+func NewPersonal(p Personality, seed int64) *Personal {
+	return &Personal{bot: NewBot(), personality: p, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Personality returns the bot's personality
+func (pb *Personal) Personality() Personality {
+	return pb.personality
+}
+
+// SelectMove chooses a column: wins and blocks first unless the bot
+// overlooks them, then a weighted random pick among moves that don't lose
+// at once, scored by the personality. Returns -1 if the board is full.
+func (pb *Personal) SelectMove(board *game.Board, player game.Cell) int {
+	valid := board.ValidColumns()
+	if len(valid) == 0 {
+		return -1
+	}
+	opponent := other(player)
+
+	candidates := valid
+	if !pb.chance(pb.personality.MissRate) {
+		for _, col := range valid {
+			if pb.bot.canWin(board, player, col) {
+				return col
+			}
+		}
+		for _, col := range valid {
+			if pb.bot.canWin(board, opponent, col) {
+				return col
+			}
+		}
+		var safe []int
+		for _, col := range valid {
+			if !pb.bot.givesOpponentWin(board, player, col) {
+				safe = append(safe, col)
+			}
+		}
+		if len(safe) > 0 {
+			candidates = safe
+		}
+	}
+
+	if pb.chance(pb.personality.MistakeRate) {
+		return candidates[pb.rng.Intn(len(candidates))]
+	}
+
+	scores := make([]float64, len(candidates))
+	for i, col := range candidates {
+		scores[i] = pb.score(board, player, col)
+	}
+	return candidates[pb.pick(scores)]
+}
+
+// score rates playing col from the personality's point of view
+func (pb *Personal) score(board *game.Board, player game.Cell, col int) float64 {
+	opponent := other(player)
+	after := board.Clone()
+	after.DropDisc(col, player)
+	m := FindThreats(after)
+
+	own, theirs := float64(lineScore(after, player)), float64(lineScore(after, opponent))
+	for c := 0; c < game.Columns; c++ {
+		own += float64(columnThreatScore(&m, player, opponent, c))
+		theirs += float64(columnThreatScore(&m, opponent, player, c))
+	}
+	if m.Double[player] {
+		own += doubleThreatScore
+	}
+	if m.Double[opponent] {
+		theirs += doubleThreatScore
+	}
+	switch m.ParityWinner() {
+	case player:
+		own += parityWinScore
+	case opponent:
+		theirs += parityWinScore
+	}
+
+	traps := 0.0
+	for c := 0; c < game.Columns; c++ {
+		if m.Poisoned[opponent][c] && !after.IsColumnFull(c) {
+			traps += poisonedColumnScore
+		}
+	}
+	if m.Double[player] {
+		traps += stackedThreatScore
+	}
+
+	center := float64(game.Columns/2 - abs(col-game.Columns/2))
+	p := pb.personality
+	return p.Attack*own - p.Defense*theirs + p.Traps*traps + center
+}
+
+// pick chooses an index with probability growing exponentially with its
+// score; a zero temperature always takes the best score
+func (pb *Personal) pick(scores []float64) int {
+	best := 0
+	for i, s := range scores {
+		if s > scores[best] {
+			best = i
+		}
+	}
+	t := pb.personality.Temperature
+	if t <= 0 {
+		return best
+	}
+
+	weights := make([]float64, len(scores))
+	total := 0.0
+	for i, s := range scores {
+		weights[i] = math.Exp((s - scores[best]) / t)
+		total += weights[i]
+	}
+	r := pb.rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return best
+}
+
+func (pb *Personal) chance(rate float64) bool {
+	return rate > 0 && pb.rng.Float64() < rate
+}
+
+// ThinkTime implements Thinker: ThinkTime scaled by the personality's pace,
+// give or take a quarter
+func (pb *Personal) ThinkTime(board *game.Board, player game.Cell, base time.Duration) time.Duration {
+	jitter := 0.75 + pb.rng.Float64()/2
+	return time.Duration(float64(ThinkTime(board, player, base)) * pb.personality.Pace * jitter)
+}
+
+// Move implements Engine
+func (pb *Personal) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	return pb.SelectMove(board, player), nil
+}
+
+// Close implements Engine; the bot holds nothing
+func (pb *Personal) Close() error {
+	return nil
+}
+
+// lineScore counts only player's open twos and threes
+func lineScore(board *game.Board, player game.Cell) int {
+	return sumWindows(board, player, func(own, theirs, empty int) int {
+		if theirs > 0 {
+			return 0
+		}
+		return windowScore(own, theirs, empty)
+	})
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,133 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"connect-four/internal/arena"
+	"connect-four/internal/game"
+)
+
+func TestPersonalTakesWinsAndBlocks(t *testing.T) {
+	for _, name := range []string{PersonalityAggressive, PersonalityDefensive, PersonalityTrappy} {
+		for seed := int64(0); seed < 20; seed++ {
+			pb := NewPersonal(Personalities[name], seed)
+
+			board := game.NewBoard()
+			board.DropDisc(0, game.Player2)
+			board.DropDisc(1, game.Player2)
+			board.DropDisc(2, game.Player2)
+			if col := pb.SelectMove(board, game.Player1); col != 3 {
+				t.Fatalf("%s (seed %d) played %d instead of blocking column 3", name, seed, col)
+			}
+			if col := pb.SelectMove(board, game.Player2); col != 3 {
+				t.Fatalf("%s (seed %d) played %d instead of winning in column 3", name, seed, col)
+			}
+		}
+	}
+}
+
+func TestPersonalIsRandomButSeeded(t *testing.T) {
+	play := func(seed int64) []int {
+		pb := NewPersonal(Personalities[PersonalityTrappy], seed)
+		board := game.NewBoard()
+		var moves []int
+		player := game.Player1
+		for i := 0; i < 10; i++ {
+			col := pb.SelectMove(board, player)
+			board.DropDisc(col, player)
+			moves = append(moves, col)
+			player = other(player)
+		}
+		return moves
+	}
+
+	first := play(1)
+	if again := play(1); !equalMoves(first, again) {
+		t.Errorf("same seed played %v then %v", first, again)
+	}
+	distinct := 0
+	for seed := int64(2); seed < 12; seed++ {
+		if !equalMoves(first, play(seed)) {
+			distinct++
+		}
+	}
+	if distinct == 0 {
+		t.Error("every seed played the same game")
+	}
+}
+
+func TestBeginnerIsWeaker(t *testing.T) {
+	score := 0.0
+	games := 40
+	for i := 0; i < games; i++ {
+		strong := NewPersonal(Personalities[PersonalityAggressive], int64(i))
+		beginner := NewPersonal(Personalities[PersonalityBeginner], int64(i))
+		// Alternate colors
+		if i%2 == 0 {
+			score += points(arena.Play(strong, beginner, nil), game.Player1)
+		} else {
+			score += points(arena.Play(beginner, strong, nil), game.Player2)
+		}
+	}
+	if rate := score / float64(games); rate < 0.7 {
+		t.Errorf("aggressive scored %.0f%% against beginner, want at least 70%%", rate*100)
+	}
+}
+
+func TestThinkTime(t *testing.T) {
+	base := 400 * time.Millisecond
+
+	open := ThinkTime(game.NewBoard(), game.Player1, base)
+	if open < base {
+		t.Errorf("opening think time %v, want at least %v", open, base)
+	}
+
+	forced := game.NewBoard()
+	forced.DropDisc(0, game.Player2)
+	forced.DropDisc(1, game.Player2)
+	forced.DropDisc(2, game.Player2)
+	if got := ThinkTime(forced, game.Player1, base); got != base/2 {
+		t.Errorf("forced move think time %v, want %v", got, base/2)
+	}
+
+	pb := NewPersonal(Personalities[PersonalityBeginner], 1)
+	if got := pb.ThinkTime(game.NewBoard(), game.Player1, base); got <= open {
+		t.Errorf("beginner thinks %v, want longer than %v", got, open)
+	}
+}
+
+func TestRegistryHasPersonalities(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range PersonalityNames() {
+		engine, err := r.New(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, ok := engine.(Thinker); !ok {
+			t.Errorf("%s should pick its own think time", name)
+		}
+	}
+}
+
+func points(winner, side game.Cell) float64 {
+	switch winner {
+	case side:
+		return 1
+	case game.Empty:
+		return 0.5
+	}
+	return 0
+}
+
+func equalMoves(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	"connect-four/internal/game"
+)
+
+// Popper is an engine that can play PopOut, where a move may pop one of
+// the player's own discs from the bottom of a column instead of dropping
+// one. pop is false for a drop.
+type Popper interface {
+	MovePopOut(ctx context.Context, board *game.Board, player game.Cell) (col int, pop bool, err error)
+}
+
+// PopOutDepth is how far ahead the PopOut bot looks at most; within the
+// move's time budget it usually stops sooner
+const PopOutDepth = 10
+
+// PopOut plays the PopOut variant with an alpha-beta search over drops and
+// pops, deepening one ply at a time within the move's time budget like
+// Search. Repetition draws are left out of the search.
+type PopOut struct {
+	Depth int
+	Eval  Evaluator
+}
+
+// NewPopOut creates a PopOut bot looking depth plies ahead
+func NewPopOut(depth int, eval Evaluator) *PopOut {
+	if depth < 1 {
+		depth = 1
+	}
+	if eval == nil {
+		eval = Evaluators[DefaultEvaluator]
+	}
+	return &PopOut{Depth: depth, Eval: eval}
+}
+
+// popOutMove is a drop or, if pop is set, a pop in col
+type popOutMove struct {
+	col int
+	pop bool
+}
+
+// popSearch is one search: the rules it plays by and when to stop
+type popSearch struct {
+	eval Evaluator
+	pops bool // false plays drops only, by the classic rules
+	stop *stopper
+}
+
+// MovePopOut implements Popper
+func (p *PopOut) MovePopOut(ctx context.Context, board *game.Board, player game.Cell) (int, bool, error) {
+	m, err := p.search(ctx, board, player, true)
+	return m.col, m.pop, err
+}
+
+// Move implements Engine, dropping discs only
+func (p *PopOut) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	m, err := p.search(ctx, board, player, false)
+	return m.col, err
+}
+
+// Close implements Engine; the PopOut bot holds nothing
+func (p *PopOut) Close() error {
+	return nil
+}
+
+// search deepens until Depth, a forced win or ctx's deadline and returns
+// the best move of the deepest search it finished; see Search.Move
+func (p *PopOut) search(ctx context.Context, board *game.Board, player game.Cell, pops bool) (popOutMove, error) {
+	if err := ctx.Err(); err != nil {
+		return popOutMove{col: -1}, err
+	}
+	s := &popSearch{eval: p.Eval, pops: pops}
+	best, score := s.root(*board, player, 1)
+	s.stop = &stopper{ctx: ctx}
+	for depth := 2; depth <= p.Depth && score < winScore; depth++ {
+		m, sc := s.root(*board, player, depth)
+		if s.stop.stopped {
+			break
+		}
+		best, score = m, sc
+	}
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return popOutMove{col: -1}, err
+	}
+	return best, nil
+}
+
+// moves lists player's legal moves, drops before pops and the center first
+func (s *popSearch) moves(b *game.Board, player game.Cell) []popOutMove {
+	var moves []popOutMove
+	for _, col := range centerOrder {
+		if !b.IsColumnFull(col) {
+			moves = append(moves, popOutMove{col: col})
+		}
+	}
+	if s.pops {
+		for _, col := range centerOrder {
+			if b.CanPop(col, player) {
+				moves = append(moves, popOutMove{col: col, pop: true})
+			}
+		}
+	}
+	return moves
+}
+
+// play makes the move on b and returns who connected four, if anyone
+func (s *popSearch) play(b *game.Board, m popOutMove, player game.Cell) game.Cell {
+	if m.pop {
+		b.PopDisc(m.col)
+		return b.PopWinner(m.col, player)
+	}
+	row := b.DropDisc(m.col, player)
+	if b.WinsAt(row, m.col, player) {
+		return player
+	}
+	return game.Empty
+}
+
+// root searches every move to depth and returns the best one with its
+// score; col is -1 if player has no move
+func (s *popSearch) root(b game.Board, player game.Cell, depth int) (popOutMove, int) {
+	best, bestScore := popOutMove{col: -1}, -winScore*2
+	alpha, beta := -winScore*2, winScore*2
+	for _, m := range s.moves(&b, player) {
+		score := s.score(b, m, player, depth-1, alpha, beta)
+		if score > bestScore {
+			best, bestScore = m, score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return best, bestScore
+}
+
+// score plays m on a copy of b and scores the result for player
+func (s *popSearch) score(b game.Board, m popOutMove, player game.Cell, depth, alpha, beta int) int {
+	switch s.play(&b, m, player) {
+	case player:
+		return winScore + depth
+	case other(player):
+		return -(winScore + depth)
+	}
+	return -s.negamax(b, other(player), depth, -beta, -alpha)
+}
+
+// negamax scores b from the point of view of player, who is to move. A
+// player with no legal move is stuck, which draws.
+func (s *popSearch) negamax(b game.Board, player game.Cell, depth, alpha, beta int) int {
+	if s.stop.check() {
+		return 0
+	}
+	moves := s.moves(&b, player)
+	if len(moves) == 0 {
+		return 0
+	}
+	if depth == 0 {
+		return s.eval(&b, player)
+	}
+
+	best := -winScore * 2
+	for _, m := range moves {
+		score := s.score(b, m, player, depth-1, alpha, beta)
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+func TestPopOutTakesWinningPop(t *testing.T) {
+	// Popping column 3 drops Player1's disc into a row of four; no drop
+	// wins at once
+	board, err := game.DecodeBoard(
+		"0000000" +
+			"0000000" +
+			"0000000" +
+			"0001000" +
+			"1112000" +
+			"2121000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	col, pop, err := NewPopOut(2, nil).MovePopOut(context.Background(), board, game.Player1)
+	if err != nil {
+		t.Fatalf("MovePopOut: %v", err)
+	}
+	if col != 3 || !pop {
+		t.Errorf("MovePopOut = %d (pop %v), want pop on 3", col, pop)
+	}
+}
+
+func TestPopOutAvoidsLosingPop(t *testing.T) {
+	// Player1's only poppable disc sits under Player2's three in column 0's
+	// row: popping it would bring Player2's disc down next to them
+	board, err := game.DecodeBoard(
+		"0000000" +
+			"0000000" +
+			"0000000" +
+			"0000000" +
+			"2000000" +
+			"1222000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	col, pop, err := NewPopOut(2, nil).MovePopOut(context.Background(), board, game.Player1)
+	if err != nil {
+		t.Fatalf("MovePopOut: %v", err)
+	}
+	if pop {
+		t.Errorf("MovePopOut popped column %d, handing Player2 the win", col)
+	}
+	if col != 4 {
+		t.Errorf("MovePopOut = %d, want the block on 4", col)
+	}
+}
+
+func TestPopOutBeatsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	bot := NewPopOut(3, nil)
+	wins := 0
+	for i := 0; i < 10; i++ {
+		g := game.NewVariantGame(&game.PlayerInfo{Username: "bot"}, &game.PlayerInfo{Username: "random"}, game.VariantPopOut)
+		for !g.IsGameOver() {
+			if g.CurrentTurn == game.Player1 {
+				board, _ := g.Snapshot()
+				col, pop, _ := bot.MovePopOut(context.Background(), board, game.Player1)
+				if pop {
+					g.MakePop(game.Player1, col)
+				} else {
+					g.MakeMove(game.Player1, col)
+				}
+				continue
+			}
+			// Random legal move for Player2
+			for {
+				col := rng.Intn(game.Columns)
+				if rng.Intn(4) == 0 {
+					if _, err := g.MakePop(game.Player2, col); err == "" {
+						break
+					}
+				} else if _, err := g.MakeMove(game.Player2, col); err == "" {
+					break
+				}
+			}
+		}
+		if g.Winner == game.Player1 {
+			wins++
+		}
+	}
+	if wins < 9 {
+		t.Errorf("PopOut bot won %d/10 against random moves", wins)
+	}
+}
+
+func TestPopOutKeepsTimeBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	board := game.NewBoard()
+	for _, col := range []int{3, 3, 2, 4, 4, 2} {
+		board.DropDisc(col, game.Player1+game.Cell(col%2))
+	}
+	start := time.Now()
+	col, _, err := NewPopOut(40, nil).MovePopOut(ctx, board, game.Player1)
+	if err != nil {
+		t.Fatalf("an expired budget should still give a move: %v", err)
+	}
+	if col < 0 || col >= game.Columns {
+		t.Errorf("MovePopOut = %d, want a legal column", col)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("MovePopOut took %v with a 50ms budget", elapsed)
+	}
+}
@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	"connect-four/internal/game"
+)
+
+// Actor is an engine that plays from the whole position under a
+// game.Ruleset, for variants whose moves and state don't fit Move
+type Actor interface {
+	Act(ctx context.Context, s *game.State) (game.Action, error)
+}
+
+// RulesDepth is how far ahead the rules bot looks at most; within the
+// move's time budget it usually stops sooner
+const RulesDepth = 8
+
+// RulesBot plays any ruleset with an alpha-beta search over the moves the
+// rules allow, deepening one ply at a time within the move's time budget
+// like Search. It scores positions by the lines still open to each player
+// and the discs each has set aside in Pop 10.
+type RulesBot struct {
+	Rules game.Ruleset
+	Depth int
+}
+
+// NewRulesBot creates a bot for rules looking depth plies ahead
+func NewRulesBot(rules game.Ruleset, depth int) *RulesBot {
+	if depth < 1 {
+		depth = 1
+	}
+	return &RulesBot{Rules: rules, Depth: depth}
+}
+
+// Act implements Actor
+func (b *RulesBot) Act(ctx context.Context, s *game.State) (game.Action, error) {
+	if err := ctx.Err(); err != nil {
+		return game.Action{}, err
+	}
+	moves := b.Rules.LegalMoves(s)
+	if len(moves) == 0 {
+		return game.Action{}, errors.New("no legal move")
+	}
+
+	best, score := b.root(s, moves, 1, nil)
+	stop := &stopper{ctx: ctx}
+	for depth := 2; depth <= b.Depth && score < winScore; depth++ {
+		m, sc := b.root(s, moves, depth, stop)
+		if stop.stopped {
+			break
+		}
+		best, score = m, sc
+	}
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return game.Action{}, err
+	}
+	return best, nil
+}
+
+// Move implements Engine by playing board by the classic rules: a board
+// alone doesn't carry what other rulesets keep besides the discs
+func (b *RulesBot) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	classic := &RulesBot{Rules: game.Classic, Depth: b.Depth}
+	m, err := classic.Act(ctx, &game.State{Grid: game.GridFromBoard(board), Turn: player})
+	if err != nil {
+		return -1, err
+	}
+	return m.Column, nil
+}
+
+// Close implements Engine; the rules bot holds nothing
+func (b *RulesBot) Close() error {
+	return nil
+}
+
+// root searches every move to depth and returns the best one with its
+// score
+func (b *RulesBot) root(s *game.State, moves []game.Action, depth int, stop *stopper) (game.Action, int) {
+	best, bestScore := moves[0], -winScore*2
+	alpha, beta := -winScore*2, winScore*2
+	for _, m := range moves {
+		score := b.score(s, m, depth-1, alpha, beta, stop)
+		if score > bestScore {
+			best, bestScore = m, score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return best, bestScore
+}
+
+// score plays m on a copy of s and scores the result for the player who
+// played it. Rules can give that player the next move too, in which case
+// the score isn't negated.
+func (b *RulesBot) score(s *game.State, m game.Action, depth, alpha, beta int, stop *stopper) int {
+	next := s.Clone()
+	b.Rules.Apply(next, m)
+	if next.Turn == s.Turn {
+		return b.negamax(next, depth, alpha, beta, stop)
+	}
+	return -b.negamax(next, depth, -beta, -alpha, stop)
+}
+
+// negamax scores s from the point of view of the player to move
+func (b *RulesBot) negamax(s *game.State, depth, alpha, beta int, stop *stopper) int {
+	if stop.check() {
+		return 0
+	}
+	if b.Rules.Terminal(s) {
+		switch winner, _ := b.Rules.Winner(s); winner {
+		case game.Empty:
+			return 0
+		case s.Turn:
+			return winScore + depth
+		default:
+			return -(winScore + depth)
+		}
+	}
+	if depth == 0 {
+		return b.evaluate(s, s.Turn)
+	}
+
+	best := -winScore * 2
+	for _, m := range b.Rules.LegalMoves(s) {
+		score := b.score(s, m, depth-1, alpha, beta, stop)
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// evaluate scores s for player: every window of Connect cells along a
+// row, column or diagonal that only one player has discs in counts for
+// that player, more the fuller it is, and each disc set aside counts for
+// more than any line still being built. On a cylinder, windows run across
+// the edge too.
+func (b *RulesBot) evaluate(s *game.State, player game.Cell) int {
+	opponent := other(player)
+	score := 1000 * (s.Captured[player] - s.Captured[opponent])
+
+	n := b.Rules.Connect()
+	grid := s.Grid
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for row := 0; row < grid.Rows; row++ {
+		for col := 0; col < grid.Columns; col++ {
+			for _, dir := range directions {
+				endRow, endCol := row+dir[0]*(n-1), col+dir[1]*(n-1)
+				if endRow >= grid.Rows || (!s.Cylinder && (endCol < 0 || endCol >= grid.Columns)) {
+					continue
+				}
+				mine, theirs, blocked := 0, 0, false
+				for i := 0; i < n; i++ {
+					c := ((col+dir[1]*i)%grid.Columns + grid.Columns) % grid.Columns
+					switch grid.GetCell(row+dir[0]*i, c) {
+					case player:
+						mine++
+					case opponent:
+						theirs++
+					case game.Empty:
+					default:
+						blocked = true
+					}
+				}
+				switch {
+				case blocked || (mine > 0 && theirs > 0):
+				case mine > 0:
+					score += 1 << (2 * mine)
+				case theirs > 0:
+					score -= 1 << (2 * theirs)
+				}
+			}
+		}
+	}
+	return score
+}
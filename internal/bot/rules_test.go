@@ -0,0 +1,127 @@
+package bot
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+func TestRulesBotCompletesFive(t *testing.T) {
+	// Player1 has four along the bottom counting the prefilled disc in
+	// column 0; column 4 makes five
+	s := game.FiveInARow.NewState()
+	for _, col := range []int{1, 7, 2, 7, 3, 6} {
+		if _, err := game.FiveInARow.Apply(s, game.Action{Column: col}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewRulesBot(game.FiveInARow, 3).Act(context.Background(), s)
+	if err != nil {
+		t.Fatalf("Act: %v", err)
+	}
+	if m.Column != 4 || m.Disc != "" {
+		t.Errorf("Act = %+v, want a drop in column 4", m)
+	}
+}
+
+func TestRulesBotBlocks(t *testing.T) {
+	// Player2 threatens five along the bottom from column 8 leftwards
+	s := game.FiveInARow.NewState()
+	for _, col := range []int{1, 7, 1, 6, 1, 5} {
+		if _, err := game.FiveInARow.Apply(s, game.Action{Column: col}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := NewRulesBot(game.FiveInARow, 3).Act(context.Background(), s)
+	if err != nil {
+		t.Fatalf("Act: %v", err)
+	}
+	if m.Column != 4 {
+		t.Errorf("Act = %+v, want the block in column 4", m)
+	}
+}
+
+func TestRulesBotWrapsOnCylinder(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []int
+		want  int
+	}{
+		// Player1 has columns 5, 6 and 0 along the bottom: 1 finishes the
+		// line across the edge
+		{"completes", []int{5, 5, 6, 6, 0, 3}, 1},
+		// Player2 has columns 6, 0 and 1, open only at 5 across the edge
+		// and at 2; Player1 already sits on 2
+		{"blocks", []int{2, 6, 3, 0, 3, 1}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := game.Classic.NewState()
+			s.Cylinder = true
+			for _, col := range tt.moves {
+				if _, err := game.Classic.Apply(s, game.Action{Column: col}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			m, err := NewRulesBot(game.Classic, 3).Act(context.Background(), s)
+			if err != nil {
+				t.Fatalf("Act: %v", err)
+			}
+			if m.Column != tt.want {
+				t.Errorf("Act = %+v, want column %d", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestRulesBotBeatsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, rules := range []game.Ruleset{game.FiveInARow, game.PowerUp, game.Pop10} {
+		bot := NewRulesBot(rules, 2)
+		wins := 0
+		for i := 0; i < 4; i++ {
+			s := rules.NewState()
+			for ply := 0; ply < 400 && !rules.Terminal(s); ply++ {
+				var m game.Action
+				if s.Turn == game.Player1 {
+					var err error
+					if m, err = bot.Act(context.Background(), s); err != nil {
+						t.Fatalf("%s: Act: %v", rules.Name(), err)
+					}
+				} else {
+					moves := rules.LegalMoves(s)
+					m = moves[rng.Intn(len(moves))]
+				}
+				if _, err := rules.Apply(s, m); err != nil {
+					t.Fatalf("%s: %+v: %v", rules.Name(), m, err)
+				}
+			}
+			if winner, _ := rules.Winner(s); winner == game.Player1 {
+				wins++
+			}
+		}
+		if wins < 3 {
+			t.Errorf("%s: rules bot won %d/4 against random moves", rules.Name(), wins)
+		}
+	}
+}
+
+func TestRulesBotKeepsTimeBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	m, err := NewRulesBot(game.PowerUp, 40).Act(ctx, game.PowerUp.NewState())
+	if err != nil {
+		t.Fatalf("an expired budget should still give a move: %v", err)
+	}
+	if !game.Legal(game.PowerUp, game.PowerUp.NewState(), m) {
+		t.Errorf("Act = %+v, want a legal move", m)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Act took %v with a 50ms budget", elapsed)
+	}
+}
@@ -0,0 +1,276 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	"connect-four/internal/game"
+)
+
+// Evaluator scores a position from player's point of view; higher is better
+type Evaluator func(board *game.Board, player game.Cell) int
+
+// Evaluators available to the search bot, by name
+var Evaluators = map[string]Evaluator{
+	"windows": EvaluateWindows,
+	"center":  EvaluateCenter,
+	"threats": EvaluateThreats,
+}
+
+// DefaultEvaluator is used when a search bot doesn't name one
+const DefaultEvaluator = "windows"
+
+// Score of a won position; quicker wins score higher
+const winScore = 1_000_000
+
+// Nodes searched between checks of the move's context
+const checkInterval = 4096
+
+// Search picks moves with an alpha-beta (negamax) search: SelectMove and
+// ScoreMoves search to Depth, Move deepens one ply at a time within the
+// move's time budget
+type Search struct {
+	Depth int
+	Eval  Evaluator
+}
+
+// NewSearch creates a search bot looking depth plies ahead
+func NewSearch(depth int, eval Evaluator) *Search {
+	if depth < 1 {
+		depth = 1
+	}
+	if eval == nil {
+		eval = Evaluators[DefaultEvaluator]
+	}
+	return &Search{Depth: depth, Eval: eval}
+}
+
+// SelectMove returns the column with the best search score, or -1 if the
+// board is full
+func (s *Search) SelectMove(board *game.Board, player game.Cell) int {
+	best, _ := s.root(board, player, s.Depth, nil)
+	return best
+}
+
+// Move implements Engine with iterative deepening: it searches depth 1,
+// 2, ... up to Depth and, once ctx's deadline passes, plays the best move
+// of the deepest search it finished. Depth 1 always finishes. A cancelled
+// ctx (rather than an expired one) returns its error instead of a move.
+func (s *Search) Move(ctx context.Context, board *game.Board, player game.Cell) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	best, score := s.root(board, player, 1, nil)
+	stop := &stopper{ctx: ctx}
+	for depth := 2; depth <= s.Depth && score < winScore; depth++ {
+		col, sc := s.root(board, player, depth, stop)
+		if stop.stopped {
+			break
+		}
+		best, score = col, sc
+	}
+	if err := ctx.Err(); errors.Is(err, context.Canceled) {
+		return -1, err
+	}
+	return best, nil
+}
+
+// root searches every move to depth and returns the best column and its
+// score. The result is meaningless if stop stopped the search.
+func (s *Search) root(board *game.Board, player game.Cell, depth int, stop *stopper) (int, int) {
+	b := board.Clone()
+	best, bestScore := -1, -winScore*2
+	alpha, beta := -winScore*2, winScore*2
+
+	for _, col := range centerOrder {
+		row := b.DropDisc(col, player)
+		if row == -1 {
+			continue
+		}
+		score := -s.negamax(b, row, col, player, depth-1, -beta, -alpha, stop)
+		b[row][col] = game.Empty
+
+		if score > bestScore {
+			best, bestScore = col, score
+		}
+		if score > alpha {
+			alpha = score
+		}
+	}
+	return best, bestScore
+}
+
+// ScoreMoves searches every legal move with a full window and returns its
+// score from player's point of view, by column. Unlike SelectMove every
+// score is exact for the search depth, not just a bound.
+func (s *Search) ScoreMoves(board *game.Board, player game.Cell) map[int]int {
+	b := board.Clone()
+	scores := make(map[int]int, game.Columns)
+	for _, col := range centerOrder {
+		row := b.DropDisc(col, player)
+		if row == -1 {
+			continue
+		}
+		scores[col] = -s.negamax(b, row, col, player, s.Depth-1, -winScore*2, winScore*2, nil)
+		b[row][col] = game.Empty
+	}
+	return scores
+}
+
+// Outcome reads a search score: 1 is a forced win, -1 a forced loss and 0
+// anything the search couldn't decide within its depth
+func Outcome(score int) int {
+	switch {
+	case score >= winScore:
+		return 1
+	case score <= -winScore:
+		return -1
+	}
+	return 0
+}
+
+// stopper aborts a search once its context is done, checking every
+// checkInterval nodes. A nil stopper never stops.
+type stopper struct {
+	ctx     context.Context
+	nodes   int
+	stopped bool
+}
+
+func (st *stopper) check() bool {
+	if st == nil {
+		return false
+	}
+	if !st.stopped {
+		st.nodes++
+		if st.nodes%checkInterval == 0 && st.ctx.Err() != nil {
+			st.stopped = true
+		}
+	}
+	return st.stopped
+}
+
+// negamax scores the position after mover dropped a disc at (row, col),
+// from the point of view of the player now to move. Once stop stops it
+// returns 0 at every node, and the caller throws the result away.
+func (s *Search) negamax(b *game.Board, row, col int, mover game.Cell, depth, alpha, beta int, stop *stopper) int {
+	if stop.check() {
+		return 0
+	}
+	if b.WinsAt(row, col, mover) {
+		return -(winScore + depth)
+	}
+	if b.IsBoardFull() {
+		return 0
+	}
+	player := other(mover)
+	if depth == 0 {
+		return s.Eval(b, player)
+	}
+
+	best := -winScore * 2
+	for _, c := range centerOrder {
+		r := b.DropDisc(c, player)
+		if r == -1 {
+			continue
+		}
+		score := -s.negamax(b, r, c, player, depth-1, -beta, -alpha, stop)
+		b[r][c] = game.Empty
+
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// EvaluateWindows scores every line of four cells: open twos and threes
+// count for their owner, and discs in the center column are worth a little
+// extra since they take part in the most lines
+func EvaluateWindows(board *game.Board, player game.Cell) int {
+	score := 0
+	for r := 0; r < game.Rows; r++ {
+		if board[r][game.Columns/2] == player {
+			score += 3
+		}
+	}
+	return score + sumWindows(board, player, windowScore)
+}
+
+// sumWindows adds score over every line of four cells, counted from
+// player's side
+func sumWindows(board *game.Board, player game.Cell, score func(own, theirs, empty int) int) int {
+	opponent := other(player)
+	total := 0
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for r := 0; r < game.Rows; r++ {
+		for c := 0; c < game.Columns; c++ {
+			for _, dir := range directions {
+				endR, endC := r+dir[0]*3, c+dir[1]*3
+				if endR < 0 || endR >= game.Rows || endC < 0 || endC >= game.Columns {
+					continue
+				}
+				var own, theirs, empty int
+				for i := 0; i < 4; i++ {
+					switch board[r+dir[0]*i][c+dir[1]*i] {
+					case player:
+						own++
+					case opponent:
+						theirs++
+					default:
+						empty++
+					}
+				}
+				total += score(own, theirs, empty)
+			}
+		}
+	}
+	return total
+}
+
+func windowScore(own, theirs, empty int) int {
+	switch {
+	case own == 3 && empty == 1:
+		return 5
+	case own == 2 && empty == 2:
+		return 2
+	case theirs == 3 && empty == 1:
+		return -4
+	}
+	return 0
+}
+
+// EvaluateCenter only counts discs by their distance from the center; a
+// weak baseline for comparing evaluators
+func EvaluateCenter(board *game.Board, player game.Cell) int {
+	weights := [game.Columns]int{0, 1, 2, 3, 2, 1, 0}
+	score := 0
+	for r := 0; r < game.Rows; r++ {
+		for c := 0; c < game.Columns; c++ {
+			switch board[r][c] {
+			case player:
+				score += weights[c]
+			case game.Empty:
+			default:
+				score -= weights[c]
+			}
+		}
+	}
+	return score
+}
+
+// Center-first move order makes alpha-beta cut off sooner
+var centerOrder = []int{3, 2, 4, 1, 5, 0, 6}
+
+func other(player game.Cell) game.Cell {
+	if player == game.Player1 {
+		return game.Player2
+	}
+	return game.Player1
+}
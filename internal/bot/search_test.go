@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"connect-four/internal/game"
+)
+
+func TestSearchTakesWin(t *testing.T) {
+	board := game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		board.DropDisc(col, game.Player1)
+		board.DropDisc(col, game.Player2)
+	}
+	// Player2 to move can win on 3 as well, but should not get the chance
+	if col := NewSearch(4, nil).SelectMove(board, game.Player1); col != 3 {
+		t.Errorf("SelectMove = %d, want winning column 3", col)
+	}
+}
+
+func TestSearchBlocksThreat(t *testing.T) {
+	board := game.NewBoard()
+	board.DropDisc(0, game.Player1)
+	board.DropDisc(1, game.Player1)
+	board.DropDisc(2, game.Player1)
+	board.DropDisc(6, game.Player2)
+	board.DropDisc(6, game.Player2)
+	for name, eval := range Evaluators {
+		if col := NewSearch(3, eval).SelectMove(board, game.Player2); col != 3 {
+			t.Errorf("%s: SelectMove = %d, want block on 3", name, col)
+		}
+	}
+}
+
+func TestSearchMoveMatchesFullDepth(t *testing.T) {
+	board := game.NewBoard()
+	for _, col := range []int{3, 3, 2, 4} {
+		board.DropDisc(col, game.Player1)
+	}
+	s := NewSearch(5, nil)
+	col, err := s.Move(context.Background(), board, game.Player2)
+	if err != nil {
+		t.Fatalf("Move: %v", err)
+	}
+	if want := s.SelectMove(board, game.Player2); col != want {
+		t.Errorf("Move = %d, want %d as at full depth", col, want)
+	}
+}
+
+func TestSearchMoveKeepsTimeBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	col, err := NewSearch(40, EvaluateThreats).Move(ctx, game.NewBoard(), game.Player1)
+	if err != nil {
+		t.Fatalf("an expired budget should still give a move: %v", err)
+	}
+	if col < 0 || col >= game.Columns {
+		t.Errorf("Move = %d, want a legal column", col)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Move took %v with a 50ms budget", elapsed)
+	}
+}
+
+func TestSearchMoveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	col, err := NewSearch(40, EvaluateThreats).Move(ctx, game.NewBoard(), game.Player1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Move = %d, %v; want context.Canceled", col, err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("cancelled search took %v to stop", elapsed)
+	}
+}
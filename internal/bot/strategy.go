@@ -8,9 +8,12 @@ import (
 // Decision priority per SRS Appendix B:
 // 1. Win if possible (immediate win in next move)
 // 2. Block opponent's immediate win
-// 3. Create winning opportunities (3-in-a-row with open ends)
-// 4. Block opponent's potential winning paths
-// 5. Play strategically (center column preference)
+// 3. Create a double threat
+// 4. Create winning opportunities (3-in-a-row with open ends)
+// 5. Block opponent's potential winning paths
+// 6. Play strategically (threat parity, then center column preference)
+// Steps 4-6 break ties with EvaluateThreats and never play a move that
+// lets the opponent win at once.
 type Bot struct{}
 
 // NewBot creates a new bot instance
@@ -20,6 +23,12 @@ func NewBot() *Bot {
 
 // SelectMove chooses the best column for the bot's next move
 func (b *Bot) SelectMove(board *game.Board, botPlayer game.Cell) int {
+	return b.Explain(board, botPlayer).Column
+}
+
+// Explain chooses the bot's next move like SelectMove and says which
+// priority rule picked it. Column is -1 if the board is full.
+func (b *Bot) Explain(board *game.Board, botPlayer game.Cell) Hint {
 	opponent := game.Player1
 	if botPlayer == game.Player1 {
 		opponent = game.Player2
@@ -27,59 +36,125 @@ func (b *Bot) SelectMove(board *game.Board, botPlayer game.Cell) int {
 
 	validColumns := board.ValidColumns()
 	if len(validColumns) == 0 {
-		return -1
+		return Hint{Column: -1, Row: -1}
 	}
 
 	// Priority 1: Win if possible
 	for _, col := range validColumns {
 		if b.canWin(board, botPlayer, col) {
-			return col
+			return newHint(board, col, ReasonWins)
 		}
 	}
 
 	// Priority 2: Block opponent's immediate win
 	for _, col := range validColumns {
 		if b.canWin(board, opponent, col) {
-			return col
+			return newHint(board, col, ReasonBlocksWin)
 		}
 	}
 
-	// Priority 3: Create 3-in-a-row with potential to win
+	// Priority 3: Double threat the opponent can't answer
 	for _, col := range validColumns {
-		if b.createsWinningPath(board, botPlayer, col) {
-			// Make sure this move doesn't give opponent a win
-			if !b.givesOpponentWin(board, botPlayer, col) {
-				return col
-			}
+		if b.createsDoubleThreat(board, botPlayer, col) && !b.givesOpponentWin(board, botPlayer, col) {
+			return newHint(board, col, ReasonDoubleThreat)
 		}
 	}
 
-	// Priority 4: Block opponent's 3-in-a-row
-	for _, col := range validColumns {
-		if b.createsWinningPath(board, opponent, col) {
-			if !b.givesOpponentWin(board, botPlayer, col) {
-				return col
-			}
+	// Priority 4: Create 3-in-a-row with potential to win, preferring
+	// threats on the bot's own parity
+	if col, ok := b.strongest(board, botPlayer, func(col int) bool {
+		return b.createsWinningPath(board, botPlayer, col) && !b.givesOpponentWin(board, botPlayer, col)
+	}); ok {
+		if b.createsParityThreat(board, botPlayer, col) {
+			return newHint(board, col, ReasonParityThreat)
 		}
+		return newHint(board, col, ReasonCreatesThree)
 	}
 
-	// Priority 5: Center preference with strategic ordering
-	preferredOrder := []int{3, 2, 4, 1, 5, 0, 6} // Center first
-	for _, col := range preferredOrder {
-		if b.isValidMove(board, col) && !b.givesOpponentWin(board, botPlayer, col) {
-			return col
-		}
+	// Priority 5: Block opponent's 3-in-a-row
+	if col, ok := b.strongest(board, botPlayer, func(col int) bool {
+		return b.createsWinningPath(board, opponent, col) && !b.givesOpponentWin(board, botPlayer, col)
+	}); ok {
+		return newHint(board, col, ReasonBlocksThree)
+	}
+
+	// Priority 6: Strongest safe move by threat evaluation, center first on
+	// ties, without playing under one of our own threats
+	threats := FindThreats(board)
+	if col, ok := b.strongest(board, botPlayer, func(col int) bool {
+		return !threats.Poisoned[opponent][col] && !b.givesOpponentWin(board, botPlayer, col)
+	}); ok {
+		return newHint(board, col, ReasonCenter)
+	}
+	if col, ok := b.strongest(board, botPlayer, func(col int) bool {
+		return !b.givesOpponentWin(board, botPlayer, col)
+	}); ok {
+		return newHint(board, col, ReasonCenter)
 	}
 
+	preferredOrder := []int{3, 2, 4, 1, 5, 0, 6} // Center first
 	// Fallback: any valid move
 	for _, col := range preferredOrder {
 		if b.isValidMove(board, col) {
-			return col
+			return newHint(board, col, ReasonOnlyMoves)
 		}
 	}
 
 	// Last resort
-	return validColumns[0]
+	return newHint(board, validColumns[0], ReasonOnlyMoves)
+}
+
+// strongest returns the column passing ok that scores best with
+// EvaluateThreats after the bot plays it; ties go to the more central column
+func (b *Bot) strongest(board *game.Board, botPlayer game.Cell, ok func(col int) bool) (int, bool) {
+	best, bestScore := -1, 0
+	for _, col := range centerOrder {
+		if !b.isValidMove(board, col) || !ok(col) {
+			continue
+		}
+		testBoard := board.Clone()
+		testBoard.DropDisc(col, botPlayer)
+		score := EvaluateThreats(testBoard, botPlayer)
+		if best == -1 || score > bestScore {
+			best, bestScore = col, score
+		}
+	}
+	return best, best != -1
+}
+
+// createsParityThreat checks if playing adds a threat on the player's own
+// parity (odd rows for Player1, even rows for Player2)
+func (b *Bot) createsParityThreat(board *game.Board, player game.Cell, col int) bool {
+	count := func(m ThreatMap) int {
+		n := 0
+		for _, t := range m.Threats {
+			if t.Player == player && t.GoodParity() {
+				n++
+			}
+		}
+		return n
+	}
+	testBoard := board.Clone()
+	if testBoard.DropDisc(col, player) == -1 {
+		return false
+	}
+	return count(FindThreats(testBoard)) > count(FindThreats(board))
+}
+
+// createsDoubleThreat checks if playing leaves two or more immediate wins,
+// which the opponent can't both block
+func (b *Bot) createsDoubleThreat(board *game.Board, player game.Cell, col int) bool {
+	testBoard := board.Clone()
+	if testBoard.DropDisc(col, player) == -1 {
+		return false
+	}
+	threats := 0
+	for _, c := range testBoard.ValidColumns() {
+		if b.canWin(testBoard, player, c) {
+			threats++
+		}
+	}
+	return threats >= 2
 }
 
 // canWin checks if playing in column will result in immediate win
@@ -204,7 +279,8 @@ func (b *Bot) createsWinningPath(board *game.Board, player game.Cell, col int) b
 	return false
 }
 
-// givesOpponentWin checks if this move allows opponent to win on next turn
+// givesOpponentWin checks if this move allows opponent to win on next turn,
+// on top of the disc or anywhere else
 func (b *Bot) givesOpponentWin(board *game.Board, botPlayer game.Cell, col int) bool {
 	opponent := game.Player1
 	if botPlayer == game.Player1 {
@@ -212,22 +288,14 @@ func (b *Bot) givesOpponentWin(board *game.Board, botPlayer game.Cell, col int)
 	}
 
 	testBoard := board.Clone()
-	row := testBoard.DropDisc(col, botPlayer)
-	if row == -1 {
+	if testBoard.DropDisc(col, botPlayer) == -1 {
 		return false
 	}
-
-	// Check if opponent can win by playing on top of our move
-	aboveRow := row - 1
-	if aboveRow >= 0 && testBoard.GetCell(aboveRow, col) == game.Empty {
-		// Simulate opponent playing on top
-		testBoard2 := testBoard.Clone()
-		testBoard2[aboveRow][col] = opponent
-		if b.checkWinAt(testBoard2, aboveRow, col, opponent) {
+	for _, c := range testBoard.ValidColumns() {
+		if b.canWin(testBoard, opponent, c) {
 			return true
 		}
 	}
-
 	return false
 }
 
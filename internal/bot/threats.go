@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"connect-four/internal/game"
+)
+
+// Threat is an empty cell that would complete four in a row for Player
+type Threat struct {
+	Row    int       `json:"row"` // board row, 0 = top
+	Col    int       `json:"col"`
+	Player game.Cell `json:"player"`
+}
+
+// Height is the threat's row counted from the bottom, starting at 1. Odd
+// threats favour the first player and even threats the second: once the
+// other columns fill up, the first player gets the odd squares and the
+// second player the even ones.
+func (t Threat) Height() int {
+	return game.Rows - t.Row
+}
+
+// Odd reports whether the threat sits on an odd row from the bottom
+func (t Threat) Odd() bool {
+	return t.Height()%2 == 1
+}
+
+// GoodParity reports whether the threat's row suits its owner: odd for
+// Player1, even for Player2
+func (t Threat) GoodParity() bool {
+	return t.Odd() == (t.Player == game.Player1)
+}
+
+// ThreatMap is every threat on a board, with the facts the bot and the
+// threats evaluator act on
+type ThreatMap struct {
+	Threats []Threat
+
+	// Per player (indexed by game.Cell): threats on odd and even rows,
+	// threats playable right now, and whether the player has a double
+	// threat (two playable threats, or two threats stacked in one column)
+	Odd       [3]int
+	Even      [3]int
+	Immediate [3]int
+	Double    [3]bool
+
+	// Poisoned[p][col] is true when p must not play col: the disc would
+	// land directly under an opponent threat
+	Poisoned [3][game.Columns]bool
+
+	// lowest[p][col] is the lowest threat row of p in col, -1 if none
+	lowest [3][game.Columns]int
+}
+
+// FindThreats enumerates both players' threats
+func FindThreats(board *game.Board) ThreatMap {
+	b := board.Clone()
+	var m ThreatMap
+	var isThreat [3][game.Rows][game.Columns]bool
+	for p := range m.lowest {
+		for c := range m.lowest[p] {
+			m.lowest[p][c] = -1
+		}
+	}
+
+	for c := 0; c < game.Columns; c++ {
+		drop := b.GetDropRow(c)
+		for r := 0; r < game.Rows; r++ {
+			if b[r][c] != game.Empty {
+				continue
+			}
+			for _, p := range []game.Cell{game.Player1, game.Player2} {
+				if !completesFour(b, r, c, p) {
+					continue
+				}
+				t := Threat{Row: r, Col: c, Player: p}
+				isThreat[p][r][c] = true
+				m.Threats = append(m.Threats, t)
+				if t.Odd() {
+					m.Odd[p]++
+				} else {
+					m.Even[p]++
+				}
+				if r == drop {
+					m.Immediate[p]++
+				}
+				if r == drop-1 {
+					m.Poisoned[other(p)][c] = true
+				}
+				if r > m.lowest[p][c] {
+					m.lowest[p][c] = r
+				}
+			}
+		}
+	}
+
+	for _, p := range []game.Cell{game.Player1, game.Player2} {
+		if m.Immediate[p] >= 2 {
+			m.Double[p] = true
+		}
+	}
+	for _, t := range m.Threats {
+		if t.Row > 0 && isThreat[t.Player][t.Row-1][t.Col] {
+			m.Double[t.Player] = true
+		}
+	}
+	return m
+}
+
+// Lowest returns the lowest threat row of player in col, -1 if none
+func (m *ThreatMap) Lowest(player game.Cell, col int) int {
+	return m.lowest[player][col]
+}
+
+// completesFour reports whether a disc of player at (row, col), ignoring
+// gravity, would make four in a row. The cell must be empty; the board is
+// left as it was.
+func completesFour(board *game.Board, row, col int, player game.Cell) bool {
+	board[row][col] = player
+	wins := board.WinsAt(row, col, player)
+	board[row][col] = game.Empty
+	return wins
+}
+
+// Weights for the threats evaluator: a threat on its owner's parity is
+// worth far more than one on the wrong rows, and stacked threats win
+// outright once they become playable
+const (
+	goodThreatScore   = 24
+	badThreatScore    = 8
+	doubleThreatScore = 200
+	parityWinScore    = 100
+)
+
+// EvaluateThreats extends EvaluateWindows with threat parity. Only the
+// lowest threat in each column counts; a threat above an opponent's lower
+// threat in the same column is worth little, since the lower one is
+// settled first.
+func EvaluateThreats(board *game.Board, player game.Cell) int {
+	score := EvaluateWindows(board, player)
+	m := FindThreats(board)
+	opponent := other(player)
+
+	for c := 0; c < game.Columns; c++ {
+		score += columnThreatScore(&m, player, opponent, c)
+		score -= columnThreatScore(&m, opponent, player, c)
+	}
+	if m.Double[player] {
+		score += doubleThreatScore
+	}
+	if m.Double[opponent] {
+		score -= doubleThreatScore
+	}
+	switch m.ParityWinner() {
+	case player:
+		score += parityWinScore
+	case opponent:
+		score -= parityWinScore
+	}
+	return score
+}
+
+// ParityWinner applies the basic zugzwang rules: with no other tactics,
+// Player1 wins with an odd threat if Player2 has no even threat, and
+// Player2 wins with an even threat if Player1 has no odd threat. Returns
+// game.Empty when parity decides nothing.
+func (m *ThreatMap) ParityWinner() game.Cell {
+	switch {
+	case m.Odd[game.Player1] > 0 && m.Even[game.Player2] == 0:
+		return game.Player1
+	case m.Even[game.Player2] > 0 && m.Odd[game.Player1] == 0:
+		return game.Player2
+	}
+	return game.Empty
+}
+
+// columnThreatScore scores p's lowest threat in col
+func columnThreatScore(m *ThreatMap, p, opponent game.Cell, col int) int {
+	row := m.lowest[p][col]
+	if row == -1 {
+		return 0
+	}
+	t := Threat{Row: row, Col: col, Player: p}
+	score := badThreatScore
+	if t.GoodParity() {
+		score = goodThreatScore
+	}
+	if theirs := m.lowest[opponent][col]; theirs > row {
+		score /= 4
+	}
+	return score
+}
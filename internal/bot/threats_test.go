@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+	"connect-four/internal/solver"
+)
+
+func TestFindThreatsParity(t *testing.T) {
+	board := game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		board.DropDisc(col, game.Player1)
+	}
+	m := FindThreats(board)
+	if m.Odd[game.Player1] != 1 || m.Immediate[game.Player1] != 1 {
+		t.Fatalf("bottom-row three: odd=%d immediate=%d, want 1 and 1", m.Odd[game.Player1], m.Immediate[game.Player1])
+	}
+	threat := m.Threats[0]
+	if threat.Col != 3 || threat.Height() != 1 || !threat.GoodParity() {
+		t.Errorf("threat = %+v, want an odd threat at the bottom of column 3", threat)
+	}
+	if m.ParityWinner() != game.Player1 {
+		t.Errorf("an odd threat with no even reply should favour Player1")
+	}
+
+	// The same three for Player2 is on the wrong parity
+	board = game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		board.DropDisc(col, game.Player2)
+	}
+	if m := FindThreats(board); m.Threats[0].GoodParity() || m.ParityWinner() != game.Empty {
+		t.Errorf("Player2's odd threat shouldn't count as good parity")
+	}
+}
+
+func TestFindThreatsPoisonedAndDouble(t *testing.T) {
+	// Player1 holds the second row of columns 0-2 over Player2's discs, so
+	// the second cell of column 3 is an (even) Player1 threat
+	board := game.NewBoard()
+	for _, col := range []int{0, 1, 2} {
+		board.DropDisc(col, game.Player2)
+		board.DropDisc(col, game.Player1)
+	}
+	m := FindThreats(board)
+	if !m.Poisoned[game.Player2][3] {
+		t.Error("Player2 playing column 3 would hand Player1 the win above it")
+	}
+	if m.Poisoned[game.Player1][3] {
+		t.Error("column 3 isn't poisoned for Player1")
+	}
+
+	// Two playable threats at once
+	board = game.NewBoard()
+	board.DropDisc(1, game.Player1)
+	board.DropDisc(2, game.Player1)
+	board.DropDisc(3, game.Player1)
+	if m := FindThreats(board); !m.Double[game.Player1] || m.Immediate[game.Player1] != 2 {
+		t.Errorf("open three should be a double threat, got %+v", m)
+	}
+}
+
+// Endgames where threat parity decides the result, checked against the
+// exact solver. Boards are game.Board.Encode strings, top row first. The
+// heuristic bot before the threats evaluator got every one of them wrong.
+var parityEndgames = []struct {
+	name   string
+	board  string
+	player game.Cell
+	hold   bool // only a draw is available; the bot must not lose
+}{
+	{"odd threat wins for Player1", "001212000212200012110001112000122212221112", game.Player1, false},
+	{"odd threat with no even answer", "021220002111000122100022112001122100211122", game.Player1, false},
+	{"lone odd threat", "021220002211000112200021110011121002221221", game.Player1, false},
+	{"even threat wins for Player2", "001211000212200122110121122021121101221220", game.Player2, false},
+	{"even threat on a crowded board", "001121000211200122111022122201121121221221", game.Player2, false},
+	{"Player2 holds against an odd threat", "011200002111000122101122122221122122211121", game.Player2, true},
+	{"Player2 holds a mixed position", "002100001220200211011011201212110222122112", game.Player2, true},
+}
+
+func TestParityEndgames(t *testing.T) {
+	b := NewBot()
+	for _, tc := range parityEndgames {
+		board, err := game.DecodeBoard(tc.board)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		remaining := (len(board.ValidColumns())*game.Rows + 1) / 2
+		col := b.SelectMove(board, tc.player)
+
+		if tc.hold {
+			after := board.Clone()
+			after.DropDisc(col, tc.player)
+			if solver.WinIn(after, other(tc.player), remaining) > 0 {
+				t.Errorf("%s: column %d loses", tc.name, col)
+			}
+			continue
+		}
+
+		depth := solver.WinIn(board, tc.player, remaining)
+		if depth == 0 {
+			t.Fatalf("%s: position should be a forced win", tc.name)
+		}
+		if !containsColumn(solver.WinningMoves(board, tc.player, depth), col) {
+			t.Errorf("%s: column %d doesn't keep the win", tc.name, col)
+		}
+	}
+}
+
+func containsColumn(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package correspondence
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// day is the unit players choose their time per move in
+const day = 24 * time.Hour
+
+// Time per move players can choose, in days
+const (
+	DefaultDaysPerMove = 3
+	MaxDaysPerMove     = 14
+)
+
+// Settings describe a new correspondence game
+type Settings struct {
+	Player1     string // the challenger, who moves first
+	Player2     string
+	Setup       game.Setup
+	DaysPerMove int // 0 picks DefaultDaysPerMove
+}
+
+// newRecord creates the stored form of a challenge p1 sends p2 at now.
+// Nobody's clock runs until p2 accepts it.
+func newRecord(p1, p2 *models.Player, s Settings, now time.Time) *models.GameRecord {
+	return &models.GameRecord{
+		ID:          uuid.New(),
+		Player1ID:   p1.ID,
+		Player2ID:   &p2.ID,
+		Variant:     string(s.Setup.Variant),
+		Cylinder:    s.Setup.Cylinder,
+		Moves:       "[]",
+		DaysPerMove: s.DaysPerMove,
+		Pending:     true,
+		StartedAt:   now,
+	}
+}
+
+// accept starts the game a challenge record stores at now, with username,
+// the challenged player, accepting it. The challenger has DaysPerMove from
+// now for the first move.
+func accept(record *models.GameRecord, username string, now time.Time) error {
+	if err := answerable(record, username); err != nil {
+		return err
+	}
+	if seat(record, username) != game.Player2 {
+		return ErrNotChallenged
+	}
+	deadline := now.Add(time.Duration(record.DaysPerMove) * day)
+	record.Pending = false
+	record.MoveDeadline = &deadline
+	record.StartedAt = now
+	return nil
+}
+
+// answerable checks that record is a challenge still waiting for an answer
+// and that username is one of its players, so may decline (or, as the
+// challenger, withdraw) it
+func answerable(record *models.GameRecord, username string) error {
+	if !record.Pending || record.EndedAt != nil {
+		return ErrAnswered
+	}
+	if seat(record, username) == game.Empty {
+		return ErrNotPlayer
+	}
+	return nil
+}
+
+// replay rebuilds the game a record stores by playing its moves again. The
+// record's players must be loaded.
+func replay(record *models.GameRecord) (*game.Game, error) {
+	var moves []game.Move
+	if err := json.Unmarshal([]byte(record.Moves), &moves); err != nil {
+		return nil, err
+	}
+
+	setup := game.Setup{Variant: game.Variant(record.Variant), Cylinder: record.Cylinder}
+	g := game.NewSetupGame(playerInfo(record.Player1), playerInfo(record.Player2), setup)
+	g.ID = record.ID
+	g.StartedAt = record.StartedAt
+	for i, m := range moves {
+		if _, reason := g.Play(m.Player, m.Action()); reason != "" {
+			return nil, fmt.Errorf("move %d doesn't replay: %s", i+1, reason)
+		}
+	}
+	g.Moves = moves
+
+	// Resigning and running out of time leave no move behind
+	if record.Result == models.GameResultForfeit && !g.IsGameOver() {
+		if record.WinnerID != nil && *record.WinnerID == record.Player1ID {
+			g.Forfeit(game.Player2)
+		} else {
+			g.Forfeit(game.Player1)
+		}
+	}
+	return g, nil
+}
+
+// playerInfo describes a stored player the way games do
+func playerInfo(p *models.Player) *game.PlayerInfo {
+	if p == nil {
+		return &game.PlayerInfo{}
+	}
+	return &game.PlayerInfo{ID: p.ID, Username: p.Username, IsBot: p.IsBot}
+}
+
+// seat returns the color username plays in record, Empty if they aren't
+// one of its players
+func seat(record *models.GameRecord, username string) game.Cell {
+	switch {
+	case record.Player1 != nil && record.Player1.Username == username:
+		return game.Player1
+	case record.Player2 != nil && record.Player2.Username == username:
+		return game.Player2
+	}
+	return game.Empty
+}
+
+// overdue reports whether the player to move in record ran out of time
+// before now
+func overdue(record *models.GameRecord, now time.Time) bool {
+	return record.InProgress() && record.MoveDeadline != nil && now.After(*record.MoveDeadline)
+}
+
+// play makes username's move in the game record stores and stores the
+// result, giving the next player DaysPerMove from now. The record isn't
+// touched if the move isn't allowed.
+func play(record *models.GameRecord, g *game.Game, username string, a game.Action, now time.Time) error {
+	if record.Pending {
+		return ErrNotAccepted
+	}
+	if !record.InProgress() {
+		return ErrGameOver
+	}
+	player := seat(record, username)
+	if player == game.Empty {
+		return ErrNotPlayer
+	}
+	if g.GetCurrentPlayer() != player {
+		return ErrNotYourTurn
+	}
+	if _, reason := g.Play(player, a); reason != "" {
+		return fmt.Errorf("%w: %s", ErrIllegalMove, reason)
+	}
+	return store(record, g, now)
+}
+
+// forfeit ends the game record stores with loser resigning or out of time
+func forfeit(record *models.GameRecord, g *game.Game, loser game.Cell, now time.Time) error {
+	g.Forfeit(loser)
+	return store(record, g, now)
+}
+
+// store copies g's moves into record, with the next move's deadline or,
+// once the game is over, its result
+func store(record *models.GameRecord, g *game.Game, now time.Time) error {
+	moves, err := json.Marshal(g.Moves)
+	if err != nil {
+		return err
+	}
+	record.Moves = string(moves)
+
+	if !g.IsGameOver() {
+		deadline := now.Add(time.Duration(record.DaysPerMove) * day)
+		record.MoveDeadline = &deadline
+		return nil
+	}
+
+	record.MoveDeadline = nil
+	record.Result = models.GameResultType(g.Result)
+	record.EndedAt = &now
+	record.DurationSeconds = int(now.Sub(record.StartedAt).Seconds())
+	switch g.Winner {
+	case game.Player1:
+		record.WinnerID = &record.Player1ID
+	case game.Player2:
+		record.WinnerID = record.Player2ID
+	}
+	return nil
+}
+
+// view describes the game record stores, which g was replayed from
+func view(record *models.GameRecord, g *game.Game) *models.CorrespondenceGamePayload {
+	v := &models.CorrespondenceGamePayload{
+		GameID:      record.ID.String(),
+		Player1:     g.Player1.Username,
+		Player2:     g.Player2.Username,
+		Variant:     string(g.Variant),
+		Cylinder:    g.Cylinder,
+		DaysPerMove: record.DaysPerMove,
+		Board:       g.Board.ToSlice(),
+		Moves:       len(g.Moves),
+		Pending:     record.Pending,
+	}
+	if n := len(g.Moves); n > 0 {
+		m := g.Moves[n-1]
+		v.LastMove = &models.CorrespondenceLastMove{
+			Player: int(m.Player),
+			Column: m.Column,
+			Row:    m.Row,
+			Type:   string(m.Type),
+			Disc:   string(m.Disc),
+		}
+	}
+	if g.Variant == game.VariantPop10 {
+		v.Captured = []int{g.Captured(game.Player1), g.Captured(game.Player2)}
+	}
+
+	if record.Pending {
+		return v
+	}
+	if record.InProgress() {
+		v.CurrentTurn = int(g.GetCurrentPlayer())
+		v.Deadline = record.MoveDeadline
+		return v
+	}
+	v.Result = string(record.Result)
+	v.WinningCells = g.WinningCells
+	if g.Winner != game.Empty {
+		v.Winner = g.GetPlayerInfo(g.Winner).Username
+	}
+	return v
+}
@@ -0,0 +1,264 @@
+package correspondence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+var start = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestChallenge has alice challenge bob a day before start
+func newTestChallenge(setup game.Setup, days int) *models.GameRecord {
+	alice := &models.Player{ID: uuid.New(), Username: "alice"}
+	bob := &models.Player{ID: uuid.New(), Username: "bob"}
+	record := newRecord(alice, bob, Settings{Player1: "alice", Player2: "bob", Setup: setup, DaysPerMove: days}, start.Add(-day))
+	record.Player1, record.Player2 = alice, bob
+	return record
+}
+
+// newTestRecord is a challenge bob accepted at start
+func newTestRecord(setup game.Setup, days int) *models.GameRecord {
+	record := newTestChallenge(setup, days)
+	if err := accept(record, "bob", start); err != nil {
+		panic(err)
+	}
+	return record
+}
+
+// playAll plays columns in turn order, alice first, each a day after the
+// last, reloading the game from the record before every move
+func playAll(t *testing.T, record *models.GameRecord, columns ...int) *game.Game {
+	t.Helper()
+	var g *game.Game
+	for i, col := range columns {
+		var err error
+		if g, err = replay(record); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+		username := "alice"
+		if g.GetCurrentPlayer() == game.Player2 {
+			username = "bob"
+		}
+		if err := play(record, g, username, game.Action{Column: col}, start.Add(time.Duration(i+1)*day)); err != nil {
+			t.Fatalf("move %d: %v", i+1, err)
+		}
+	}
+	return g
+}
+
+func TestPlaySetsDeadline(t *testing.T) {
+	record := newTestRecord(game.Setup{Variant: game.VariantClassic}, 3)
+	if want := start.Add(3 * day); !record.MoveDeadline.Equal(want) {
+		t.Fatalf("first deadline %v, want %v", record.MoveDeadline, want)
+	}
+
+	playAll(t, record, 3, 4)
+	if want := start.Add(2*day + 3*day); !record.MoveDeadline.Equal(want) {
+		t.Errorf("deadline %v, want three days after the last move", record.MoveDeadline)
+	}
+	if !record.InProgress() || record.Result != "" {
+		t.Errorf("game should still be on, result %q", record.Result)
+	}
+
+	g, err := replay(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := view(record, g)
+	if v.Moves != 2 || v.CurrentTurn != int(game.Player1) || v.LastMove == nil || v.LastMove.Column != 4 || v.LastMove.Player != 2 {
+		t.Errorf("view = %+v", v)
+	}
+	if v.Board[game.Rows-1][3] != 1 || v.Board[game.Rows-1][4] != 2 {
+		t.Errorf("board %v", v.Board)
+	}
+}
+
+func TestPlayRefuses(t *testing.T) {
+	record := newTestRecord(game.Setup{Variant: game.VariantClassic}, 1)
+	g, _ := replay(record)
+	moves := record.Moves
+
+	tests := []struct {
+		name     string
+		username string
+		action   game.Action
+		want     error
+	}{
+		{"outsider", "carol", game.Action{Column: 0}, ErrNotPlayer},
+		{"out of turn", "bob", game.Action{Column: 0}, ErrNotYourTurn},
+		{"off the board", "alice", game.Action{Column: 9}, ErrIllegalMove},
+		{"pop in classic", "alice", game.Action{Type: game.MovePop, Column: 0}, ErrIllegalMove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := play(record, g, tt.username, tt.action, start); !errors.Is(err, tt.want) {
+				t.Errorf("play = %v, want %v", err, tt.want)
+			}
+			if record.Moves != moves {
+				t.Errorf("a refused move changed the record: %s", record.Moves)
+			}
+		})
+	}
+}
+
+func TestPlayToWin(t *testing.T) {
+	record := newTestRecord(game.Setup{Variant: game.VariantClassic}, 2)
+	g := playAll(t, record, 0, 1, 0, 1, 0, 1, 0)
+
+	if record.InProgress() || record.MoveDeadline != nil {
+		t.Fatal("a won game should be over with no deadline")
+	}
+	if record.Result != models.GameResultPlayer1Win || record.WinnerID == nil || *record.WinnerID != record.Player1ID {
+		t.Errorf("result %q, winner %v", record.Result, record.WinnerID)
+	}
+	if want := int((7 * day).Seconds()); record.DurationSeconds != want {
+		t.Errorf("duration %d, want %d", record.DurationSeconds, want)
+	}
+	if err := play(record, g, "bob", game.Action{Column: 2}, start.Add(8*day)); !errors.Is(err, ErrGameOver) {
+		t.Errorf("moving after the end = %v, want ErrGameOver", err)
+	}
+
+	replayed, err := replay(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := view(record, replayed)
+	if v.Result != "player1" || v.Winner != "alice" || len(v.WinningCells) != 4 || v.CurrentTurn != 0 || v.Deadline != nil {
+		t.Errorf("view = %+v", v)
+	}
+}
+
+func TestForfeitSurvivesReplay(t *testing.T) {
+	for _, loser := range []game.Cell{game.Player1, game.Player2} {
+		record := newTestRecord(game.Setup{Variant: game.VariantClassic}, 1)
+		g := playAll(t, record, 3)
+		if err := forfeit(record, g, loser, start.Add(5*day)); err != nil {
+			t.Fatal(err)
+		}
+		if record.Result != models.GameResultForfeit || record.InProgress() {
+			t.Fatalf("result %q", record.Result)
+		}
+
+		replayed, err := replay(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !replayed.IsGameOver() || replayed.Winner == loser {
+			t.Errorf("loser %d: replayed winner %d", loser, replayed.Winner)
+		}
+		winner := "alice"
+		if loser == game.Player1 {
+			winner = "bob"
+		}
+		if v := view(record, replayed); v.Winner != winner || v.Result != "forfeit" {
+			t.Errorf("loser %d: view = %+v", loser, v)
+		}
+	}
+}
+
+func TestOverdue(t *testing.T) {
+	record := newTestRecord(game.Setup{Variant: game.VariantClassic}, 2)
+	if overdue(record, start.Add(2*day)) {
+		t.Error("the deadline itself is still in time")
+	}
+	if !overdue(record, start.Add(2*day+time.Second)) {
+		t.Error("a second after the deadline is overdue")
+	}
+
+	playAll(t, record, 0, 1, 0, 1, 0, 1, 0)
+	if overdue(record, start.Add(100*day)) {
+		t.Error("a finished game is never overdue")
+	}
+}
+
+func TestReplayKeepsSetup(t *testing.T) {
+	// Stored moves replay under the game's own rules and board: on a
+	// cylinder the bottom row wraps, and in Power Up a double disc gives
+	// another move
+	record := newTestRecord(game.Setup{Variant: game.VariantClassic, Cylinder: true}, 1)
+	playAll(t, record, 5, 5, 6, 6, 0, 0, 1)
+	if record.Result != models.GameResultPlayer1Win {
+		t.Fatalf("a row across the edge should win on a cylinder, result %q", record.Result)
+	}
+
+	record = newTestRecord(game.Setup{Variant: game.VariantPowerUp}, 1)
+	g, _ := replay(record)
+	if err := play(record, g, "alice", game.Action{Column: 3, Disc: game.DiscDouble}, start); err != nil {
+		t.Fatal(err)
+	}
+	g, err := replay(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.GetCurrentPlayer() != game.Player1 {
+		t.Error("the double disc's extra drop should survive a replay")
+	}
+	if v := view(record, g); v.Variant != "power_up" || v.LastMove.Disc != "double" {
+		t.Errorf("view = %+v", v)
+	}
+}
+
+func TestChallengeAccept(t *testing.T) {
+	record := newTestChallenge(game.Setup{Variant: game.VariantClassic}, 2)
+	g, _ := replay(record)
+
+	// Nobody's clock runs and nothing can be played before bob accepts
+	if record.InProgress() || record.MoveDeadline != nil || overdue(record, start.Add(100*day)) {
+		t.Fatal("an unanswered challenge shouldn't be running")
+	}
+	if err := play(record, g, "alice", game.Action{Column: 3}, start); !errors.Is(err, ErrNotAccepted) {
+		t.Errorf("moving before the challenge is accepted = %v, want ErrNotAccepted", err)
+	}
+	if v := view(record, g); !v.Pending || v.CurrentTurn != 0 || v.Deadline != nil || v.Result != "" {
+		t.Errorf("challenge view = %+v", v)
+	}
+
+	if err := accept(record, "carol", start); !errors.Is(err, ErrNotPlayer) {
+		t.Errorf("outsider accepting = %v, want ErrNotPlayer", err)
+	}
+	if err := accept(record, "alice", start); !errors.Is(err, ErrNotChallenged) {
+		t.Errorf("challenger accepting = %v, want ErrNotChallenged", err)
+	}
+	if err := accept(record, "bob", start); err != nil {
+		t.Fatal(err)
+	}
+	if !record.InProgress() || !record.StartedAt.Equal(start) || !record.MoveDeadline.Equal(start.Add(2*day)) {
+		t.Errorf("accepted at %v with deadline %v, want the clock to start then", record.StartedAt, record.MoveDeadline)
+	}
+	if err := accept(record, "bob", start); !errors.Is(err, ErrAnswered) {
+		t.Errorf("accepting twice = %v, want ErrAnswered", err)
+	}
+
+	playAll(t, record, 3)
+	if v := view(record, g); v.Pending {
+		t.Error("an accepted game shouldn't be pending")
+	}
+}
+
+func TestChallengeDecline(t *testing.T) {
+	record := newTestChallenge(game.Setup{Variant: game.VariantClassic}, 2)
+
+	// Either player may turn it down: bob declines, alice withdraws
+	for _, username := range []string{"bob", "alice"} {
+		if err := answerable(record, username); err != nil {
+			t.Errorf("%s declining = %v", username, err)
+		}
+	}
+	if err := answerable(record, "carol"); !errors.Is(err, ErrNotPlayer) {
+		t.Errorf("outsider declining = %v, want ErrNotPlayer", err)
+	}
+
+	// Once accepted, the game can only be resigned
+	if err := accept(record, "bob", start); err != nil {
+		t.Fatal(err)
+	}
+	if err := answerable(record, "bob"); !errors.Is(err, ErrAnswered) {
+		t.Errorf("declining an accepted game = %v, want ErrAnswered", err)
+	}
+}
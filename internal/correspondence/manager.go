@@ -0,0 +1,460 @@
+package correspondence
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/repository"
+)
+
+// Errors returned by correspondence operations. Their text is meant for
+// players; anything else that goes wrong is logged and reported as
+// ErrUnavailable.
+var (
+	ErrNotFound       = errors.New("correspondence game not found")
+	ErrPlayerNotFound = errors.New("player not found")
+	ErrInvalidDays    = errors.New("daysPerMove must be 1 to 14")
+	ErrInvalidVariant = errors.New("unknown variant")
+	ErrSamePlayer     = errors.New("you can't play yourself")
+	ErrNotPlayer      = errors.New("you aren't playing this game")
+	ErrNotChallenged  = errors.New("only the challenged player can accept")
+	ErrNotAccepted    = errors.New("the challenge hasn't been accepted yet")
+	ErrAnswered       = errors.New("the challenge has already been answered")
+	ErrNotYourTurn    = errors.New("not your turn")
+	ErrIllegalMove    = errors.New("illegal move")
+	ErrGameOver       = errors.New("game is over")
+	ErrConflict       = errors.New("the game changed meanwhile, reload it and try again")
+	ErrUnavailable    = errors.New("correspondence games are unavailable, try again later")
+)
+
+// Host reaches players connected to the live server
+type Host interface {
+	// Push sends a message to a player and reports whether they were
+	// connected to get it
+	Push(username string, msgType models.WSMessageType, payload interface{}) bool
+}
+
+// Manager runs correspondence games: games played a move at a time over
+// days, with no one needing to stay connected. Games live in the database
+// rather than the hub, so a player can have any number of them and a
+// restarted server loses nothing. A game starts as a challenge the
+// opponent accepts or declines. Once it is on, the player to move is
+// pushed the news if they are connected and it is queued for when they
+// connect if not, and whoever doesn't move in time forfeits.
+type Manager struct {
+	games         *repository.GameRepository
+	players       *repository.PlayerRepository
+	notifications *repository.NotificationRepository
+	host          Host
+	sweepInterval time.Duration
+
+	// Serializes changes to games on this instance; UpdateInProgress
+	// catches changes made elsewhere
+	mu sync.Mutex
+}
+
+// NewManager creates a correspondence game manager that looks for players
+// out of time every sweepInterval
+func NewManager(games *repository.GameRepository, players *repository.PlayerRepository, notifications *repository.NotificationRepository, host Host, sweepInterval time.Duration) *Manager {
+	return &Manager{games: games, players: players, notifications: notifications, host: host, sweepInterval: sweepInterval}
+}
+
+// Start forfeits overdue games every sweep interval, starting with any
+// that ran out while the server was down. With no interval, games are
+// only forfeited when someone tries to move in them.
+func (m *Manager) Start() {
+	go func() {
+		m.expireOverdue(context.Background())
+		if m.sweepInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(m.sweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			m.expireOverdue(context.Background())
+		}
+	}()
+}
+
+// Create challenges Player2 to a game with Player1, who moves first once
+// Player2 accepts. Player2 is told about the challenge.
+func (m *Manager) Create(ctx context.Context, s Settings) (*models.CorrespondenceGamePayload, error) {
+	if s.DaysPerMove == 0 {
+		s.DaysPerMove = DefaultDaysPerMove
+	}
+	if s.DaysPerMove < 1 || s.DaysPerMove > MaxDaysPerMove {
+		return nil, ErrInvalidDays
+	}
+	if s.Setup.Variant == "" {
+		s.Setup.Variant = game.VariantClassic
+	}
+	if !s.Setup.Variant.Valid() {
+		return nil, ErrInvalidVariant
+	}
+	if s.Player1 == s.Player2 {
+		return nil, ErrSamePlayer
+	}
+
+	p1, err := m.player(ctx, s.Player1)
+	if err != nil {
+		return nil, err
+	}
+	p2, err := m.player(ctx, s.Player2)
+	if err != nil {
+		return nil, err
+	}
+
+	record := newRecord(p1, p2, s, time.Now())
+	if err := m.games.Create(ctx, record); err != nil {
+		return nil, m.unavailable(err, record.ID, "Failed to store correspondence game")
+	}
+	record.Player1, record.Player2 = p1, p2
+
+	g, err := replay(record)
+	if err != nil {
+		return nil, m.unavailable(err, record.ID, "Failed to replay correspondence game")
+	}
+	v := view(record, g)
+	m.notify(ctx, p2.Username, models.WSTypeCorrespondenceChallenge, v)
+
+	log.Info().
+		Str("gameId", record.ID.String()).
+		Str("player1", p1.Username).
+		Str("player2", p2.Username).
+		Str("variant", s.Setup.Key()).
+		Int("daysPerMove", s.DaysPerMove).
+		Msg("Correspondence challenge sent")
+	return v, nil
+}
+
+// Accept starts a challenged game, with username the challenged player.
+// The challenger's clock starts now and they are told.
+func (m *Manager) Accept(ctx context.Context, id uuid.UUID, username string) (*models.CorrespondenceGamePayload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, g, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := accept(record, username, time.Now()); err != nil {
+		return nil, err
+	}
+	saved, err := m.games.Accept(ctx, record)
+	if err != nil {
+		return nil, m.unavailable(err, id, "Failed to store correspondence game")
+	}
+	if !saved {
+		return nil, ErrConflict
+	}
+
+	v := view(record, g)
+	m.notify(ctx, g.Player1.Username, models.WSTypeCorrespondenceStarted, v)
+	log.Info().Str("gameId", id.String()).Str("username", username).Msg("Correspondence game started")
+	return v, nil
+}
+
+// Decline turns down a challenge sent to username, or withdraws one they
+// sent. The challenge is removed without counting for either player, and
+// the other player is told.
+func (m *Manager) Decline(ctx context.Context, id uuid.UUID, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, g, err := m.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := answerable(record, username); err != nil {
+		return err
+	}
+	deleted, err := m.games.DeletePending(ctx, id)
+	if err != nil {
+		return m.unavailable(err, id, "Failed to delete correspondence challenge")
+	}
+	if !deleted {
+		return ErrConflict
+	}
+
+	other := g.Player1.Username
+	if seat(record, username) == game.Player1 {
+		other = g.Player2.Username
+	}
+	m.notify(ctx, other, models.WSTypeCorrespondenceDeclined, view(record, g))
+	log.Info().Str("gameId", id.String()).Str("username", username).Msg("Correspondence challenge declined")
+	return nil
+}
+
+// Get returns a correspondence game, over or not
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.CorrespondenceGamePayload, error) {
+	record, g, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return view(record, g), nil
+}
+
+// List returns the correspondence games a player is still playing, the
+// one whose move has the least time left first, followed by the challenges
+// they sent or were sent that are waiting for an answer
+func (m *Manager) List(ctx context.Context, username string) ([]*models.CorrespondenceGamePayload, error) {
+	p, err := m.player(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	records, err := m.games.ListInProgress(ctx, p.ID)
+	if err != nil {
+		return nil, m.unavailable(err, uuid.Nil, "Failed to list correspondence games")
+	}
+
+	views := make([]*models.CorrespondenceGamePayload, 0, len(records))
+	for i := range records {
+		g, err := replay(&records[i])
+		if err != nil {
+			log.Error().Err(err).Str("gameId", records[i].ID.String()).Msg("Failed to replay correspondence game")
+			continue
+		}
+		views = append(views, view(&records[i], g))
+	}
+	return views, nil
+}
+
+// Move plays username's move. Their opponent hears about it, unless the
+// rules give the same player another move.
+func (m *Manager) Move(ctx context.Context, id uuid.UUID, username string, a game.Action) (*models.CorrespondenceGamePayload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, g, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if overdue(record, now) {
+		m.expire(ctx, record, g, now)
+		return nil, ErrGameOver
+	}
+
+	deadline := record.MoveDeadline
+	if err := play(record, g, username, a, now); err != nil {
+		return nil, err
+	}
+	if err := m.save(ctx, record, deadline); err != nil {
+		return nil, err
+	}
+
+	v := view(record, g)
+	switch {
+	case g.IsGameOver():
+		m.finish(ctx, record, g, v)
+	case g.GetCurrentPlayer() != seat(record, username):
+		next := g.GetPlayerInfo(g.GetCurrentPlayer()).Username
+		m.notify(ctx, next, models.WSTypeCorrespondenceTurn, v)
+	}
+	return v, nil
+}
+
+// Resign ends a game with username losing it
+func (m *Manager) Resign(ctx context.Context, id uuid.UUID, username string) (*models.CorrespondenceGamePayload, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, g, err := m.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Pending {
+		return nil, ErrNotAccepted
+	}
+	if !record.InProgress() {
+		return nil, ErrGameOver
+	}
+	player := seat(record, username)
+	if player == game.Empty {
+		return nil, ErrNotPlayer
+	}
+
+	deadline := record.MoveDeadline
+	if err := forfeit(record, g, player, time.Now()); err != nil {
+		return nil, m.unavailable(err, id, "Failed to store correspondence game")
+	}
+	if err := m.save(ctx, record, deadline); err != nil {
+		return nil, err
+	}
+
+	v := view(record, g)
+	m.finish(ctx, record, g, v)
+	log.Info().Str("gameId", id.String()).Str("username", username).Msg("Correspondence game resigned")
+	return v, nil
+}
+
+// Deliver pushes a player who just connected the notifications queued
+// while they were away
+func (m *Manager) Deliver(username string) {
+	ctx := context.Background()
+	queued, err := m.notifications.ListByUsername(ctx, username)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to load notifications")
+		return
+	}
+
+	delivered := make([]uuid.UUID, 0, len(queued))
+	for _, n := range queued {
+		if !m.host.Push(username, models.WSMessageType(n.Type), json.RawMessage(n.Payload)) {
+			break
+		}
+		delivered = append(delivered, n.ID)
+	}
+	if err := m.notifications.Delete(ctx, delivered); err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to delete delivered notifications")
+	}
+}
+
+// expireOverdue forfeits every game whose player to move is out of time
+func (m *Manager) expireOverdue(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	records, err := m.games.ListOverdue(ctx, now)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list overdue correspondence games")
+		return
+	}
+	for i := range records {
+		g, err := replay(&records[i])
+		if err != nil {
+			log.Error().Err(err).Str("gameId", records[i].ID.String()).Msg("Failed to replay correspondence game")
+			continue
+		}
+		m.expire(ctx, &records[i], g, now)
+	}
+}
+
+// expire forfeits a game whose player to move ran out of time. Caller
+// must hold m.mu.
+func (m *Manager) expire(ctx context.Context, record *models.GameRecord, g *game.Game, now time.Time) {
+	loser := g.GetCurrentPlayer()
+	deadline := record.MoveDeadline
+	if err := forfeit(record, g, loser, now); err != nil {
+		log.Error().Err(err).Str("gameId", record.ID.String()).Msg("Failed to forfeit correspondence game")
+		return
+	}
+	if err := m.save(ctx, record, deadline); err != nil {
+		return
+	}
+
+	m.finish(ctx, record, g, view(record, g))
+	log.Info().
+		Str("gameId", record.ID.String()).
+		Str("username", g.GetPlayerInfo(loser).Username).
+		Msg("Correspondence game forfeited (out of time)")
+}
+
+// finish counts a finished game in both players' stats and tells them
+// how it ended. Correspondence games don't change ratings.
+func (m *Manager) finish(ctx context.Context, record *models.GameRecord, g *game.Game, v *models.CorrespondenceGamePayload) {
+	var err error
+	switch g.Winner {
+	case game.Player1:
+		err = m.score(ctx, record.Player1ID, *record.Player2ID)
+	case game.Player2:
+		err = m.score(ctx, *record.Player2ID, record.Player1ID)
+	default:
+		if err = m.players.IncrementDraws(ctx, record.Player1ID); err == nil {
+			err = m.players.IncrementDraws(ctx, *record.Player2ID)
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Str("gameId", record.ID.String()).Msg("Failed to update player stats")
+	}
+
+	m.notify(ctx, g.Player1.Username, models.WSTypeCorrespondenceOver, v)
+	m.notify(ctx, g.Player2.Username, models.WSTypeCorrespondenceOver, v)
+
+	log.Info().Str("gameId", record.ID.String()).Str("result", v.Result).Str("winner", v.Winner).Msg("Correspondence game ended")
+}
+
+// score counts a win for winner and a loss for loser
+func (m *Manager) score(ctx context.Context, winner, loser uuid.UUID) error {
+	if err := m.players.IncrementWins(ctx, winner); err != nil {
+		return err
+	}
+	return m.players.IncrementLosses(ctx, loser)
+}
+
+// notify pushes a message to a connected player or queues it for when
+// they next connect
+func (m *Manager) notify(ctx context.Context, username string, msgType models.WSMessageType, payload interface{}) {
+	if m.host.Push(username, msgType, payload) {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to encode notification")
+		return
+	}
+	n := &models.Notification{Username: username, Type: string(msgType), Payload: string(data)}
+	if err := m.notifications.Create(ctx, n); err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to queue notification")
+	}
+}
+
+// load reads a correspondence game and replays it
+func (m *Manager) load(ctx context.Context, id uuid.UUID) (*models.GameRecord, *game.Game, error) {
+	record, err := m.games.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, m.unavailable(err, id, "Failed to load correspondence game")
+	}
+	if record == nil || record.DaysPerMove == 0 {
+		return nil, nil, ErrNotFound
+	}
+	g, err := replay(record)
+	if err != nil {
+		return nil, nil, m.unavailable(err, id, "Failed to replay correspondence game")
+	}
+	return record, g, nil
+}
+
+// save stores a game changed from the position it had when it was read
+// with deadline
+func (m *Manager) save(ctx context.Context, record *models.GameRecord, deadline *time.Time) error {
+	saved, err := m.games.UpdateInProgress(ctx, record, *deadline)
+	if err != nil {
+		return m.unavailable(err, record.ID, "Failed to store correspondence game")
+	}
+	if !saved {
+		return ErrConflict
+	}
+	return nil
+}
+
+// player looks up an existing player by username
+func (m *Manager) player(ctx context.Context, username string) (*models.Player, error) {
+	p, err := m.players.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, m.unavailable(err, uuid.Nil, "Failed to look up player")
+	}
+	if p == nil {
+		return nil, ErrPlayerNotFound
+	}
+	return p, nil
+}
+
+// unavailable logs an unexpected error and returns ErrUnavailable for it
+func (m *Manager) unavailable(err error, id uuid.UUID, msg string) error {
+	event := log.Error().Err(err)
+	if id != uuid.Nil {
+		event = event.Str("gameId", id.String())
+	}
+	event.Msg(msg)
+	return ErrUnavailable
+}
@@ -1,5 +1,7 @@
 package game
 
+import "fmt"
+
 // Board dimensions per SRS FR-GM-001
 const (
 	Columns = 7
@@ -129,3 +131,117 @@ func (b *Board) GetDropRow(col int) int {
 	}
 	return -1
 }
+
+// WinsAt reports whether the disc at (row, col) completes four in a row
+// for player
+func (b *Board) WinsAt(row, col int, player Cell) bool {
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, dir := range directions {
+		count := 1
+		for _, sign := range []int{1, -1} {
+			for i := 1; i < 4; i++ {
+				r, c := row+sign*dir[0]*i, col+sign*dir[1]*i
+				if b.GetCell(r, c) != player || player == Empty {
+					break
+				}
+				count++
+			}
+		}
+		if count >= 4 {
+			return true
+		}
+	}
+	return false
+}
+
+// CanPop reports whether player may pop the bottom disc of the column
+// (PopOut): it has to be one of their own
+func (b *Board) CanPop(col int, player Cell) bool {
+	if col < 0 || col >= Columns || player == Empty {
+		return false
+	}
+	return b[Rows-1][col] == player
+}
+
+// PopDisc removes the bottom disc of the column and lets the discs above
+// it fall one row. Returns the disc removed, Empty if there was none.
+func (b *Board) PopDisc(col int) Cell {
+	if col < 0 || col >= Columns {
+		return Empty
+	}
+	popped := b[Rows-1][col]
+	for row := Rows - 1; row > 0; row-- {
+		b[row][col] = b[row-1][col]
+	}
+	b[0][col] = Empty
+	return popped
+}
+
+// PushDisc slides a disc in at the bottom of the column, lifting the discs
+// above it one row; it undoes PopDisc. Returns false if the column is full.
+func (b *Board) PushDisc(col int, player Cell) bool {
+	if b.IsColumnFull(col) {
+		return false
+	}
+	for row := 0; row < Rows-1; row++ {
+		b[row][col] = b[row+1][col]
+	}
+	b[Rows-1][col] = player
+	return true
+}
+
+// CanPopAny reports whether player has a disc at the bottom of any column
+func (b *Board) CanPopAny(player Cell) bool {
+	for c := 0; c < Columns; c++ {
+		if b.CanPop(c, player) {
+			return true
+		}
+	}
+	return false
+}
+
+// PopWinner returns who connects four after popper popped the column:
+// every disc in it moved, so any new line runs through it. If both
+// players connect, the player who popped wins. Empty if neither does.
+func (b *Board) PopWinner(col int, popper Cell) Cell {
+	opponent := Player1
+	if popper == Player1 {
+		opponent = Player2
+	}
+	for _, player := range []Cell{popper, opponent} {
+		for row := 0; row < Rows; row++ {
+			if b[row][col] == player && b.WinsAt(row, col, player) {
+				return player
+			}
+		}
+	}
+	return Empty
+}
+
+// Encode returns the board as a string of Rows*Columns digits, top row
+// first, for storage and lookup
+func (b *Board) Encode() string {
+	buf := make([]byte, 0, Rows*Columns)
+	for r := 0; r < Rows; r++ {
+		for c := 0; c < Columns; c++ {
+			buf = append(buf, byte('0'+b[r][c]))
+		}
+	}
+	return string(buf)
+}
+
+// DecodeBoard parses a board produced by Encode
+func DecodeBoard(s string) (*Board, error) {
+	if len(s) != Rows*Columns {
+		return nil, fmt.Errorf("board must be %d cells, got %d", Rows*Columns, len(s))
+	}
+	board := NewBoard()
+	for i := 0; i < len(s); i++ {
+		cell := Cell(s[i] - '0')
+		if cell != Empty && cell != Player1 && cell != Player2 {
+			return nil, fmt.Errorf("invalid cell %q at %d", s[i], i)
+		}
+		board[i/Columns][i%Columns] = cell
+	}
+	return board, nil
+}
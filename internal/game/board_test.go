@@ -129,3 +129,43 @@ func TestToSlice(t *testing.T) {
 		t.Error("Player2 disc not in slice")
 	}
 }
+
+func TestWinsAt(t *testing.T) {
+	board := NewBoard()
+	for c := 0; c < 3; c++ {
+		board.DropDisc(c, Player1)
+	}
+	if board.WinsAt(5, 2, Player1) {
+		t.Error("Three in a row reported as a win")
+	}
+	row := board.DropDisc(3, Player1)
+	if !board.WinsAt(row, 3, Player1) {
+		t.Error("Horizontal four not detected")
+	}
+	if board.WinsAt(row, 3, Player2) {
+		t.Error("Win reported for the wrong player")
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	board := NewBoard()
+	board.DropDisc(3, Player1)
+	board.DropDisc(3, Player2)
+
+	encoded := board.Encode()
+	if len(encoded) != Rows*Columns {
+		t.Fatalf("Expected %d characters, got %d", Rows*Columns, len(encoded))
+	}
+
+	decoded, err := DecodeBoard(encoded)
+	if err != nil {
+		t.Fatalf("DecodeBoard failed: %v", err)
+	}
+	if *decoded != *board {
+		t.Error("Decoded board doesn't match original")
+	}
+
+	if _, err := DecodeBoard("123"); err == nil {
+		t.Error("Expected error for short board")
+	}
+}
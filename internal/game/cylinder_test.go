@@ -0,0 +1,180 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// cylinderLines returns every line of n cells on a rows x columns
+// cylinder, along rows and both diagonals, with columns taken modulo
+// columns, and whether it runs across the edge
+func cylinderLines(rows, columns, n int) (lines [][][2]int, wraps []bool) {
+	for _, dir := range [][2]int{{0, 1}, {1, 1}, {1, -1}} {
+		for row := 0; row+dir[0]*(n-1) < rows; row++ {
+			for col := 0; col < columns; col++ {
+				var line [][2]int
+				wrapped := false
+				for i := 0; i < n; i++ {
+					c := col + dir[1]*i
+					if c < 0 || c >= columns {
+						wrapped = true
+					}
+					line = append(line, [2]int{row + dir[0]*i, (c%columns + columns) % columns})
+				}
+				lines = append(lines, line)
+				wraps = append(wraps, wrapped)
+			}
+		}
+	}
+	return lines, wraps
+}
+
+func sortedCells(cells [][2]int) string {
+	sorted := append([][2]int(nil), cells...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i][0] != sorted[j][0] {
+			return sorted[i][0] < sorted[j][0]
+		}
+		return sorted[i][1] < sorted[j][1]
+	})
+	return fmt.Sprint(sorted)
+}
+
+func TestCylinderCheckWinEveryLine(t *testing.T) {
+	for _, size := range []struct{ rows, columns, n int }{
+		{Rows, Columns, 4},
+		{FiveInARowRows, FiveInARowColumns, 5},
+	} {
+		lines, wraps := cylinderLines(size.rows, size.columns, size.n)
+		wrapping := 0
+		for i, line := range lines {
+			if wraps[i] {
+				wrapping++
+			}
+			grid := NewGrid(size.rows, size.columns)
+			for _, cell := range line {
+				grid.SetCell(cell[0], cell[1], Player1)
+			}
+
+			// Whichever disc of the line was played last finds all of it
+			for _, last := range line {
+				won, cells := checkWin(grid, last[0], last[1], Player1, size.n, true)
+				if !won {
+					t.Fatalf("%dx%d: line %v not found from %v", size.columns, size.rows, line, last)
+				}
+				if got, want := sortedCells(cells), sortedCells(line); got != want {
+					t.Fatalf("%dx%d: line %v from %v reported as %v", size.columns, size.rows, line, last, cells)
+				}
+
+				won, _ = checkWin(grid, last[0], last[1], Player1, size.n, false)
+				if won == wraps[i] {
+					t.Fatalf("%dx%d: line %v wins on a flat board: %v, want %v", size.columns, size.rows, line, won, !wraps[i])
+				}
+			}
+		}
+		if wrapping == 0 {
+			t.Fatalf("%dx%d: no wrapping lines tested", size.columns, size.rows)
+		}
+	}
+}
+
+func TestCylinderLineNeverRepeatsCells(t *testing.T) {
+	// A full row is longer than a line, so counting both ways round must
+	// stop before it comes back to where it started
+	grid := NewGrid(Rows, Columns)
+	for col := 0; col < Columns; col++ {
+		grid.SetCell(Rows-1, col, Player1)
+	}
+	for col := 0; col < Columns; col++ {
+		cells := countLine(grid, Rows-1, col, 0, 1, Player1, 4, true)
+		if len(cells) != Columns {
+			t.Errorf("column %d: %d cells, want %d", col, len(cells), Columns)
+		}
+		seen := map[[2]int]bool{}
+		for _, cell := range cells {
+			if seen[cell] {
+				t.Errorf("column %d: %v counted twice", col, cell)
+			}
+			seen[cell] = true
+		}
+	}
+}
+
+func TestCylinderGameWinningCells(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves []int // alternating, Player1 first
+		cells string
+	}{
+		{
+			name:  "row across the edge",
+			moves: []int{5, 5, 6, 6, 0, 0, 1},
+			cells: "[[5 0] [5 1] [5 5] [5 6]]",
+		},
+		{
+			// Player1 climbs from column 5 through 6 to 0 and 1
+			name:  "rising diagonal across the edge",
+			moves: []int{5, 6, 6, 0, 0, 1, 0, 1, 1, 3, 1},
+			cells: "[[2 1] [3 0] [4 6] [5 5]]",
+		},
+		{
+			// Player1 climbs leftwards from column 1 through 0 to 6 and 5
+			name:  "falling diagonal across the edge",
+			moves: []int{1, 0, 0, 6, 6, 5, 6, 5, 5, 3, 5},
+			cells: "[[2 5] [3 6] [4 0] [5 1]]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewSetupGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"}, Setup{Variant: VariantClassic, Cylinder: true})
+			flat := NewGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"})
+			for i, col := range tt.moves {
+				player := Player1 + Cell(i%2)
+				if _, err := g.MakeMove(player, col); err != "" {
+					t.Fatalf("move %d: %s", i, err)
+				}
+				flat.MakeMove(player, col)
+				if g.IsGameOver() != (i == len(tt.moves)-1) {
+					t.Fatalf("move %d: game over %v", i, g.IsGameOver())
+				}
+			}
+			if g.Winner != Player1 {
+				t.Fatalf("Winner %d, want Player1", g.Winner)
+			}
+			if got := sortedCells(g.WinningCells); got != tt.cells {
+				t.Errorf("WinningCells = %s, want %s", got, tt.cells)
+			}
+			if flat.IsGameOver() {
+				t.Error("The same moves shouldn't win on a flat board")
+			}
+		})
+	}
+}
+
+func TestCylinderSurvivesUndoAndEncode(t *testing.T) {
+	g := NewSetupGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"}, Setup{Variant: VariantPopOut, Cylinder: true})
+	for _, col := range []int{5, 5, 6, 6, 0, 0} {
+		g.MakeMove(g.CurrentTurn, col)
+	}
+	g.MakeMove(Player1, 3)
+	g.UndoTurn(Player1)
+	g.MakeMove(Player1, 1)
+	if g.Winner != Player1 {
+		t.Fatal("The replayed game should still be on a cylinder")
+	}
+
+	state, _ := g.SnapshotState()
+	data, err := PopOut.Encode(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := PopOut.Decode(data)
+	if err != nil || !decoded.Cylinder {
+		t.Errorf("Decoded state lost the cylinder: %v", err)
+	}
+	if g.Setup() != (Setup{Variant: VariantPopOut, Cylinder: true}) {
+		t.Errorf("Setup = %+v", g.Setup())
+	}
+}
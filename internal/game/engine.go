@@ -25,6 +25,7 @@ const (
 	ResultPlayer2Win GameResult = "player2"
 	ResultDraw       GameResult = "draw"
 	ResultForfeit    GameResult = "forfeit"
+	ResultDrawAgreed GameResult = "draw_agreed"
 )
 
 // PlayerInfo holds information about a player in a game
@@ -40,9 +41,19 @@ type PlayerInfo struct {
 type Move struct {
 	Player    Cell      `json:"player"`
 	Column    int       `json:"column"`
-	Row       int       `json:"row"`
+	Row       int       `json:"row"` // where the disc landed, or Rows-1 for a pop
 	MoveNum   int       `json:"move_num"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Set for pops only, so drops read the same as in classic games
+	Type MoveType `json:"type,omitempty"`
+	// Power Up's special discs
+	Disc Disc `json:"disc,omitempty"`
+}
+
+// Action returns the move as its ruleset played it
+func (m Move) Action() Action {
+	return Action{Type: m.Type, Column: m.Column, Disc: m.Disc}
 }
 
 // Game represents an active game session
@@ -50,7 +61,10 @@ type Game struct {
 	ID           uuid.UUID
 	Player1      *PlayerInfo
 	Player2      *PlayerInfo
-	Board        *Board
+	Board        *Grid // the state's grid
+	Variant      Variant
+	Rules        Ruleset
+	Cylinder     bool // the board's left and right edges meet
 	CurrentTurn  Cell
 	Moves        []Move
 	Status       GameStatus
@@ -60,29 +74,142 @@ type Game struct {
 	StartedAt    time.Time
 	EndedAt      *time.Time
 
+	// Bumped by every move and takeback, so a position can be told apart
+	// from an earlier one with the same number of moves
+	version int
+
+	// The position the rules play on, and the one the game started from
+	// for replaying moves after a takeback
+	state *State
+	start *State
+
 	mu sync.RWMutex
 }
 
-// NewGame creates a new game session
+// NewGame creates a new game session with the classic rules
 func NewGame(player1, player2 *PlayerInfo) *Game {
+	return NewVariantGame(player1, player2, VariantClassic)
+}
+
+// NewVariantGame creates a new game session played by the variant's
+// rules, or the classic ones if the variant is unknown
+func NewVariantGame(player1, player2 *PlayerInfo, variant Variant) *Game {
+	return NewSetupGame(player1, player2, Setup{Variant: variant})
+}
+
+// NewSetupGame creates a new game session played by the setup's rules,
+// on a cylinder if it asks for one
+func NewSetupGame(player1, player2 *PlayerInfo, setup Setup) *Game {
+	rules, ok := RulesFor(setup.Variant)
+	if !ok {
+		rules = Classic
+	}
+	state := rules.NewState()
+	state.Cylinder = setup.Cylinder
 	return &Game{
 		ID:          uuid.New(),
 		Player1:     player1,
 		Player2:     player2,
-		Board:       NewBoard(),
-		CurrentTurn: Player1, // Player 1 always goes first
+		Board:       state.Grid,
+		Variant:     rules.Name(),
+		Rules:       rules,
+		Cylinder:    setup.Cylinder,
+		CurrentTurn: state.Turn, // Player 1 always goes first
 		Moves:       make([]Move, 0),
 		Status:      GameStatusInProgress,
 		StartedAt:   time.Now(),
+		state:       state,
+		start:       state.Clone(),
 	}
 }
 
+// NewGameFromBoard creates a game that starts from an existing position
+// (e.g. a puzzle) with the given player to move. No move history is kept
+// for the discs already on the board.
+func NewGameFromBoard(player1, player2 *PlayerInfo, board *Board, turn Cell) *Game {
+	g := NewGame(player1, player2)
+	g.state.Grid = GridFromBoard(board)
+	g.state.Turn = turn
+	g.Board = g.state.Grid
+	g.CurrentTurn = turn
+	g.start = g.state.Clone()
+	return g
+}
+
+// Setup returns what the game is played by
+func (g *Game) Setup() Setup {
+	return Setup{Variant: g.Variant, Cylinder: g.Cylinder}
+}
+
 // MakeMove attempts to make a move in the specified column
 // Returns the row where disc landed, or error message
 func (g *Game) MakeMove(player Cell, col int) (int, string) {
+	return g.Play(player, Action{Column: col})
+}
+
+// MakeMoveAt makes a move chosen in the position Snapshot returned with
+// version. It is refused if a move was made or taken back since, so a move
+// computed for an old position is never played.
+func (g *Game) MakeMoveAt(player Cell, col, version int) (int, string) {
+	return g.PlayAt(player, Action{Column: col}, version)
+}
+
+// MakePop removes one of player's discs from the bottom of col and lets
+// the column fall, in games whose rules allow it. Returns the row the disc
+// was taken from, or an error message.
+func (g *Game) MakePop(player Cell, col int) (int, string) {
+	return g.Play(player, Action{Type: MovePop, Column: col})
+}
+
+// MakePopAt is MakePop for a move chosen in the position Snapshot returned
+// with version; see MakeMoveAt
+func (g *Game) MakePopAt(player Cell, col, version int) (int, string) {
+	return g.PlayAt(player, Action{Type: MovePop, Column: col}, version)
+}
+
+// Play makes any move the game's rules allow. Returns the row the disc
+// landed on or was taken from, or an error message.
+func (g *Game) Play(player Cell, a Action) (int, string) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
+	return g.play(player, a)
+}
 
+// PlayAt is Play for a move chosen in the position SnapshotState returned
+// with version; see MakeMoveAt
+func (g *Game) PlayAt(player Cell, a Action, version int) (int, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.version != version {
+		return -1, "position has changed"
+	}
+	return g.play(player, a)
+}
+
+// Snapshot returns a copy of the board and its version, for MakeMoveAt.
+// The board is nil if the game isn't played on the classic 7x6.
+func (g *Game) Snapshot() (*Board, int) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.state.Grid.Board(), g.version
+}
+
+// SnapshotState returns a copy of the position and its version, for PlayAt
+func (g *Game) SnapshotState() (*State, int) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.state.Clone(), g.version
+}
+
+// Captured returns how many discs player has set aside in Pop 10
+func (g *Game) Captured(player Cell) int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.state.Captured[player]
+}
+
+// play validates and plays a move. Caller must hold g.mu.
+func (g *Game) play(player Cell, a Action) (int, string) {
 	// Validate game status
 	if g.Status != GameStatusInProgress {
 		return -1, "game is not in progress"
@@ -93,110 +220,61 @@ func (g *Game) MakeMove(player Cell, col int) (int, string) {
 		return -1, "not your turn"
 	}
 
-	// Validate column
-	if col < 0 || col >= Columns {
-		return -1, "invalid column"
-	}
-
-	// Drop disc
-	row := g.Board.DropDisc(col, player)
-	if row == -1 {
-		return -1, "column is full"
+	row, err := g.Rules.Apply(g.state, a)
+	if err != nil {
+		return -1, err.Error()
 	}
 
 	// Record move
 	move := Move{
 		Player:    player,
-		Column:    col,
+		Column:    a.Column,
 		Row:       row,
 		MoveNum:   len(g.Moves) + 1,
 		Timestamp: time.Now(),
+		Disc:      a.Disc,
 	}
-	g.Moves = append(g.Moves, move)
-
-	// Check for win
-	if won, cells := g.checkWin(row, col, player); won {
-		g.Status = GameStatusFinished
-		g.Winner = player
-		g.WinningCells = cells
-		now := time.Now()
-		g.EndedAt = &now
-		if player == Player1 {
-			g.Result = ResultPlayer1Win
-		} else {
-			g.Result = ResultPlayer2Win
-		}
-		return row, ""
-	}
-
-	// Check for draw
-	if g.Board.IsBoardFull() {
-		g.Status = GameStatusFinished
-		g.Result = ResultDraw
-		now := time.Now()
-		g.EndedAt = &now
-		return row, ""
-	}
-
-	// Switch turn
-	if g.CurrentTurn == Player1 {
-		g.CurrentTurn = Player2
-	} else {
-		g.CurrentTurn = Player1
+	if a.Type == MovePop {
+		move.Type = MovePop
 	}
+	g.Moves = append(g.Moves, move)
+	g.version++
 
+	g.settle()
 	return row, ""
 }
 
-// checkWin checks if the last move at (row, col) creates a win
-// Returns true and the winning cells if a win is found
-func (g *Game) checkWin(row, col int, player Cell) (bool, [][2]int) {
-	directions := [][2]int{
-		{0, 1},  // Horizontal
-		{1, 0},  // Vertical
-		{1, 1},  // Diagonal (down-right)
-		{1, -1}, // Diagonal (down-left)
+// settle catches the game up with its state after a move: whose turn it
+// is and, once the rules say the game is over, its result. Caller must
+// hold g.mu.
+func (g *Game) settle() {
+	g.CurrentTurn = g.state.Turn
+	if !g.Rules.Terminal(g.state) {
+		return
 	}
-
-	for _, dir := range directions {
-		cells := g.countLine(row, col, dir[0], dir[1], player)
-		if len(cells) >= 4 {
-			return true, cells
-		}
+	if winner, cells := g.Rules.Winner(g.state); winner != Empty {
+		g.win(winner, cells)
+		return
 	}
-
-	return false, nil
+	g.Status = GameStatusFinished
+	g.Result = ResultDraw
+	now := time.Now()
+	g.EndedAt = &now
 }
 
-// countLine counts connected cells in both directions along a line
-func (g *Game) countLine(row, col, dRow, dCol int, player Cell) [][2]int {
-	cells := [][2]int{{row, col}}
-
-	// Count in positive direction
-	for i := 1; i < 4; i++ {
-		r, c := row+dRow*i, col+dCol*i
-		if r < 0 || r >= Rows || c < 0 || c >= Columns {
-			break
-		}
-		if g.Board.GetCell(r, c) != player {
-			break
-		}
-		cells = append(cells, [2]int{r, c})
-	}
-
-	// Count in negative direction
-	for i := 1; i < 4; i++ {
-		r, c := row-dRow*i, col-dCol*i
-		if r < 0 || r >= Rows || c < 0 || c >= Columns {
-			break
-		}
-		if g.Board.GetCell(r, c) != player {
-			break
-		}
-		cells = append(cells, [2]int{r, c})
+// win ends the game with player connecting the given cells. Caller must
+// hold g.mu.
+func (g *Game) win(player Cell, cells [][2]int) {
+	g.Status = GameStatusFinished
+	g.Winner = player
+	g.WinningCells = cells
+	now := time.Now()
+	g.EndedAt = &now
+	if player == Player1 {
+		g.Result = ResultPlayer1Win
+	} else {
+		g.Result = ResultPlayer2Win
 	}
-
-	return cells
 }
 
 // IsGameOver returns true if the game has ended
@@ -206,6 +284,20 @@ func (g *Game) IsGameOver() bool {
 	return g.Status == GameStatusFinished
 }
 
+// GetStatus returns the current game status
+func (g *Game) GetStatus() GameStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Status
+}
+
+// MoveCount returns the number of moves played so far
+func (g *Game) MoveCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.Moves)
+}
+
 // GetCurrentPlayer returns the player whose turn it is
 func (g *Game) GetCurrentPlayer() Cell {
 	g.mu.RLock()
@@ -246,6 +338,114 @@ func (g *Game) Forfeit(loser Cell) {
 	}
 }
 
+// SetResult ends the game with an externally decided result (e.g. by an admin).
+// Only player1, player2 and draw results are accepted; returns false if the
+// result is not one of those or the game has already finished.
+func (g *Game) SetResult(result GameResult) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status == GameStatusFinished {
+		return false
+	}
+
+	switch result {
+	case ResultPlayer1Win:
+		g.Winner = Player1
+	case ResultPlayer2Win:
+		g.Winner = Player2
+	case ResultDraw:
+		g.Winner = Empty
+	default:
+		return false
+	}
+
+	g.Status = GameStatusFinished
+	g.Result = result
+	now := time.Now()
+	g.EndedAt = &now
+	return true
+}
+
+// AgreeDraw ends the game as a draw both players agreed to.
+// Returns false if the game has already finished.
+func (g *Game) AgreeDraw() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status == GameStatusFinished {
+		return false
+	}
+
+	g.Status = GameStatusFinished
+	g.Result = ResultDrawAgreed
+	g.Winner = Empty
+	now := time.Now()
+	g.EndedAt = &now
+	return true
+}
+
+// UndoTurn takes back player's last turn, every move of it along with any
+// the opponent made since, so player is to move where their turn began.
+// Some rules give a player several moves in a turn (Power Up's wall and
+// double disc, Pop 10's pops). Returns how many moves were taken back, and
+// false if player has no move to take back or the game ended some other
+// way (forfeit, agreement).
+func (g *Game) UndoTurn(player Cell) (int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.undoable() {
+		return 0, false
+	}
+	n := len(g.Moves)
+	for n > 0 && g.Moves[n-1].Player != player {
+		n--
+	}
+	if n == 0 {
+		return 0, false
+	}
+	for n > 0 && g.Moves[n-1].Player == player {
+		n--
+	}
+	undone := len(g.Moves) - n
+	g.rewind(n)
+	return undone, true
+}
+
+// undoable reports whether there is a move a takeback could undo. Caller
+// must hold g.mu.
+func (g *Game) undoable() bool {
+	if len(g.Moves) == 0 {
+		return false
+	}
+	return g.Status != GameStatusFinished || g.Result == ResultPlayer1Win || g.Result == ResultPlayer2Win || g.Result == ResultDraw
+}
+
+// rewind takes the game back to the position after its first n moves.
+// Caller must hold g.mu.
+func (g *Game) rewind(n int) {
+	g.Moves = g.Moves[:n]
+	g.version++
+
+	// Rules like Power Up's anvil can't be played backwards, so replay
+	// the game from the start up to there
+	g.state = g.start.Clone()
+	for _, m := range g.Moves {
+		g.Rules.Apply(g.state, m.Action())
+	}
+	g.Board = g.state.Grid
+	g.CurrentTurn = g.state.Turn
+
+	if g.Status == GameStatusFinished {
+		g.Status = GameStatusInProgress
+		g.Result = ""
+		g.Winner = Empty
+		g.WinningCells = nil
+		g.EndedAt = nil
+	}
+}
+
 // SetDisconnected marks a player as disconnected
 func (g *Game) SetDisconnected(player Cell) {
 	g.mu.Lock()
@@ -187,3 +187,140 @@ func TestForfeit(t *testing.T) {
 		t.Error("Result should be forfeit")
 	}
 }
+
+func TestSetResult(t *testing.T) {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	game := NewGame(p1, p2)
+
+	if game.SetResult(ResultForfeit) {
+		t.Error("Forfeit should not be accepted as an imposed result")
+	}
+
+	if !game.SetResult(ResultPlayer2Win) {
+		t.Fatal("SetResult should end an in-progress game")
+	}
+	if !game.IsGameOver() || game.Winner != Player2 {
+		t.Errorf("Expected Player2 win, got winner=%d status=%s", game.Winner, game.Status)
+	}
+
+	if game.SetResult(ResultDraw) {
+		t.Error("SetResult should not change a finished game")
+	}
+}
+
+func TestAgreeDraw(t *testing.T) {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	game := NewGame(p1, p2)
+
+	game.MakeMove(Player1, 3)
+	if !game.AgreeDraw() {
+		t.Fatal("AgreeDraw should end an in-progress game")
+	}
+	if game.Result != ResultDrawAgreed || game.Winner != Empty {
+		t.Errorf("Expected agreed draw, got result=%s winner=%d", game.Result, game.Winner)
+	}
+	if game.AgreeDraw() {
+		t.Error("AgreeDraw should not change a finished game")
+	}
+}
+
+func TestUndoTurn(t *testing.T) {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	game := NewGame(p1, p2)
+
+	if _, ok := game.UndoTurn(Player1); ok {
+		t.Error("UndoTurn should fail with no moves")
+	}
+
+	game.MakeMove(Player1, 3)
+	game.MakeMove(Player2, 4)
+
+	n, ok := game.UndoTurn(Player2)
+	if !ok || n != 1 {
+		t.Fatalf("UndoTurn(Player2) = %d, %v; want 1 move undone", n, ok)
+	}
+	if game.Board.GetCell(Rows-1, 4) != Empty {
+		t.Error("Undone disc should be removed from the board")
+	}
+	if game.CurrentTurn != Player2 {
+		t.Error("Turn should return to the player whose move was undone")
+	}
+	if len(game.Moves) != 1 {
+		t.Errorf("Expected 1 move left, got %d", len(game.Moves))
+	}
+
+	// Player1's turn comes with Player2's reply after it
+	game.MakeMove(Player2, 4)
+	if n, ok := game.UndoTurn(Player1); !ok || n != 2 {
+		t.Fatalf("UndoTurn(Player1) = %d, %v; want the move and the reply", n, ok)
+	}
+	if len(game.Moves) != 0 || game.CurrentTurn != Player1 || game.Board.GetCell(Rows-1, 3) != Empty {
+		t.Errorf("Player1 should be back to an empty board: moves %d, turn %d", len(game.Moves), game.CurrentTurn)
+	}
+}
+
+func TestUndoWinningMove(t *testing.T) {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	game := NewGame(p1, p2)
+
+	// Player1 wins vertically in column 0
+	for i := 0; i < 3; i++ {
+		game.MakeMove(Player1, 0)
+		game.MakeMove(Player2, 1)
+	}
+	game.MakeMove(Player1, 0)
+	if !game.IsGameOver() {
+		t.Fatal("Expected Player1 to win")
+	}
+
+	if _, ok := game.UndoTurn(Player1); !ok {
+		t.Fatal("UndoTurn should reopen a game ended by a win")
+	}
+	if game.Status != GameStatusInProgress || game.Result != "" || game.Winner != Empty || game.EndedAt != nil {
+		t.Errorf("Game not restored: status=%s result=%s winner=%d", game.Status, game.Result, game.Winner)
+	}
+	if game.CurrentTurn != Player1 {
+		t.Error("Player1 should be to move again")
+	}
+
+	game.Forfeit(Player1)
+	if _, ok := game.UndoTurn(Player2); ok {
+		t.Error("UndoTurn should not reopen a forfeited game")
+	}
+}
+
+func TestMakeMoveAt(t *testing.T) {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	game := NewGame(p1, p2)
+
+	game.MakeMove(Player1, 3)
+	board, version := game.Snapshot()
+	if board.GetCell(Rows-1, 3) != Player1 {
+		t.Fatal("Snapshot should have the disc in column 3")
+	}
+	board.DropDisc(0, Player2)
+	if game.Board.GetCell(Rows-1, 0) != Empty {
+		t.Error("Snapshot should be a copy of the board")
+	}
+
+	// Player1's move is taken back and replaced before Player2's answer
+	// arrives: same number of moves, different position
+	game.UndoTurn(Player1)
+	game.MakeMove(Player1, 2)
+	if _, err := game.MakeMoveAt(Player2, 4, version); err == "" {
+		t.Error("Should reject a move chosen for an earlier position")
+	}
+	if len(game.Moves) != 1 {
+		t.Errorf("Stale move was recorded: %d moves", len(game.Moves))
+	}
+
+	_, version = game.Snapshot()
+	if _, err := game.MakeMoveAt(Player2, 4, version); err != "" {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
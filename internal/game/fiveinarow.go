@@ -0,0 +1,33 @@
+package game
+
+// Five-in-a-Row board size
+const (
+	FiveInARowColumns = 9
+	FiveInARowRows    = 6
+)
+
+// FiveInARow is played like classic on a 9x6 board, but a line takes five
+// discs. The outer columns start full of alternating discs, as in the
+// boxed game, so lines can lean on them but nobody can drop there.
+var FiveInARow Ruleset = &connectRules{
+	name:    VariantFiveInARow,
+	rows:    FiveInARowRows,
+	columns: FiveInARowColumns,
+	connect: 5,
+	setup:   fillOuterColumns,
+}
+
+// fillOuterColumns fills the first and last columns with alternating
+// discs, Player1's at the bottom of the left one and Player2's at the
+// bottom of the right one
+func fillOuterColumns(g *Grid) {
+	for i := 0; i < g.Rows; i++ {
+		row := g.Rows - 1 - i
+		left, right := Player1, Player2
+		if i%2 == 1 {
+			left, right = Player2, Player1
+		}
+		g.SetCell(row, 0, left)
+		g.SetCell(row, g.Columns-1, right)
+	}
+}
@@ -0,0 +1,41 @@
+package game
+
+import "testing"
+
+func TestFiveInARowSetup(t *testing.T) {
+	s := FiveInARow.NewState()
+	if s.Grid.Rows != 6 || s.Grid.Columns != 9 {
+		t.Fatalf("Board is %dx%d, want 9x6", s.Grid.Columns, s.Grid.Rows)
+	}
+	if s.Grid.GetCell(5, 0) != Player1 || s.Grid.GetCell(4, 0) != Player2 || s.Grid.GetCell(5, 8) != Player2 {
+		t.Errorf("Outer columns should start with alternating discs: %v", s.Grid.ToSlice())
+	}
+	if n := len(FiveInARow.LegalMoves(s)); n != 7 {
+		t.Errorf("%d legal moves, want the 7 inner columns", n)
+	}
+	if _, err := FiveInARow.Apply(s, Action{Column: 0}); err == nil {
+		t.Error("Outer columns should be full")
+	}
+}
+
+func TestFiveInARowNeedsFive(t *testing.T) {
+	s := FiveInARow.NewState()
+
+	// Player1 fills the bottom row next to the prefilled disc in column 0,
+	// which makes four but not five; Player2 stacks four in column 7
+	apply(t, FiveInARow, s, drops(1, 7, 2, 7, 3, 7, 6, 7)...)
+	if FiveInARow.Terminal(s) {
+		t.Fatal("Four in a row shouldn't win")
+	}
+
+	apply(t, FiveInARow, s, Action{Column: 4})
+	winner, cells := FiveInARow.Winner(s)
+	if winner != Player1 || len(cells) != 5 {
+		t.Fatalf("Winner %d with %v, want Player1 with five cells", winner, cells)
+	}
+	for _, cell := range cells {
+		if cell[0] != 5 || cell[1] > 4 {
+			t.Errorf("Unexpected winning cell %v", cell)
+		}
+	}
+}
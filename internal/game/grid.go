@@ -0,0 +1,235 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Grid is a board of any size, for variants played on something other than
+// the classic 7x6 Board. Row 0 is the top, as on Board.
+type Grid struct {
+	Rows    int
+	Columns int
+	cells   []Cell
+}
+
+// NewGrid creates an empty grid
+func NewGrid(rows, columns int) *Grid {
+	return &Grid{Rows: rows, Columns: columns, cells: make([]Cell, rows*columns)}
+}
+
+// Clone creates a deep copy of the grid for simulation
+func (g *Grid) Clone() *Grid {
+	clone := &Grid{Rows: g.Rows, Columns: g.Columns, cells: make([]Cell, len(g.cells))}
+	copy(clone.cells, g.cells)
+	return clone
+}
+
+// GetCell returns the cell value at the specified position, Empty off the
+// grid
+func (g *Grid) GetCell(row, col int) Cell {
+	if row < 0 || row >= g.Rows || col < 0 || col >= g.Columns {
+		return Empty
+	}
+	return g.cells[row*g.Columns+col]
+}
+
+// SetCell sets a cell directly, ignoring gravity
+func (g *Grid) SetCell(row, col int, cell Cell) {
+	g.cells[row*g.Columns+col] = cell
+}
+
+// IsColumnFull checks if a column cannot accept more discs
+func (g *Grid) IsColumnFull(col int) bool {
+	if col < 0 || col >= g.Columns {
+		return true
+	}
+	return g.GetCell(0, col) != Empty
+}
+
+// IsFull checks if every column is full
+func (g *Grid) IsFull() bool {
+	for c := 0; c < g.Columns; c++ {
+		if !g.IsColumnFull(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidColumns returns a list of columns that can accept a disc
+func (g *Grid) ValidColumns() []int {
+	var valid []int
+	for c := 0; c < g.Columns; c++ {
+		if !g.IsColumnFull(c) {
+			valid = append(valid, c)
+		}
+	}
+	return valid
+}
+
+// GetDropRow returns the row where a disc would land in the column, or -1
+// if the column is full
+func (g *Grid) GetDropRow(col int) int {
+	if g.IsColumnFull(col) {
+		return -1
+	}
+	for row := g.Rows - 1; row >= 0; row-- {
+		if g.GetCell(row, col) == Empty {
+			return row
+		}
+	}
+	return -1
+}
+
+// DropDisc drops a disc into the column and returns the row where it
+// landed, or -1 if the column is full or doesn't exist
+func (g *Grid) DropDisc(col int, player Cell) int {
+	row := g.GetDropRow(col)
+	if row != -1 {
+		g.SetCell(row, col, player)
+	}
+	return row
+}
+
+// WinningLine returns the cells of a line of four or more through (row,
+// col) for player, or nil if there is none
+func (g *Grid) WinningLine(row, col int, player Cell) [][2]int {
+	if player == Empty {
+		return nil
+	}
+	directions := [][2]int{{0, 1}, {1, 0}, {1, 1}, {1, -1}}
+	for _, dir := range directions {
+		cells := [][2]int{{row, col}}
+		for _, sign := range []int{1, -1} {
+			for i := 1; ; i++ {
+				r, c := row+sign*dir[0]*i, col+sign*dir[1]*i
+				if g.GetCell(r, c) != player {
+					break
+				}
+				cells = append(cells, [2]int{r, c})
+			}
+		}
+		if len(cells) >= 4 {
+			return cells
+		}
+	}
+	return nil
+}
+
+// WinsAt reports whether the disc at (row, col) completes four in a row
+// for player
+func (g *Grid) WinsAt(row, col int, player Cell) bool {
+	return g.WinningLine(row, col, player) != nil
+}
+
+// ToSlice converts the grid to a 2D slice for JSON serialization
+func (g *Grid) ToSlice() [][]int {
+	result := make([][]int, g.Rows)
+	for r := 0; r < g.Rows; r++ {
+		result[r] = make([]int, g.Columns)
+		for c := 0; c < g.Columns; c++ {
+			result[r][c] = int(g.GetCell(r, c))
+		}
+	}
+	return result
+}
+
+// CanPop reports whether player may pop the bottom disc of col
+func (g *Grid) CanPop(col int, player Cell) bool {
+	if col < 0 || col >= g.Columns || player == Empty {
+		return false
+	}
+	return g.GetCell(g.Rows-1, col) == player
+}
+
+// CanPopAny reports whether player has a disc at the bottom of any column
+func (g *Grid) CanPopAny(player Cell) bool {
+	for c := 0; c < g.Columns; c++ {
+		if g.CanPop(c, player) {
+			return true
+		}
+	}
+	return false
+}
+
+// PopDisc removes the bottom disc of the column and lets the discs above
+// it fall one row. Returns the disc removed, Empty if there was none.
+func (g *Grid) PopDisc(col int) Cell {
+	if col < 0 || col >= g.Columns {
+		return Empty
+	}
+	popped := g.GetCell(g.Rows-1, col)
+	for row := g.Rows - 1; row > 0; row-- {
+		g.SetCell(row, col, g.GetCell(row-1, col))
+	}
+	g.SetCell(0, col, Empty)
+	return popped
+}
+
+// Encode returns the grid's cells as a string of digits, top row first
+func (g *Grid) Encode() string {
+	buf := make([]byte, len(g.cells))
+	for i, cell := range g.cells {
+		buf[i] = byte('0' + cell)
+	}
+	return string(buf)
+}
+
+// gridJSON is how a grid is stored: its size and its encoded cells
+type gridJSON struct {
+	Rows    int    `json:"rows"`
+	Columns int    `json:"columns"`
+	Cells   string `json:"cells"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (g *Grid) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gridJSON{Rows: g.Rows, Columns: g.Columns, Cells: g.Encode()})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (g *Grid) UnmarshalJSON(data []byte) error {
+	var v gridJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Rows < 1 || v.Columns < 1 || len(v.Cells) != v.Rows*v.Columns {
+		return fmt.Errorf("grid of %dx%d can't hold %d cells", v.Rows, v.Columns, len(v.Cells))
+	}
+	*g = *NewGrid(v.Rows, v.Columns)
+	for i := 0; i < len(v.Cells); i++ {
+		cell := Cell(v.Cells[i] - '0')
+		if cell < Empty || cell > Wall {
+			return fmt.Errorf("invalid cell %q at %d", v.Cells[i], i)
+		}
+		g.cells[i] = cell
+	}
+	return nil
+}
+
+// GridFromBoard copies a classic board into a grid
+func GridFromBoard(board *Board) *Grid {
+	g := NewGrid(Rows, Columns)
+	for r := 0; r < Rows; r++ {
+		for c := 0; c < Columns; c++ {
+			g.SetCell(r, c, board[r][c])
+		}
+	}
+	return g
+}
+
+// Board copies the grid into a classic board, for the engines that play
+// on one. Returns nil if the grid isn't the classic 7x6.
+func (g *Grid) Board() *Board {
+	if g.Rows != Rows || g.Columns != Columns {
+		return nil
+	}
+	board := NewBoard()
+	for r := 0; r < Rows; r++ {
+		for c := 0; c < Columns; c++ {
+			board[r][c] = g.GetCell(r, c)
+		}
+	}
+	return board
+}
@@ -0,0 +1,363 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Colors for the extra seats of a multiplayer game
+const (
+	Player3 Cell = 3 // Green
+	Player4 Cell = 4 // Blue
+)
+
+// Seats a multiplayer game can have
+const (
+	MinMultiPlayers = 3
+	MaxMultiPlayers = 4
+)
+
+// MultiRule decides how a game with more than two players ends
+type MultiRule string
+
+const (
+	// The first player to connect four wins; everyone else shares second
+	RuleFirstToConnect MultiRule = "first_to_connect"
+	// Each player who connects four takes the next place and leaves the
+	// rotation; play goes on until one player is left
+	RuleElimination MultiRule = "elimination"
+)
+
+// Valid reports whether r is a known rule
+func (r MultiRule) Valid() bool {
+	return r == RuleFirstToConnect || r == RuleElimination
+}
+
+// MultiBoardSize returns the board a game with players seats is played on:
+// wider with every extra player so there's room to build
+func MultiBoardSize(players int) (rows, columns int) {
+	if players >= MaxMultiPlayers {
+		return 8, 10
+	}
+	return 7, 9
+}
+
+// Placement is where a seat finished. Tied players share a place.
+type Placement struct {
+	Player Cell `json:"player"`
+	Place  int  `json:"place"`
+}
+
+// MultiGame is a game for three or four players on a Grid. Seat i plays
+// Cell(i+1), and turns go round the seats still playing in order.
+type MultiGame struct {
+	ID           uuid.UUID
+	Players      []*PlayerInfo
+	Grid         *Grid
+	Rule         MultiRule
+	CurrentTurn  Cell
+	Moves        []Move
+	Status       GameStatus
+	WinningCells [][2]int // the last line of four made
+	StartedAt    time.Time
+	EndedAt      *time.Time
+
+	// places[i] is seat i's final place, 0 while it is still playing. The
+	// next player to connect takes best; the next to leave takes worst.
+	places      []int
+	best, worst int
+
+	mu sync.RWMutex
+}
+
+// NewMultiGame creates a game for three or four players
+func NewMultiGame(players []*PlayerInfo, rule MultiRule) (*MultiGame, error) {
+	if len(players) < MinMultiPlayers || len(players) > MaxMultiPlayers {
+		return nil, fmt.Errorf("multiplayer games need %d to %d players, got %d", MinMultiPlayers, MaxMultiPlayers, len(players))
+	}
+	if !rule.Valid() {
+		return nil, fmt.Errorf("unknown rule %q", rule)
+	}
+	rows, columns := MultiBoardSize(len(players))
+	return &MultiGame{
+		ID:          uuid.New(),
+		Players:     players,
+		Grid:        NewGrid(rows, columns),
+		Rule:        rule,
+		CurrentTurn: Player1,
+		Moves:       make([]Move, 0),
+		Status:      GameStatusInProgress,
+		StartedAt:   time.Now(),
+		places:      make([]int, len(players)),
+		best:        1,
+		worst:       len(players),
+	}, nil
+}
+
+// Seat returns the color a username plays, or Empty if they aren't in the
+// game
+func (g *MultiGame) Seat(username string) Cell {
+	for i, p := range g.Players {
+		if p.Username == username {
+			return Cell(i + 1)
+		}
+	}
+	return Empty
+}
+
+// PlayerInfo returns the player in a seat, nil if there is no such seat
+func (g *MultiGame) PlayerInfo(player Cell) *PlayerInfo {
+	if player < Player1 || int(player) > len(g.Players) {
+		return nil
+	}
+	return g.Players[player-1]
+}
+
+// MakeMove drops player's disc into col. Returns the row where it landed,
+// or an error message.
+func (g *MultiGame) MakeMove(player Cell, col int) (int, string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != GameStatusInProgress {
+		return -1, "game is not in progress"
+	}
+	if g.CurrentTurn != player {
+		return -1, "not your turn"
+	}
+	if col < 0 || col >= g.Grid.Columns {
+		return -1, "invalid column"
+	}
+	row := g.Grid.DropDisc(col, player)
+	if row == -1 {
+		return -1, "column is full"
+	}
+	g.Moves = append(g.Moves, Move{
+		Player:    player,
+		Column:    col,
+		Row:       row,
+		MoveNum:   len(g.Moves) + 1,
+		Timestamp: time.Now(),
+	})
+
+	if cells := g.Grid.WinningLine(row, col, player); cells != nil {
+		g.WinningCells = cells
+		g.place(player, g.best)
+		g.best++
+		if g.Rule == RuleFirstToConnect {
+			g.finish()
+			return row, ""
+		}
+	}
+	if g.Grid.IsFull() || g.active() <= 1 {
+		g.finish()
+		return row, ""
+	}
+	g.advance()
+	return row, ""
+}
+
+// Eliminate takes a player out of the game (they left or timed out) with
+// the worst place still open. Returns false if they had already finished.
+func (g *MultiGame) Eliminate(player Cell) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != GameStatusInProgress || g.PlayerInfo(player) == nil || g.places[player-1] != 0 {
+		return false
+	}
+	g.place(player, g.worst)
+	g.worst--
+	if g.active() <= 1 {
+		g.finish()
+	} else if g.CurrentTurn == player {
+		g.advance()
+	}
+	return true
+}
+
+// End stops the game early, as an admin would: winner, if not Empty,
+// takes the best place left and everyone else still playing shares the
+// next. Returns false if the game is over or winner has already finished.
+func (g *MultiGame) End(winner Cell) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Status != GameStatusInProgress {
+		return false
+	}
+	if winner != Empty {
+		if g.PlayerInfo(winner) == nil || g.places[winner-1] != 0 {
+			return false
+		}
+		g.place(winner, g.best)
+		g.best++
+	}
+	g.finish()
+	return true
+}
+
+// place records a seat's final place. Caller must hold g.mu.
+func (g *MultiGame) place(player Cell, place int) {
+	g.places[player-1] = place
+}
+
+// finish ends the game: everyone still playing shares the best place left.
+// Caller must hold g.mu.
+func (g *MultiGame) finish() {
+	for i := range g.places {
+		if g.places[i] == 0 {
+			g.places[i] = g.best
+		}
+	}
+	g.Status = GameStatusFinished
+	now := time.Now()
+	g.EndedAt = &now
+}
+
+// active counts the seats still playing. Caller must hold g.mu.
+func (g *MultiGame) active() int {
+	n := 0
+	for _, place := range g.places {
+		if place == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// advance passes the turn to the next seat still playing. Caller must hold
+// g.mu.
+func (g *MultiGame) advance() {
+	n := len(g.Players)
+	for i := 1; i <= n; i++ {
+		next := Cell((int(g.CurrentTurn)-1+i)%n + 1)
+		if g.places[next-1] == 0 {
+			g.CurrentTurn = next
+			return
+		}
+	}
+}
+
+// TurnOrder returns the seats still playing, starting with the player to
+// move
+func (g *MultiGame) TurnOrder() []Cell {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	n := len(g.Players)
+	order := make([]Cell, 0, n)
+	for i := 0; i < n; i++ {
+		seat := Cell((int(g.CurrentTurn)-1+i)%n + 1)
+		if g.places[seat-1] == 0 {
+			order = append(order, seat)
+		}
+	}
+	return order
+}
+
+// Place returns a seat's final place, 0 while it is still playing
+func (g *MultiGame) Place(player Cell) int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.PlayerInfo(player) == nil {
+		return 0
+	}
+	return g.places[player-1]
+}
+
+// Placements returns every seat's place, best first. Seats still playing
+// have place 0 and come last.
+func (g *MultiGame) Placements() []Placement {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	placements := make([]Placement, len(g.places))
+	for i, place := range g.places {
+		placements[i] = Placement{Player: Cell(i + 1), Place: place}
+	}
+	sort.SliceStable(placements, func(i, j int) bool {
+		a, b := placements[i].Place, placements[j].Place
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+	return placements
+}
+
+// Snapshot returns a copy of the grid and the player to move, taken
+// together
+func (g *MultiGame) Snapshot() (*Grid, Cell) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Grid.Clone(), g.CurrentTurn
+}
+
+// IsGameOver returns true if the game has ended
+func (g *MultiGame) IsGameOver() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Status == GameStatusFinished
+}
+
+// GetStatus returns whether the game is still going
+func (g *MultiGame) GetStatus() GameStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Status
+}
+
+// MoveCount returns the number of moves played so far
+func (g *MultiGame) MoveCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.Moves)
+}
+
+// GetCurrentPlayer returns the player to move
+func (g *MultiGame) GetCurrentPlayer() Cell {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.CurrentTurn
+}
+
+// Duration returns the game length in seconds
+func (g *MultiGame) Duration() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	end := time.Now()
+	if g.EndedAt != nil {
+		end = *g.EndedAt
+	}
+	return int(end.Sub(g.StartedAt).Seconds())
+}
+
+// SetConnected records a player dropping off or coming back
+func (g *MultiGame) SetConnected(player Cell, connected bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	info := g.PlayerInfo(player)
+	if info == nil {
+		return
+	}
+	info.Connected = connected
+	info.DisconnectedAt = nil
+	if !connected {
+		now := time.Now()
+		info.DisconnectedAt = &now
+	}
+}
+
+// IsConnected reports whether a player is connected
+func (g *MultiGame) IsConnected(player Cell) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	info := g.PlayerInfo(player)
+	return info != nil && info.Connected
+}
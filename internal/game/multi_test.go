@@ -0,0 +1,207 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newMultiPlayers(n int) []*PlayerInfo {
+	players := make([]*PlayerInfo, n)
+	for i := range players {
+		players[i] = &PlayerInfo{ID: uuid.New(), Username: string(rune('a' + i)), Connected: true}
+	}
+	return players
+}
+
+func TestMultiGameRotation(t *testing.T) {
+	g, err := NewMultiGame(newMultiPlayers(4), RuleFirstToConnect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Grid.Rows != 8 || g.Grid.Columns != 10 {
+		t.Errorf("4-player board is %dx%d, want 10x8", g.Grid.Columns, g.Grid.Rows)
+	}
+	for i, want := range []Cell{Player1, Player2, Player3, Player4, Player1} {
+		if g.CurrentTurn != want {
+			t.Fatalf("move %d: turn %d, want %d", i, g.CurrentTurn, want)
+		}
+		if _, errMsg := g.MakeMove(want, i); errMsg != "" {
+			t.Fatalf("move %d: %s", i, errMsg)
+		}
+	}
+	if _, errMsg := g.MakeMove(Player4, 0); errMsg == "" {
+		t.Error("Should reject a move out of turn")
+	}
+
+	if _, err := NewMultiGame(newMultiPlayers(2), RuleFirstToConnect); err == nil {
+		t.Error("Two players should be refused")
+	}
+	if _, err := NewMultiGame(newMultiPlayers(3), "last_one_standing"); err == nil {
+		t.Error("Unknown rules should be refused")
+	}
+}
+
+// play makes moves in turn order; columns are chosen per seat
+func play(t *testing.T, g *MultiGame, cols ...int) {
+	t.Helper()
+	for _, col := range cols {
+		if _, errMsg := g.MakeMove(g.CurrentTurn, col); errMsg != "" {
+			t.Fatalf("column %d for %d: %s", col, g.CurrentTurn, errMsg)
+		}
+	}
+}
+
+func TestMultiGameFirstToConnect(t *testing.T) {
+	g, _ := NewMultiGame(newMultiPlayers(3), RuleFirstToConnect)
+	// Player1 stacks column 0, the others play 1-8 out of the way
+	play(t, g, 0, 1, 2, 0, 3, 4, 0, 5, 6, 0)
+
+	if !g.IsGameOver() {
+		t.Fatal("Player1 connected four; the game should be over")
+	}
+	if len(g.WinningCells) != 4 {
+		t.Errorf("WinningCells = %v", g.WinningCells)
+	}
+	want := []Placement{{Player1, 1}, {Player2, 2}, {Player3, 2}}
+	if got := g.Placements(); !equalPlacements(got, want) {
+		t.Errorf("Placements = %v, want %v", got, want)
+	}
+}
+
+func TestMultiGameElimination(t *testing.T) {
+	g, _ := NewMultiGame(newMultiPlayers(3), RuleElimination)
+	play(t, g, 0, 1, 2, 0, 3, 4, 0, 5, 6, 0)
+
+	if g.IsGameOver() {
+		t.Fatal("Elimination goes on after the first connect")
+	}
+	if g.Place(Player1) != 1 || g.CurrentTurn != Player2 {
+		t.Fatalf("Player1 should take first and leave the rotation, turn is %d", g.CurrentTurn)
+	}
+	if order := g.TurnOrder(); len(order) != 2 || order[0] != Player2 || order[1] != Player3 {
+		t.Errorf("TurnOrder = %v, want [2 3]", order)
+	}
+
+	// Player3 stacks column 8 while Player2 plays elsewhere
+	play(t, g, 1, 8, 3, 8, 5, 8, 7, 8)
+	want := []Placement{{Player1, 1}, {Player3, 2}, {Player2, 3}}
+	if !g.IsGameOver() {
+		t.Fatal("One player left; the game should be over")
+	}
+	if got := g.Placements(); !equalPlacements(got, want) {
+		t.Errorf("Placements = %v, want %v", got, want)
+	}
+}
+
+func TestMultiGameEliminate(t *testing.T) {
+	g, _ := NewMultiGame(newMultiPlayers(4), RuleFirstToConnect)
+	if !g.Eliminate(Player1) {
+		t.Fatal("Eliminate should take out a player still playing")
+	}
+	if g.Place(Player1) != 4 || g.CurrentTurn != Player2 {
+		t.Errorf("leaver should take last place and pass the turn: place %d, turn %d", g.Place(Player1), g.CurrentTurn)
+	}
+	if g.Eliminate(Player1) {
+		t.Error("A player can only leave once")
+	}
+
+	g.Eliminate(Player3)
+	g.Eliminate(Player4)
+	want := []Placement{{Player2, 1}, {Player4, 2}, {Player3, 3}, {Player1, 4}}
+	if !g.IsGameOver() {
+		t.Fatal("Last player standing should end the game")
+	}
+	if got := g.Placements(); !equalPlacements(got, want) {
+		t.Errorf("Placements = %v, want %v", got, want)
+	}
+}
+
+func TestMultiGameEnd(t *testing.T) {
+	g, _ := NewMultiGame(newMultiPlayers(4), RuleElimination)
+	g.Eliminate(Player2)
+	if g.End(Player2) {
+		t.Error("A player who already left can't be given the win")
+	}
+	if !g.End(Player3) {
+		t.Fatal("End should stop a game in progress")
+	}
+	want := []Placement{{Player3, 1}, {Player1, 2}, {Player4, 2}, {Player2, 4}}
+	if got := g.Placements(); !g.IsGameOver() || !equalPlacements(got, want) {
+		t.Errorf("Placements = %v, want %v", got, want)
+	}
+	if g.End(Empty) {
+		t.Error("A finished game can't be ended again")
+	}
+
+	g, _ = NewMultiGame(newMultiPlayers(3), RuleFirstToConnect)
+	g.End(Empty)
+	for _, p := range g.Placements() {
+		if p.Place != 1 {
+			t.Errorf("ending without a winner should leave everyone first: %v", g.Placements())
+		}
+	}
+}
+
+func TestMultiGameFullBoard(t *testing.T) {
+	g, _ := NewMultiGame(newMultiPlayers(3), RuleElimination)
+	// Fill all but the top right cell with a pattern that has no four in a
+	// row anywhere, then let Player1 drop the last disc
+	g.mu.Lock()
+	for r := 0; r < g.Grid.Rows; r++ {
+		for c := 0; c < g.Grid.Columns; c++ {
+			if r == 0 && c == g.Grid.Columns-1 {
+				continue
+			}
+			g.Grid.SetCell(r, c, Cell((r/2+c)%3+1))
+		}
+	}
+	g.mu.Unlock()
+
+	if line := g.Grid.WinningLine(1, 0, g.Grid.GetCell(1, 0)); line != nil {
+		t.Fatalf("test board already has a line: %v", line)
+	}
+	_, errMsg := g.MakeMove(Player1, g.Grid.Columns-1)
+	if errMsg != "" {
+		t.Fatal(errMsg)
+	}
+	if !g.IsGameOver() {
+		t.Fatal("A full board should end the game")
+	}
+	for _, p := range g.Placements() {
+		if p.Place != 1 {
+			t.Errorf("everyone still playing shares first on a full board: %v", g.Placements())
+		}
+	}
+}
+
+func TestGridWinningLine(t *testing.T) {
+	g := NewGrid(7, 9)
+	for i := 0; i < 5; i++ {
+		g.SetCell(6-i, 2+i, Player3)
+	}
+	if line := g.WinningLine(4, 4, Player3); len(line) != 5 {
+		t.Errorf("diagonal of five: got %v", line)
+	}
+	if g.WinsAt(4, 4, Player2) {
+		t.Error("Player2 has no line")
+	}
+	if row := g.DropDisc(8, Player1); row != 6 {
+		t.Errorf("DropDisc landed on row %d, want 6", row)
+	}
+	if g.DropDisc(9, Player1) != -1 {
+		t.Error("Column off the grid should be refused")
+	}
+}
+
+func equalPlacements(a, b []Placement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
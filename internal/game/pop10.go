@@ -0,0 +1,195 @@
+package game
+
+import "errors"
+
+// pop10Target is how many discs a player has to set aside to win Pop 10
+const pop10Target = 10
+
+// pop10Rules is Pop 10 on the classic board
+type pop10Rules struct{}
+
+// Pop10 starts with the players taking turns filling the board, lowest
+// open row first. Then each player in turn pops one of their own discs
+// from the bottom row: if it was part of a line of four they set it aside
+// and pop again, otherwise they drop it back into a different column and
+// the turn passes. A player with nothing to pop is skipped. The first to
+// set aside ten discs wins.
+var Pop10 Ruleset = pop10Rules{}
+
+var (
+	errFillLowest = errors.New("fill the lowest open row first")
+	errFillFirst  = errors.New("the board has to be filled before popping")
+	errMustPop    = errors.New("pop one of your own discs from the bottom")
+	errDropBack   = errors.New("drop the popped disc back into another column")
+)
+
+func (pop10Rules) Name() Variant { return VariantPop10 }
+func (pop10Rules) Connect() int  { return 4 }
+
+func (pop10Rules) NewState() *State {
+	s := newState(Rows, Columns)
+	s.Captured = make(map[Cell]int)
+	return s
+}
+
+func (r pop10Rules) LegalMoves(s *State) []Action {
+	if r.Terminal(s) {
+		return nil
+	}
+	var moves []Action
+	switch {
+	case !s.Filled:
+		lowest := lowestOpenRow(s.Grid)
+		for col := 0; col < s.Grid.Columns; col++ {
+			if s.Grid.GetDropRow(col) == lowest {
+				moves = append(moves, Action{Column: col})
+			}
+		}
+	case s.Holding:
+		for _, col := range dropBackColumns(s) {
+			moves = append(moves, Action{Column: col})
+		}
+	default:
+		for col := 0; col < s.Grid.Columns; col++ {
+			if s.Grid.CanPop(col, s.Turn) {
+				moves = append(moves, Action{Type: MovePop, Column: col})
+			}
+		}
+	}
+	return moves
+}
+
+func (pop10Rules) Apply(s *State, a Action) (int, error) {
+	if a.Disc != "" {
+		return -1, errNoSpecials
+	}
+	if a.Type != "" && a.Type != MoveDrop && a.Type != MovePop {
+		return -1, errUnknownMove
+	}
+	pop := a.Type == MovePop
+	if a.Column < 0 || a.Column >= s.Grid.Columns {
+		return -1, errInvalidColumn
+	}
+
+	switch {
+	case !s.Filled:
+		if pop {
+			return -1, errFillFirst
+		}
+		if s.Grid.IsColumnFull(a.Column) {
+			return -1, errColumnFull
+		}
+		if s.Grid.GetDropRow(a.Column) != lowestOpenRow(s.Grid) {
+			return -1, errFillLowest
+		}
+		row, _ := drop(s, a.Column, s.Turn)
+		if s.Filled = s.Grid.IsFull(); s.Filled {
+			s.handOver()
+		} else {
+			s.pass()
+		}
+		return row, nil
+
+	case s.Holding:
+		if pop {
+			return -1, errDropBack
+		}
+		allowed := false
+		for _, col := range dropBackColumns(s) {
+			allowed = allowed || col == a.Column
+		}
+		if !allowed {
+			return -1, errDropBack
+		}
+		row, _ := drop(s, a.Column, s.Turn)
+		s.Holding, s.HeldFrom = false, 0
+		s.handOver()
+		return row, nil
+	}
+
+	if !pop {
+		return -1, errMustPop
+	}
+	if !s.Grid.CanPop(a.Column, s.Turn) {
+		return -1, errPopOwn
+	}
+	row := s.Grid.Rows - 1
+	scored, _ := checkWin(s.Grid, row, a.Column, s.Turn, 4, s.Cylinder)
+	s.Grid.PopDisc(a.Column)
+	if !scored {
+		s.Holding, s.HeldFrom = true, a.Column
+		return row, nil
+	}
+
+	if s.Captured == nil {
+		s.Captured = make(map[Cell]int)
+	}
+	s.Captured[s.Turn]++
+	if s.Captured[s.Turn] >= pop10Target {
+		s.Winner = s.Turn
+		return row, nil
+	}
+	// Setting a disc aside earns another pop, if there is one to make
+	if !s.Grid.CanPopAny(s.Turn) {
+		s.handOver()
+	}
+	return row, nil
+}
+
+func (pop10Rules) Terminal(s *State) bool {
+	return s.Winner != Empty || s.Drawn
+}
+
+func (pop10Rules) Winner(s *State) (Cell, [][2]int) {
+	return s.Winner, nil
+}
+
+func (pop10Rules) Encode(s *State) ([]byte, error) {
+	return encodeState(s)
+}
+
+func (pop10Rules) Decode(data []byte) (*State, error) {
+	return decodeState(data, Rows, Columns)
+}
+
+// handOver passes the turn, skipping a player with nothing to pop. If
+// neither player can pop, the game is drawn.
+func (s *State) handOver() {
+	s.pass()
+	if s.Grid.CanPopAny(s.Turn) {
+		return
+	}
+	s.pass()
+	if !s.Grid.CanPopAny(s.Turn) {
+		s.Drawn = true
+	}
+}
+
+// lowestOpenRow returns the lowest row with an empty cell, -1 if the grid
+// is full
+func lowestOpenRow(g *Grid) int {
+	for row := g.Rows - 1; row >= 0; row-- {
+		for col := 0; col < g.Columns; col++ {
+			if g.GetCell(row, col) == Empty {
+				return row
+			}
+		}
+	}
+	return -1
+}
+
+// dropBackColumns returns where a popped disc that didn't score may go:
+// any column with room other than the one it came from, or that one if
+// every other column is full
+func dropBackColumns(s *State) []int {
+	var cols []int
+	for _, col := range s.Grid.ValidColumns() {
+		if col != s.HeldFrom {
+			cols = append(cols, col)
+		}
+	}
+	if len(cols) == 0 {
+		cols = []int{s.HeldFrom}
+	}
+	return cols
+}
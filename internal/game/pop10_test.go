@@ -0,0 +1,99 @@
+package game
+
+import "testing"
+
+func TestPop10Setup(t *testing.T) {
+	s := Pop10.NewState()
+	apply(t, Pop10, s, Action{Column: 0})
+	if _, err := Pop10.Apply(s, Action{Column: 0}); err != errFillLowest {
+		t.Errorf("Stacking before the bottom row is full: %v, want %v", err, errFillLowest)
+	}
+	if _, err := Pop10.Apply(s, Action{Type: MovePop, Column: 0}); err != errFillFirst {
+		t.Errorf("Popping during the setup: %v, want %v", err, errFillFirst)
+	}
+
+	for !s.Filled {
+		moves := Pop10.LegalMoves(s)
+		if len(moves) == 0 {
+			t.Fatal("Setup ran out of moves before the board filled")
+		}
+		apply(t, Pop10, s, moves[0])
+	}
+	if !s.Grid.IsFull() {
+		t.Fatal("Setup should end with a full board")
+	}
+	for _, m := range Pop10.LegalMoves(s) {
+		if m.Type != MovePop {
+			t.Errorf("After the setup only pops are legal, got %+v", m)
+		}
+	}
+}
+
+// pop10State is a Pop 10 position after the setup, Player1 to move
+func pop10State(t *testing.T, rows ...string) *State {
+	s := Pop10.NewState()
+	s.Grid = gridFrom(t, rows...)
+	s.Filled = true
+	return s
+}
+
+func TestPop10Capture(t *testing.T) {
+	s := pop10State(t,
+		".......",
+		".......",
+		".......",
+		".......",
+		"2......",
+		"1111.2.",
+	)
+
+	// Column 0's disc is part of a line: it's set aside and Player1 pops again
+	apply(t, Pop10, s, Action{Type: MovePop, Column: 0})
+	if s.Captured[Player1] != 1 || s.Turn != Player1 || s.Holding {
+		t.Fatalf("After scoring: captured %d, turn %d, holding %v", s.Captured[Player1], s.Turn, s.Holding)
+	}
+
+	// Columns 1-3 are only three now: the disc has to go back elsewhere
+	apply(t, Pop10, s, Action{Type: MovePop, Column: 1})
+	if !s.Holding || s.HeldFrom != 1 || s.Turn != Player1 {
+		t.Fatalf("After a pop that didn't score: holding %v from %d, turn %d", s.Holding, s.HeldFrom, s.Turn)
+	}
+	if _, err := Pop10.Apply(s, Action{Column: 1}); err != errDropBack {
+		t.Errorf("Dropping back into the same column: %v, want %v", err, errDropBack)
+	}
+	apply(t, Pop10, s, Action{Column: 4})
+	if s.Holding || s.Turn != Player2 || s.Grid.GetCell(5, 4) != Player1 {
+		t.Errorf("The disc should be back in column 4 with Player2 to move")
+	}
+}
+
+func TestPop10Win(t *testing.T) {
+	s := pop10State(t,
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+		"11112..",
+	)
+	s.Captured[Player1] = pop10Target - 1
+	apply(t, Pop10, s, Action{Type: MovePop, Column: 3})
+	if winner, _ := Pop10.Winner(s); !Pop10.Terminal(s) || winner != Player1 {
+		t.Errorf("Setting aside the tenth disc should win, got winner %d", winner)
+	}
+}
+
+func TestPop10SkipsPlayerWithoutPops(t *testing.T) {
+	s := pop10State(t,
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+		"1.1....",
+	)
+	apply(t, Pop10, s, Action{Type: MovePop, Column: 0}, Action{Column: 3})
+	if s.Turn != Player1 {
+		t.Errorf("Player2 has nothing to pop and should be skipped")
+	}
+}
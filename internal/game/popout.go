@@ -0,0 +1,122 @@
+package game
+
+import "strconv"
+
+// repetitionLimit is how many times the same PopOut position, with the
+// same player to move, may come up before the game is drawn
+const repetitionLimit = 3
+
+// popOutRules is classic with pops: a move may instead take one of your
+// own discs out of the bottom of a column, letting the column fall
+type popOutRules struct{}
+
+// PopOut is Connect Four with pops. A pop that connects four for both
+// players wins for the one who popped; a position coming up for the third
+// time is a draw; and a full board only draws if the player to move has
+// nothing to pop.
+var PopOut Ruleset = popOutRules{}
+
+func (popOutRules) Name() Variant { return VariantPopOut }
+func (popOutRules) Connect() int  { return 4 }
+
+func (popOutRules) NewState() *State {
+	s := newState(Rows, Columns)
+	s.Positions = make(map[string]int)
+	s.recordPosition()
+	return s
+}
+
+func (r popOutRules) LegalMoves(s *State) []Action {
+	if r.Terminal(s) {
+		return nil
+	}
+	var moves []Action
+	for _, col := range s.Grid.ValidColumns() {
+		moves = append(moves, Action{Column: col})
+	}
+	for col := 0; col < s.Grid.Columns; col++ {
+		if s.Grid.CanPop(col, s.Turn) {
+			moves = append(moves, Action{Type: MovePop, Column: col})
+		}
+	}
+	return moves
+}
+
+func (popOutRules) Apply(s *State, a Action) (int, error) {
+	if a.Disc != "" {
+		return -1, errNoSpecials
+	}
+
+	var row int
+	switch a.Type {
+	case "", MoveDrop:
+		var err error
+		if row, err = drop(s, a.Column, s.Turn); err != nil {
+			return -1, err
+		}
+		if s.connects(row, a.Column, s.Turn, 4) {
+			return row, nil
+		}
+	case MovePop:
+		if a.Column < 0 || a.Column >= s.Grid.Columns {
+			return -1, errInvalidColumn
+		}
+		if !s.Grid.CanPop(a.Column, s.Turn) {
+			return -1, errPopOwn
+		}
+		row = s.Grid.Rows - 1
+		s.Grid.PopDisc(a.Column)
+		if s.popWinner(a.Column) {
+			return row, nil
+		}
+	default:
+		return -1, errUnknownMove
+	}
+
+	s.pass()
+	if s.recordPosition() >= repetitionLimit {
+		s.Drawn = true
+	}
+	return row, nil
+}
+
+func (popOutRules) Terminal(s *State) bool {
+	if s.Winner != Empty || s.Drawn {
+		return true
+	}
+	return s.Grid.IsFull() && !s.Grid.CanPopAny(s.Turn)
+}
+
+func (popOutRules) Winner(s *State) (Cell, [][2]int) {
+	return s.Winner, s.WinningCells
+}
+
+func (popOutRules) Encode(s *State) ([]byte, error) {
+	return encodeState(s)
+}
+
+func (popOutRules) Decode(data []byte) (*State, error) {
+	return decodeState(data, Rows, Columns)
+}
+
+// popWinner records who connects four after the player to move popped
+// col: every disc in it moved, so any new line runs through it. If both
+// players connect, the player who popped wins.
+func (s *State) popWinner(col int) bool {
+	for _, player := range []Cell{s.Turn, opponent(s.Turn)} {
+		for row := 0; row < s.Grid.Rows; row++ {
+			if s.Grid.GetCell(row, col) == player && s.connects(row, col, player, 4) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordPosition counts the current position and returns how many times
+// it has come up
+func (s *State) recordPosition() int {
+	key := s.Grid.Encode() + strconv.Itoa(int(s.Turn))
+	s.Positions[key]++
+	return s.Positions[key]
+}
@@ -0,0 +1,202 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newPopOutGame() *Game {
+	p1 := &PlayerInfo{ID: uuid.New(), Username: "player1"}
+	p2 := &PlayerInfo{ID: uuid.New(), Username: "player2"}
+	return NewVariantGame(p1, p2, VariantPopOut)
+}
+
+// startFrom makes board the position g starts from
+func startFrom(g *Game, board *Board) {
+	g.state.Grid = GridFromBoard(board)
+	g.Board = g.state.Grid
+	g.start = g.state.Clone()
+}
+
+// boardFrom builds a board from rows written top first, '.' for empty
+func boardFrom(t *testing.T, rows ...string) *Board {
+	t.Helper()
+	s := ""
+	for _, r := range rows {
+		for _, ch := range r {
+			if ch == '.' {
+				ch = '0'
+			}
+			s += string(ch)
+		}
+	}
+	b, err := DecodeBoard(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPopDisc(t *testing.T) {
+	b := NewBoard()
+	b.DropDisc(2, Player1)
+	b.DropDisc(2, Player2)
+	b.DropDisc(2, Player1)
+
+	if b.CanPop(2, Player2) {
+		t.Error("Player2 shouldn't pop Player1's disc")
+	}
+	if !b.CanPop(2, Player1) || b.CanPop(3, Player1) {
+		t.Error("Player1 should pop column 2 only")
+	}
+
+	if popped := b.PopDisc(2); popped != Player1 {
+		t.Errorf("Popped %d, want Player1", popped)
+	}
+	if b[Rows-1][2] != Player2 || b[Rows-2][2] != Player1 || b[Rows-3][2] != Empty {
+		t.Errorf("Column didn't fall: %v", b.ToSlice())
+	}
+
+	b.PushDisc(2, Player1)
+	if b[Rows-1][2] != Player1 || b[Rows-2][2] != Player2 || b[Rows-3][2] != Player1 {
+		t.Errorf("PushDisc didn't undo the pop: %v", b.ToSlice())
+	}
+}
+
+func TestMakePop(t *testing.T) {
+	game := newPopOutGame()
+	game.MakeMove(Player1, 0)
+	game.MakeMove(Player2, 1)
+
+	if _, err := game.MakePop(Player1, 1); err == "" {
+		t.Error("Should refuse popping the opponent's disc")
+	}
+	if _, err := game.MakePop(Player1, 0); err != "" {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	last := game.Moves[len(game.Moves)-1]
+	if last.Type != MovePop || last.Row != Rows-1 {
+		t.Errorf("Pop recorded as %+v", last)
+	}
+	if game.Board.GetCell(Rows-1, 0) != Empty || game.CurrentTurn != Player2 {
+		t.Error("Pop should empty column 0 and pass the turn")
+	}
+
+	classic := NewGame(game.Player1, game.Player2)
+	classic.MakeMove(Player1, 0)
+	classic.MakeMove(Player2, 1)
+	if _, err := classic.MakePop(Player1, 0); err == "" {
+		t.Error("Classic games should refuse pops")
+	}
+}
+
+func TestPopWins(t *testing.T) {
+	tests := []struct {
+		name   string
+		board  *Board
+		winner Cell
+	}{
+		{
+			// Popping column 3 brings Player1's disc down to finish row 4
+			// and Player2's down to finish row 5: the popper wins
+			name: "both connect",
+			board: boardFrom(t,
+				".......",
+				".......",
+				".......",
+				"...1...",
+				"1112...",
+				"2121222",
+			),
+			winner: Player1,
+		},
+		{
+			name: "only opponent connects",
+			board: boardFrom(t,
+				".......",
+				".......",
+				".......",
+				"...1...",
+				"2112...",
+				"1121222",
+			),
+			winner: Player2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newPopOutGame()
+			startFrom(game, tt.board)
+			if _, err := game.MakePop(Player1, 3); err != "" {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if game.Winner != tt.winner || !game.IsGameOver() {
+				t.Fatalf("Winner %d, want %d", game.Winner, tt.winner)
+			}
+			if len(game.WinningCells) < 4 {
+				t.Fatalf("Expected winning cells, got %v", game.WinningCells)
+			}
+			for _, cell := range game.WinningCells {
+				if game.Board.GetCell(cell[0], cell[1]) != tt.winner {
+					t.Errorf("Winning cell %v isn't the winner's", cell)
+				}
+			}
+		})
+	}
+}
+
+func TestPopOutRepetitionDraw(t *testing.T) {
+	game := newPopOutGame()
+
+	// Both players drop and pop back to the empty board: its third
+	// appearance with Player1 to move draws the game
+	for cycle := 0; cycle < 2; cycle++ {
+		if game.IsGameOver() {
+			t.Fatalf("Game ended early in cycle %d", cycle)
+		}
+		game.MakeMove(Player1, 0)
+		game.MakeMove(Player2, 1)
+		game.MakePop(Player1, 0)
+		game.MakePop(Player2, 1)
+	}
+	if !game.IsGameOver() || game.Result != ResultDraw {
+		t.Fatalf("Expected a repetition draw, got status=%s result=%s", game.Status, game.Result)
+	}
+
+	// Taking the last pop back reopens the game and forgets the repetition
+	if _, ok := game.UndoTurn(Player2); !ok {
+		t.Fatal("UndoTurn should reopen a drawn game")
+	}
+	if game.Board.GetCell(Rows-1, 1) != Player2 {
+		t.Error("UndoTurn should put the popped disc back")
+	}
+	game.MakePop(Player2, 1)
+	if !game.IsGameOver() {
+		t.Error("Replaying the pop should draw again")
+	}
+}
+
+func TestPopOutFullBoard(t *testing.T) {
+	game := newPopOutGame()
+	startFrom(game, boardFrom(t,
+		".212121",
+		"1212121",
+		"2121212",
+		"2121212",
+		"1212121",
+		"1212121",
+	))
+
+	game.MakeMove(Player1, 0)
+	if !game.Board.IsFull() {
+		t.Fatal("Board should be full")
+	}
+	if game.IsGameOver() {
+		t.Fatal("A full board isn't a draw while the next player can pop")
+	}
+	if _, err := game.MakePop(Player2, 1); err != "" {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,186 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Wall is the cell of a Power Up wall disc, which belongs to nobody
+const Wall Cell = 5
+
+// Disc is one of Power Up's special discs
+type Disc string
+
+const (
+	// Lands on the bottom of the column, knocking out every disc in it
+	DiscAnvil Disc = "anvil"
+	// Blows up with the disc it lands on, leaving neither behind
+	DiscBomb Disc = "bomb"
+	// A disc nobody can use in a line; the player then drops again
+	DiscWall Disc = "wall"
+	// An ordinary disc after which the player drops again
+	DiscDouble Disc = "double"
+)
+
+// powerUpDiscs are the special discs each player starts with
+var powerUpDiscs = []Disc{DiscAnvil, DiscBomb, DiscWall, DiscDouble}
+
+// powerUpRules is classic with special discs
+type powerUpRules struct{}
+
+// PowerUp plays classic with one of each special disc per player, each
+// usable once in place of an ordinary drop. The extra drop after a wall
+// or double disc has to be an ordinary disc.
+var PowerUp Ruleset = powerUpRules{}
+
+var (
+	errExtraDrop = errors.New("the extra drop has to be an ordinary disc")
+	errBombEmpty = errors.New("the bomb needs a disc to land on")
+)
+
+func (powerUpRules) Name() Variant { return VariantPowerUp }
+func (powerUpRules) Connect() int  { return 4 }
+
+func (powerUpRules) NewState() *State {
+	s := newState(Rows, Columns)
+	s.Specials = map[Cell][]Disc{
+		Player1: append([]Disc(nil), powerUpDiscs...),
+		Player2: append([]Disc(nil), powerUpDiscs...),
+	}
+	return s
+}
+
+func (r powerUpRules) LegalMoves(s *State) []Action {
+	if s.Winner != Empty {
+		return nil
+	}
+	var moves []Action
+	for _, col := range s.Grid.ValidColumns() {
+		moves = append(moves, Action{Column: col})
+		if s.Extra {
+			continue
+		}
+		for _, disc := range s.Specials[s.Turn] {
+			if disc == DiscBomb && s.Grid.GetCell(s.Grid.Rows-1, col) == Empty {
+				continue
+			}
+			moves = append(moves, Action{Column: col, Disc: disc})
+		}
+	}
+	return moves
+}
+
+func (powerUpRules) Apply(s *State, a Action) (int, error) {
+	if a.Type == MovePop {
+		return -1, errNoPops
+	}
+	if a.Type != "" && a.Type != MoveDrop {
+		return -1, errUnknownMove
+	}
+	if a.Column < 0 || a.Column >= s.Grid.Columns {
+		return -1, errInvalidColumn
+	}
+	if a.Disc != "" {
+		if err := s.canUse(a.Disc); err != nil {
+			return -1, err
+		}
+	}
+	if s.Grid.IsColumnFull(a.Column) {
+		return -1, errColumnFull
+	}
+
+	player := s.Turn
+	switch a.Disc {
+	case "":
+		row, _ := drop(s, a.Column, player)
+		s.Extra = false
+		if !s.connects(row, a.Column, player, 4) {
+			s.pass()
+		}
+		return row, nil
+
+	case DiscAnvil:
+		row := s.Grid.Rows - 1
+		for r := 0; r < row; r++ {
+			s.Grid.SetCell(r, a.Column, Empty)
+		}
+		s.Grid.SetCell(row, a.Column, player)
+		s.use(a.Disc)
+		if !s.connects(row, a.Column, player, 4) {
+			s.pass()
+		}
+		return row, nil
+
+	case DiscBomb:
+		row := s.Grid.GetDropRow(a.Column) + 1
+		if row >= s.Grid.Rows {
+			return -1, errBombEmpty
+		}
+		s.Grid.SetCell(row, a.Column, Empty)
+		s.use(a.Disc)
+		s.pass()
+		return row, nil
+
+	case DiscWall:
+		row, _ := drop(s, a.Column, Wall)
+		s.use(a.Disc)
+		s.Extra = true
+		return row, nil
+
+	default: // DiscDouble
+		row, _ := drop(s, a.Column, player)
+		s.use(a.Disc)
+		if !s.connects(row, a.Column, player, 4) {
+			s.Extra = true
+		}
+		return row, nil
+	}
+}
+
+func (r powerUpRules) Terminal(s *State) bool {
+	return s.Winner != Empty || len(r.LegalMoves(s)) == 0
+}
+
+func (powerUpRules) Winner(s *State) (Cell, [][2]int) {
+	return s.Winner, s.WinningCells
+}
+
+func (powerUpRules) Encode(s *State) ([]byte, error) {
+	return encodeState(s)
+}
+
+func (powerUpRules) Decode(data []byte) (*State, error) {
+	return decodeState(data, Rows, Columns)
+}
+
+// canUse returns why the player to move can't drop disc now, nil if they
+// can
+func (s *State) canUse(disc Disc) error {
+	known := false
+	for _, d := range powerUpDiscs {
+		known = known || d == disc
+	}
+	if !known {
+		return fmt.Errorf("unknown disc %q", disc)
+	}
+	if s.Extra {
+		return errExtraDrop
+	}
+	for _, d := range s.Specials[s.Turn] {
+		if d == disc {
+			return nil
+		}
+	}
+	return fmt.Errorf("you have no %s disc left", disc)
+}
+
+// use takes disc from the specials the player to move has left
+func (s *State) use(disc Disc) {
+	left := s.Specials[s.Turn][:0:0]
+	for _, d := range s.Specials[s.Turn] {
+		if d != disc {
+			left = append(left, d)
+		}
+	}
+	s.Specials[s.Turn] = left
+}
@@ -0,0 +1,92 @@
+package game
+
+import "testing"
+
+func TestPowerUpAnvil(t *testing.T) {
+	s := PowerUp.NewState()
+	apply(t, PowerUp, s, drops(2, 2, 3)...)
+	apply(t, PowerUp, s, Action{Column: 2, Disc: DiscAnvil})
+	if s.Grid.GetCell(5, 2) != Player2 || s.Grid.GetCell(4, 2) != Empty {
+		t.Errorf("The anvil should clear the column and sit at the bottom: %v", s.Grid.ToSlice())
+	}
+	if s.Turn != Player1 {
+		t.Error("The anvil ends the turn")
+	}
+	if _, err := PowerUp.Apply(s, Action{Column: 0, Disc: DiscAnvil}); err != nil {
+		t.Errorf("Player1 still has an anvil: %v", err)
+	}
+	s.Turn = Player2
+	if _, err := PowerUp.Apply(s, Action{Column: 0, Disc: DiscAnvil}); err == nil {
+		t.Error("Each anvil can only be used once")
+	}
+}
+
+func TestPowerUpBomb(t *testing.T) {
+	s := PowerUp.NewState()
+	if _, err := PowerUp.Apply(s, Action{Column: 3, Disc: DiscBomb}); err != errBombEmpty {
+		t.Errorf("Bombing an empty column: %v, want %v", err, errBombEmpty)
+	}
+	apply(t, PowerUp, s, drops(3, 3)...)
+	apply(t, PowerUp, s, Action{Column: 3, Disc: DiscBomb})
+	if s.Grid.GetCell(4, 3) != Empty || s.Grid.GetCell(5, 3) != Player1 {
+		t.Errorf("The bomb should take out the top disc only: %v", s.Grid.ToSlice())
+	}
+	if s.Turn != Player2 {
+		t.Error("The bomb ends the turn")
+	}
+}
+
+func TestPowerUpExtraDrops(t *testing.T) {
+	s := PowerUp.NewState()
+	apply(t, PowerUp, s, Action{Column: 0, Disc: DiscWall})
+	if s.Turn != Player1 || !s.Extra || s.Grid.GetCell(5, 0) != Wall {
+		t.Fatal("A wall should give its player an extra drop")
+	}
+	if _, err := PowerUp.Apply(s, Action{Column: 1, Disc: DiscDouble}); err != errExtraDrop {
+		t.Errorf("Special disc as the extra drop: %v, want %v", err, errExtraDrop)
+	}
+	for _, m := range PowerUp.LegalMoves(s) {
+		if m.Disc != "" {
+			t.Errorf("Extra drop listed a special disc: %+v", m)
+		}
+	}
+	apply(t, PowerUp, s, Action{Column: 1})
+	if s.Turn != Player2 || s.Extra {
+		t.Error("The extra drop ends the turn")
+	}
+
+	apply(t, PowerUp, s, Action{Column: 6, Disc: DiscDouble})
+	if s.Turn != Player2 || s.Grid.GetCell(5, 6) != Player2 {
+		t.Error("A double disc is the player's own and gives an extra drop")
+	}
+}
+
+func TestPowerUpWallBreaksLines(t *testing.T) {
+	s := PowerUp.NewState()
+	s.Grid = gridFrom(t,
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+		"11W....",
+	)
+	apply(t, PowerUp, s, Action{Column: 3})
+	if PowerUp.Terminal(s) {
+		t.Error("A wall can't be part of a line")
+	}
+
+	s.Grid = gridFrom(t,
+		".......",
+		".......",
+		".......",
+		".......",
+		".......",
+		"111....",
+	)
+	s.Turn = Player1
+	apply(t, PowerUp, s, Action{Column: 3, Disc: DiscDouble})
+	if winner, _ := PowerUp.Winner(s); winner != Player1 || s.Extra {
+		t.Error("A double disc can win, which ends the game without an extra drop")
+	}
+}
@@ -0,0 +1,369 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Variant names the rules a two-player game is played by
+type Variant string
+
+const (
+	// Drop discs until someone connects four or the board is full
+	VariantClassic Variant = "classic"
+	// A move may also pop one of your own discs from the bottom of a
+	// column instead of dropping one
+	VariantPopOut Variant = "popout"
+	// Fill the board, then pop discs out: the first to set aside ten discs
+	// that were part of a line of four wins
+	VariantPop10 Variant = "pop10"
+	// Connect five on a 9x6 board whose outer columns start full
+	VariantFiveInARow Variant = "five_in_a_row"
+	// Classic, plus one anvil, bomb, wall and double disc for each player
+	VariantPowerUp Variant = "power_up"
+)
+
+// rulesets are the rules games can be played by, by name
+var rulesets = map[Variant]Ruleset{
+	VariantClassic:    Classic,
+	VariantPopOut:     PopOut,
+	VariantPop10:      Pop10,
+	VariantFiveInARow: FiveInARow,
+	VariantPowerUp:    PowerUp,
+}
+
+// Setup is what a two-player game is played by: a ruleset and whether the
+// board is a cylinder
+type Setup struct {
+	Variant  Variant
+	Cylinder bool
+}
+
+// Key names the setup for matchmaking, which only pairs players who asked
+// for the same one
+func (s Setup) Key() string {
+	if s.Cylinder {
+		return string(s.Variant) + "/cylinder"
+	}
+	return string(s.Variant)
+}
+
+// RulesFor returns the ruleset named v
+func RulesFor(v Variant) (Ruleset, bool) {
+	r, ok := rulesets[v]
+	return r, ok
+}
+
+// Valid reports whether v names a known ruleset
+func (v Variant) Valid() bool {
+	_, ok := rulesets[v]
+	return ok
+}
+
+// MoveType tells a drop from a pop
+type MoveType string
+
+const (
+	MoveDrop MoveType = "drop"
+	MovePop  MoveType = "pop"
+)
+
+// Action is a move as a ruleset sees it: a drop or a pop in Column, with
+// Disc set when Power Up's special discs are dropped
+type Action struct {
+	Type   MoveType `json:"type,omitempty"`
+	Column int      `json:"column"`
+	Disc   Disc     `json:"disc,omitempty"`
+}
+
+// Ruleset is the rules of a two-player game: which moves are legal, what
+// they do and how the game ends. Rulesets keep no state of their own, so
+// one value serves every game played by it.
+type Ruleset interface {
+	// Name is how clients, the queue and game records refer to the rules
+	Name() Variant
+	// Connect is how many discs in a row make a line
+	Connect() int
+	// NewState returns the position games start from, Player1 to move
+	NewState() *State
+	// LegalMoves lists what the player to move may play, nothing once the
+	// game is over
+	LegalMoves(s *State) []Action
+	// Apply plays a for the player to move and returns the row the disc
+	// landed on or was taken from. s is left alone if a is illegal.
+	Apply(s *State, a Action) (int, error)
+	// Terminal reports whether the game is over
+	Terminal(s *State) bool
+	// Winner returns who won and, if they won with one, their line; Empty
+	// while the game goes on or if it was drawn
+	Winner(s *State) (Cell, [][2]int)
+	// Encode and Decode store a position and read it back
+	Encode(s *State) ([]byte, error)
+	Decode(data []byte) (*State, error)
+}
+
+// State is a position under some ruleset. The fields after Drawn belong
+// to particular rulesets and stay empty under the others.
+//
+// On a cylinder the grid's first and last columns are neighbours, so
+// rows and diagonals can wrap around from one side to the other. Every
+// ruleset can be played that way.
+type State struct {
+	Grid     *Grid `json:"grid"`
+	Turn     Cell  `json:"turn"`               // the player to move
+	Cylinder bool  `json:"cylinder,omitempty"` // the left and right edges meet
+
+	// Set once the game is won, or drawn by a rule before the board fills
+	Winner       Cell     `json:"winner,omitempty"`
+	WinningCells [][2]int `json:"winningCells,omitempty"`
+	Drawn        bool     `json:"drawn,omitempty"`
+
+	// PopOut: how often each position has come up, for repetition draws
+	Positions map[string]int `json:"positions,omitempty"`
+
+	// Pop 10: whether the board has been filled, ending the setup; the
+	// discs each player has set aside; and, after a pop that didn't score,
+	// the column the disc came from while it waits to be dropped back
+	Filled   bool         `json:"filled,omitempty"`
+	Captured map[Cell]int `json:"captured,omitempty"`
+	Holding  bool         `json:"holding,omitempty"`
+	HeldFrom int          `json:"heldFrom,omitempty"`
+
+	// Power Up: the special discs each player has left, and whether the
+	// player to move is taking the extra drop a wall or double disc gave
+	Specials map[Cell][]Disc `json:"specials,omitempty"`
+	Extra    bool            `json:"extra,omitempty"`
+}
+
+// newState creates an empty position with Player1 to move
+func newState(rows, columns int) *State {
+	return &State{Grid: NewGrid(rows, columns), Turn: Player1}
+}
+
+// Clone creates a deep copy of the state for simulation
+func (s *State) Clone() *State {
+	clone := *s
+	clone.Grid = s.Grid.Clone()
+	clone.WinningCells = append([][2]int(nil), s.WinningCells...)
+	if s.Positions != nil {
+		clone.Positions = make(map[string]int, len(s.Positions))
+		for k, v := range s.Positions {
+			clone.Positions[k] = v
+		}
+	}
+	if s.Captured != nil {
+		clone.Captured = make(map[Cell]int, len(s.Captured))
+		for k, v := range s.Captured {
+			clone.Captured[k] = v
+		}
+	}
+	if s.Specials != nil {
+		clone.Specials = make(map[Cell][]Disc, len(s.Specials))
+		for k, v := range s.Specials {
+			clone.Specials[k] = append([]Disc(nil), v...)
+		}
+	}
+	return &clone
+}
+
+// pass gives the turn to the other player
+func (s *State) pass() {
+	s.Turn = opponent(s.Turn)
+}
+
+// connects records player's win if the disc at (row, col) is part of a
+// line of connect discs, and reports whether it is
+func (s *State) connects(row, col int, player Cell, connect int) bool {
+	won, cells := checkWin(s.Grid, row, col, player, connect, s.Cylinder)
+	if won {
+		s.Winner = player
+		s.WinningCells = cells
+	}
+	return won
+}
+
+// opponent returns the other player of a two-player game
+func opponent(player Cell) Cell {
+	if player == Player1 {
+		return Player2
+	}
+	return Player1
+}
+
+// Legal reports whether r allows a in s
+func Legal(r Ruleset, s *State, a Action) bool {
+	_, err := r.Apply(s.Clone(), a)
+	return err == nil
+}
+
+// Errors rulesets give for moves they don't allow
+var (
+	errInvalidColumn = errors.New("invalid column")
+	errColumnFull    = errors.New("column is full")
+	errUnknownMove   = errors.New("unknown move type")
+	errNoPops        = errors.New("popping isn't allowed in this game")
+	errNoSpecials    = errors.New("special discs are only allowed in Power Up")
+	errPopOwn        = errors.New("you can only pop your own disc from the bottom")
+)
+
+// plainDrop returns the error for an action that isn't an ordinary drop,
+// for rulesets that are playing only those
+func plainDrop(a Action) error {
+	switch {
+	case a.Type == MovePop:
+		return errNoPops
+	case a.Type != "" && a.Type != MoveDrop:
+		return errUnknownMove
+	case a.Disc != "":
+		return errNoSpecials
+	}
+	return nil
+}
+
+// drop drops disc into col and returns the row it landed on
+func drop(s *State, col int, disc Cell) (int, error) {
+	if col < 0 || col >= s.Grid.Columns {
+		return -1, errInvalidColumn
+	}
+	row := s.Grid.DropDisc(col, disc)
+	if row == -1 {
+		return -1, errColumnFull
+	}
+	return row, nil
+}
+
+// encodeState stores a position as JSON
+func encodeState(s *State) ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// decodeState reads a position stored by encodeState, checking it was
+// played on a rows x columns board
+func decodeState(data []byte, rows, columns int) (*State, error) {
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if s.Grid == nil || s.Grid.Rows != rows || s.Grid.Columns != columns {
+		return nil, fmt.Errorf("position isn't on a %dx%d board", columns, rows)
+	}
+	if s.Turn != Player1 && s.Turn != Player2 {
+		return nil, fmt.Errorf("invalid player to move %d", s.Turn)
+	}
+	return &s, nil
+}
+
+// checkWin checks if the disc at (row, col) is part of a line of connect
+// discs for player; on a cylinder, lines may run off one side of the grid
+// and on at the other. Returns true and the line's cells if it is.
+func checkWin(grid *Grid, row, col int, player Cell, connect int, cylinder bool) (bool, [][2]int) {
+	directions := [][2]int{
+		{0, 1},  // Horizontal
+		{1, 0},  // Vertical
+		{1, 1},  // Diagonal (down-right)
+		{1, -1}, // Diagonal (down-left)
+	}
+
+	for _, dir := range directions {
+		cells := countLine(grid, row, col, dir[0], dir[1], player, connect, cylinder)
+		if len(cells) >= connect {
+			return true, cells
+		}
+	}
+
+	return false, nil
+}
+
+// countLine counts connected cells in both directions along a line. On a
+// cylinder, columns wrap around, and a line stops short of coming back to
+// a column it has already been through.
+func countLine(grid *Grid, row, col, dRow, dCol int, player Cell, connect int, cylinder bool) [][2]int {
+	cells := [][2]int{{row, col}}
+
+	for _, sign := range []int{1, -1} {
+		for i := 1; i < connect; i++ {
+			if cylinder && len(cells) == grid.Columns && dCol != 0 {
+				break
+			}
+			r, c := row+sign*dRow*i, col+sign*dCol*i
+			if cylinder {
+				c = (c%grid.Columns + grid.Columns) % grid.Columns
+			}
+			if r < 0 || r >= grid.Rows || c < 0 || c >= grid.Columns {
+				break
+			}
+			if grid.GetCell(r, c) != player {
+				break
+			}
+			cells = append(cells, [2]int{r, c})
+		}
+	}
+
+	return cells
+}
+
+// connectRules drops discs until someone gets connect in a row or the
+// board fills up: the classic rules, on a board of any size
+type connectRules struct {
+	name    Variant
+	rows    int
+	columns int
+	connect int
+	setup   func(*Grid) // places any discs the game starts with
+}
+
+// Classic is the standard game: four in a row on the 7x6 board
+var Classic Ruleset = &connectRules{name: VariantClassic, rows: Rows, columns: Columns, connect: 4}
+
+func (r connectRules) Name() Variant { return r.name }
+func (r connectRules) Connect() int  { return r.connect }
+
+func (r connectRules) NewState() *State {
+	s := newState(r.rows, r.columns)
+	if r.setup != nil {
+		r.setup(s.Grid)
+	}
+	return s
+}
+
+func (r connectRules) LegalMoves(s *State) []Action {
+	if r.Terminal(s) {
+		return nil
+	}
+	var moves []Action
+	for _, col := range s.Grid.ValidColumns() {
+		moves = append(moves, Action{Column: col})
+	}
+	return moves
+}
+
+func (r connectRules) Apply(s *State, a Action) (int, error) {
+	if err := plainDrop(a); err != nil {
+		return -1, err
+	}
+	row, err := drop(s, a.Column, s.Turn)
+	if err != nil {
+		return -1, err
+	}
+	if !s.connects(row, a.Column, s.Turn, r.connect) {
+		s.pass()
+	}
+	return row, nil
+}
+
+func (r connectRules) Terminal(s *State) bool {
+	return s.Winner != Empty || s.Grid.IsFull()
+}
+
+func (r connectRules) Winner(s *State) (Cell, [][2]int) {
+	return s.Winner, s.WinningCells
+}
+
+func (r connectRules) Encode(s *State) ([]byte, error) {
+	return encodeState(s)
+}
+
+func (r connectRules) Decode(data []byte) (*State, error) {
+	return decodeState(data, r.rows, r.columns)
+}
@@ -0,0 +1,199 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// gridFrom builds a grid from rows written top first, '.' for empty and
+// 'W' for a wall
+func gridFrom(t *testing.T, rows ...string) *Grid {
+	t.Helper()
+	g := NewGrid(len(rows), len(rows[0]))
+	for r, row := range rows {
+		for c, ch := range row {
+			switch ch {
+			case '.':
+			case 'W':
+				g.SetCell(r, c, Wall)
+			default:
+				g.SetCell(r, c, Cell(ch-'0'))
+			}
+		}
+	}
+	return g
+}
+
+// apply plays each action for whoever is to move
+func apply(t *testing.T, r Ruleset, s *State, actions ...Action) {
+	t.Helper()
+	for i, a := range actions {
+		if _, err := r.Apply(s, a); err != nil {
+			t.Fatalf("action %d %+v: %v", i, a, err)
+		}
+	}
+}
+
+func drops(cols ...int) []Action {
+	actions := make([]Action, len(cols))
+	for i, col := range cols {
+		actions[i] = Action{Column: col}
+	}
+	return actions
+}
+
+func TestClassicRuleset(t *testing.T) {
+	s := Classic.NewState()
+	if len(Classic.LegalMoves(s)) != Columns {
+		t.Fatalf("Empty board should allow %d drops", Columns)
+	}
+
+	// Player1 stacks column 3 while Player2 plays column 4
+	apply(t, Classic, s, drops(3, 4, 3, 4, 3, 4)...)
+	if Classic.Terminal(s) {
+		t.Fatal("Game shouldn't be over yet")
+	}
+	if _, err := Classic.Apply(s, Action{Type: MovePop, Column: 3}); err == nil {
+		t.Error("Classic should refuse pops")
+	}
+	if _, err := Classic.Apply(s, Action{Column: 0, Disc: DiscAnvil}); err == nil {
+		t.Error("Classic should refuse special discs")
+	}
+
+	apply(t, Classic, s, Action{Column: 3})
+	winner, cells := Classic.Winner(s)
+	if !Classic.Terminal(s) || winner != Player1 || len(cells) != 4 {
+		t.Errorf("Winner %d with %v, want Player1 with four cells", winner, cells)
+	}
+	if len(Classic.LegalMoves(s)) != 0 {
+		t.Error("A finished game has no legal moves")
+	}
+}
+
+func TestApplyLeavesStateOnError(t *testing.T) {
+	for _, r := range rulesets {
+		s := r.NewState()
+		before, _ := r.Encode(s)
+		for _, a := range []Action{{Column: -1}, {Column: 99}, {Type: "slide", Column: 0}, {Column: 0, Disc: "laser"}} {
+			if _, err := r.Apply(s, a); err == nil {
+				t.Errorf("%s: %+v should be refused", r.Name(), a)
+			}
+		}
+		if after, _ := r.Encode(s); string(after) != string(before) {
+			t.Errorf("%s: refused moves changed the state", r.Name())
+		}
+	}
+}
+
+func TestRulesetEncodeDecode(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, r := range rulesets {
+		s := r.NewState()
+		for i := 0; i < 12 && !r.Terminal(s); i++ {
+			moves := r.LegalMoves(s)
+			apply(t, r, s, moves[rng.Intn(len(moves))])
+		}
+
+		data, err := r.Encode(s)
+		if err != nil {
+			t.Fatalf("%s: Encode: %v", r.Name(), err)
+		}
+		decoded, err := r.Decode(data)
+		if err != nil {
+			t.Fatalf("%s: Decode: %v", r.Name(), err)
+		}
+		if decoded.Grid.Encode() != s.Grid.Encode() || decoded.Turn != s.Turn {
+			t.Errorf("%s: decoded position differs", r.Name())
+		}
+		if len(r.LegalMoves(decoded)) != len(r.LegalMoves(s)) {
+			t.Errorf("%s: decoded position allows different moves", r.Name())
+		}
+	}
+
+	data, _ := Classic.Encode(FiveInARow.NewState())
+	if _, err := Classic.Decode(data); err == nil {
+		t.Error("Classic should refuse a 9x6 position")
+	}
+}
+
+func TestRulesetsPlayRandomGames(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for _, r := range rulesets {
+		for game := 0; game < 20; game++ {
+			s := r.NewState()
+			for ply := 0; ply < 500 && !r.Terminal(s); ply++ {
+				moves := r.LegalMoves(s)
+				if len(moves) == 0 {
+					t.Fatalf("%s: no legal moves in an unfinished game", r.Name())
+				}
+				for _, m := range moves {
+					if !Legal(r, s, m) {
+						t.Fatalf("%s: listed move %+v is refused", r.Name(), m)
+					}
+				}
+				apply(t, r, s, moves[rng.Intn(len(moves))])
+			}
+		}
+	}
+}
+
+func TestVariantGameUndo(t *testing.T) {
+	g := NewVariantGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"}, VariantPowerUp)
+	g.MakeMove(Player1, 2)
+	g.MakeMove(Player2, 2)
+	if _, err := g.Play(Player1, Action{Column: 2, Disc: DiscAnvil}); err != "" {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if g.Board.GetCell(Rows-2, 2) != Empty {
+		t.Fatal("The anvil should have cleared the column")
+	}
+
+	if _, ok := g.UndoTurn(Player1); !ok {
+		t.Fatal("UndoTurn should take the anvil back")
+	}
+	if g.Board.GetCell(Rows-1, 2) != Player1 || g.Board.GetCell(Rows-2, 2) != Player2 || g.CurrentTurn != Player1 {
+		t.Errorf("UndoTurn didn't restore the column: %v", g.Board.ToSlice())
+	}
+	if _, err := g.Play(Player1, Action{Column: 4, Disc: DiscAnvil}); err != "" {
+		t.Errorf("The anvil should be usable again after the takeback: %s", err)
+	}
+}
+
+func TestUndoTurnSeveralMoves(t *testing.T) {
+	g := NewVariantGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"}, VariantPowerUp)
+	if _, ok := g.UndoTurn(Player1); ok {
+		t.Error("UndoTurn should fail with no moves")
+	}
+
+	g.MakeMove(Player1, 0)
+	// Player2's double disc gives them a second drop in the same turn
+	if _, err := g.Play(Player2, Action{Column: 1, Disc: DiscDouble}); err != "" {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	g.MakeMove(Player2, 2)
+	g.MakeMove(Player1, 3)
+
+	if n, ok := g.UndoTurn(Player2); !ok || n != 3 {
+		t.Fatalf("UndoTurn(Player2) = %d, %v; want both drops of the turn and the reply", n, ok)
+	}
+	if len(g.Moves) != 1 || g.CurrentTurn != Player2 || g.Board.GetCell(Rows-1, 1) != Empty {
+		t.Errorf("Player2 should be back to the start of their turn: moves %d, turn %d", len(g.Moves), g.CurrentTurn)
+	}
+	if _, err := g.Play(Player2, Action{Column: 5, Disc: DiscDouble}); err != "" {
+		t.Errorf("The double disc should be usable again after the takeback: %s", err)
+	}
+
+	if n, ok := g.UndoTurn(Player1); !ok || n != 2 || len(g.Moves) != 0 || g.CurrentTurn != Player1 {
+		t.Errorf("UndoTurn(Player1) = %d, %v with %d moves left", n, ok, len(g.Moves))
+	}
+}
+
+func TestNewVariantGameUnknown(t *testing.T) {
+	g := NewVariantGame(&PlayerInfo{Username: "a"}, &PlayerInfo{Username: "b"}, "hexagonal")
+	if g.Variant != VariantClassic || g.Rules != Classic {
+		t.Errorf("Unknown variant played as %s", g.Variant)
+	}
+	if Variant("hexagonal").Valid() || !VariantPop10.Valid() {
+		t.Error("Valid should know the registered rulesets only")
+	}
+}
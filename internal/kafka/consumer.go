@@ -9,6 +9,9 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Consumer handles consuming events from Kafka for analytics
@@ -64,7 +67,7 @@ func (c *Consumer) Close() error {
 }
 
 // Start starts consuming messages and processes them with the handler
-func (c *Consumer) Start(ctx context.Context, handler func(GameEvent)) {
+func (c *Consumer) Start(ctx context.Context, handler func(context.Context, GameEvent)) {
 	if !c.enabled {
 		return
 	}
@@ -86,20 +89,32 @@ func (c *Consumer) Start(ctx context.Context, handler func(GameEvent)) {
 				continue
 			}
 
+			// Continue the producer's trace from the message headers
+			msgCtx := otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &msg.Headers})
+
 			var event GameEvent
 			if err := json.Unmarshal(msg.Value, &event); err != nil {
 				log.Error().Err(err).Msg("Error unmarshaling event")
 				continue
 			}
 
-			handler(event)
+			msgCtx, span := tracer.Start(msgCtx, "kafka.consume "+string(event.Type),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "kafka"),
+					attribute.String("messaging.destination.name", msg.Topic),
+					attribute.String("messaging.message.id", event.ID),
+				),
+			)
+			handler(msgCtx, event)
+			span.End()
 		}
 	}
 }
 
 // ProcessEvent is a handler that processes analytics events from Kafka
 // In production, this would aggregate metrics and store to database
-func ProcessEvent(event GameEvent) {
+func ProcessEvent(ctx context.Context, event GameEvent) {
 	switch event.Type {
 	case EventGameStarted:
 		log.Info().
@@ -125,6 +140,7 @@ func ProcessEvent(event GameEvent) {
 			Str("gameId", event.Data["gameId"].(string)).
 			Str("winner", event.Data["winner"].(string)).
 			Str("result", event.Data["result"].(string)).
+			Interface("duration", event.Data["duration"]).
 			Interface("totalMoves", event.Data["totalMoves"]).
 			Msg("Analytics: Game ended")
 
@@ -141,6 +157,31 @@ func ProcessEvent(event GameEvent) {
 			Interface("waitDuration", event.Data["waitDuration"]).
 			Msg("Analytics: Matchmaking timeout")
 
+	case EventPlayerReconnected:
+		log.Info().
+			Str("type", string(event.Type)).
+			Str("username", event.Data["username"].(string)).
+			Str("gameId", event.Data["gameId"].(string)).
+			Interface("offlineDuration", event.Data["offlineDuration"]).
+			Msg("Analytics: Player reconnected")
+
+	case EventSessionAbandoned:
+		log.Info().
+			Str("type", string(event.Type)).
+			Str("gameId", event.Data["gameId"].(string)).
+			Str("username", event.Data["username"].(string)).
+			Interface("totalMoves", event.Data["totalMoves"]).
+			Msg("Analytics: Session abandoned")
+
+	case EventInvalidMove:
+		log.Info().
+			Str("type", string(event.Type)).
+			Str("gameId", event.Data["gameId"].(string)).
+			Str("player", event.Data["player"].(string)).
+			Interface("column", event.Data["column"]).
+			Str("reason", event.Data["reason"].(string)).
+			Msg("Analytics: Invalid move")
+
 	default:
 		log.Debug().
 			Str("type", string(event.Type)).
@@ -0,0 +1,41 @@
+package kafka
+
+import (
+	"github.com/segmentio/kafka-go"
+)
+
+// headerCarrier adapts Kafka message headers to the OpenTelemetry
+// TextMapCarrier interface so trace context travels with each event
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+// Get returns the value for a header key
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set stores a header, replacing any existing value for the key
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys lists all header keys
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
@@ -10,8 +10,17 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 	"github.com/segmentio/kafka-go/sasl/plain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"connect-four/internal/metrics"
+	"connect-four/internal/telemetry"
 )
 
+var tracer = telemetry.Tracer("kafka")
+
 // EventType defines the types of events we publish
 type EventType string
 
@@ -22,6 +31,9 @@ const (
 	EventPlayerConnected    EventType = "player.connected"
 	EventPlayerDisconnected EventType = "player.disconnected"
 	EventMatchmakingTimeout EventType = "matchmaking.timeout"
+	EventPlayerReconnected  EventType = "player.reconnected"
+	EventSessionAbandoned   EventType = "session.abandoned"
+	EventInvalidMove        EventType = "game.invalid_move"
 )
 
 // GameEvent represents an event to be published
@@ -110,18 +122,39 @@ func (p *Producer) Publish(ctx context.Context, event GameEvent) error {
 	event.ID = uuid.New().String()
 	event.Timestamp = time.Now()
 
+	ctx, span := tracer.Start(ctx, "kafka.publish "+string(event.Type),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", p.writer.Topic),
+			attribute.String("messaging.message.id", event.ID),
+		),
+	)
+	defer span.End()
+
 	data, err := json.Marshal(event)
 	if err != nil {
+		metrics.KafkaPublishFailures.WithLabelValues(string(event.Type)).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "marshal failed")
 		log.Error().Err(err).Msg("Failed to marshal event")
 		return err
 	}
 
+	// Carry the trace context in the message headers for the consumer
+	var headers []kafka.Header
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &headers})
+
 	err = p.writer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(event.ID),
-		Value: data,
+		Key:     []byte(event.ID),
+		Value:   data,
+		Headers: headers,
 	})
 
 	if err != nil {
+		metrics.KafkaPublishFailures.WithLabelValues(string(event.Type)).Inc()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "publish failed")
 		log.Error().Err(err).Str("type", string(event.Type)).Msg("Failed to publish event")
 		return err
 	}
@@ -204,3 +237,40 @@ func (p *Producer) PublishMatchmakingTimeout(ctx context.Context, username strin
 		},
 	})
 }
+
+// PublishPlayerReconnected publishes a player reconnected event
+func (p *Producer) PublishPlayerReconnected(ctx context.Context, username string, gameID uuid.UUID, offlineFor time.Duration) {
+	p.Publish(ctx, GameEvent{
+		Type: EventPlayerReconnected,
+		Data: map[string]interface{}{
+			"username":        username,
+			"gameId":          gameID.String(),
+			"offlineDuration": offlineFor.Seconds(),
+		},
+	})
+}
+
+// PublishSessionAbandoned publishes a session abandoned event
+func (p *Producer) PublishSessionAbandoned(ctx context.Context, gameID uuid.UUID, username string, moveCount int) {
+	p.Publish(ctx, GameEvent{
+		Type: EventSessionAbandoned,
+		Data: map[string]interface{}{
+			"gameId":     gameID.String(),
+			"username":   username,
+			"totalMoves": moveCount,
+		},
+	})
+}
+
+// PublishInvalidMove publishes a rejected move attempt
+func (p *Producer) PublishInvalidMove(ctx context.Context, gameID uuid.UUID, player string, column int, reason string) {
+	p.Publish(ctx, GameEvent{
+		Type: EventInvalidMove,
+		Data: map[string]interface{}{
+			"gameId": gameID.String(),
+			"player": player,
+			"column": column,
+			"reason": reason,
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package matchmaking
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Table is the kind of multiplayer game a lobby fills: how many seats and
+// which rule. Players only share a lobby with others asking for the same
+// table.
+type Table struct {
+	Seats int    `json:"seats"`
+	Rule  string `json:"rule"`
+}
+
+// Lobby is a multiplayer game waiting for its seats to fill
+type Lobby struct {
+	Table    Table
+	Players  []string // in join order
+	OpenedAt time.Time
+}
+
+// Lobbies fills N-seat games, one lobby per table at a time. A lobby that
+// is full, or still has empty seats once the timeout has passed since its
+// first player joined, is handed to onStart; bots take the empty seats.
+type Lobbies struct {
+	mu       sync.Mutex
+	timeout  time.Duration
+	lobbies  map[Table]*Lobby
+	players  map[string]Table
+	onStart  func(lobby Lobby, bots int)
+	stopChan chan struct{}
+}
+
+// NewLobbies creates the lobbies for multiplayer games; set OnStart before
+// anyone joins
+func NewLobbies(timeout time.Duration) *Lobbies {
+	return &Lobbies{
+		timeout:  timeout,
+		lobbies:  make(map[Table]*Lobby),
+		players:  make(map[string]Table),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// OnStart registers the callback that starts a filled lobby's game, with
+// bots in the seats left empty. It runs on its own goroutine.
+func (l *Lobbies) OnStart(fn func(lobby Lobby, bots int)) {
+	l.onStart = fn
+}
+
+// Start begins backfilling lobbies that wait too long
+func (l *Lobbies) Start() {
+	go l.checkTimeouts()
+}
+
+// Stop halts the backfill loop
+func (l *Lobbies) Stop() {
+	close(l.stopChan)
+}
+
+// Join seats a player in the table's lobby and returns the lobby as it is
+// now. Joining the last seat starts the game. Returns false if the player
+// is already waiting in a lobby.
+func (l *Lobbies) Join(username string, table Table) (Lobby, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, waiting := l.players[username]; waiting {
+		return Lobby{}, false
+	}
+	lobby, ok := l.lobbies[table]
+	if !ok {
+		lobby = &Lobby{Table: table, OpenedAt: time.Now()}
+		l.lobbies[table] = lobby
+	}
+	lobby.Players = append(lobby.Players, username)
+	l.players[username] = table
+
+	snapshot := copyLobby(lobby)
+	if len(lobby.Players) >= table.Seats {
+		l.startLocked(lobby, 0)
+	}
+	log.Info().Str("username", username).Int("seats", table.Seats).Str("rule", table.Rule).Int("waiting", len(snapshot.Players)).Msg("Player joined lobby")
+	return snapshot, true
+}
+
+// Leave takes a player out of their lobby and returns the lobby as it is
+// afterwards. Returns false if they weren't waiting.
+func (l *Lobbies) Leave(username string) (Lobby, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	table, ok := l.players[username]
+	if !ok {
+		return Lobby{}, false
+	}
+	delete(l.players, username)
+	lobby := l.lobbies[table]
+	for i, p := range lobby.Players {
+		if p == username {
+			lobby.Players = append(lobby.Players[:i], lobby.Players[i+1:]...)
+			break
+		}
+	}
+	if len(lobby.Players) == 0 {
+		delete(l.lobbies, table)
+	}
+	log.Info().Str("username", username).Msg("Player left lobby")
+	return copyLobby(lobby), true
+}
+
+// Waiting returns the lobby a player is waiting in
+func (l *Lobbies) Waiting(username string) (Lobby, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	table, ok := l.players[username]
+	if !ok {
+		return Lobby{}, false
+	}
+	return copyLobby(l.lobbies[table]), true
+}
+
+// startLocked closes a lobby and hands it over. Caller must hold l.mu.
+func (l *Lobbies) startLocked(lobby *Lobby, bots int) {
+	delete(l.lobbies, lobby.Table)
+	for _, p := range lobby.Players {
+		delete(l.players, p)
+	}
+	log.Info().Int("seats", lobby.Table.Seats).Int("players", len(lobby.Players)).Int("bots", bots).Msg("Lobby filled")
+	go l.onStart(copyLobby(lobby), bots)
+}
+
+// checkTimeouts periodically backfills lobbies that have waited too long
+func (l *Lobbies) checkTimeouts() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stopChan:
+			return
+
+		case <-ticker.C:
+			l.processTimeouts()
+		}
+	}
+}
+
+// processTimeouts fills the empty seats of every lobby past the timeout
+// with bots
+func (l *Lobbies) processTimeouts() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for _, lobby := range l.lobbies {
+		if now.Sub(lobby.OpenedAt) >= l.timeout {
+			l.startLocked(lobby, lobby.Table.Seats-len(lobby.Players))
+		}
+	}
+}
+
+func copyLobby(lobby *Lobby) Lobby {
+	c := *lobby
+	c.Players = append([]string(nil), lobby.Players...)
+	return c
+}
@@ -0,0 +1,73 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+type started struct {
+	lobby Lobby
+	bots  int
+}
+
+func newTestLobbies(timeout time.Duration) (*Lobbies, chan started) {
+	starts := make(chan started, 4)
+	l := NewLobbies(timeout)
+	l.OnStart(func(lobby Lobby, bots int) { starts <- started{lobby, bots} })
+	return l, starts
+}
+
+func TestLobbyFills(t *testing.T) {
+	l, starts := newTestLobbies(time.Minute)
+	three := Table{Seats: 3, Rule: "elimination"}
+	four := Table{Seats: 4, Rule: "elimination"}
+
+	l.Join("alice", three)
+	l.Join("bob", four)
+	if lobby, _ := l.Join("carol", three); len(lobby.Players) != 2 {
+		t.Fatalf("different tables shouldn't share a lobby: %v", lobby.Players)
+	}
+	if _, ok := l.Join("carol", three); ok {
+		t.Error("a player can only wait in one lobby")
+	}
+
+	l.Join("dave", three)
+	select {
+	case s := <-starts:
+		if s.bots != 0 || len(s.lobby.Players) != 3 || s.lobby.Players[0] != "alice" {
+			t.Errorf("started %v with %d bots", s.lobby.Players, s.bots)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a full lobby should start")
+	}
+	if _, waiting := l.Waiting("alice"); waiting {
+		t.Error("players leave the lobby when the game starts")
+	}
+	if lobby, waiting := l.Waiting("bob"); !waiting || lobby.Table != four {
+		t.Error("bob should still be waiting for a 4-player game")
+	}
+}
+
+func TestLobbyLeaveAndBackfill(t *testing.T) {
+	l, starts := newTestLobbies(0)
+	table := Table{Seats: 4, Rule: "first_to_connect"}
+
+	l.Join("alice", table)
+	l.Join("bob", table)
+	if lobby, ok := l.Leave("alice"); !ok || len(lobby.Players) != 1 || lobby.Players[0] != "bob" {
+		t.Fatalf("Leave = %v, %v", lobby.Players, ok)
+	}
+	if _, ok := l.Leave("alice"); ok {
+		t.Error("alice already left")
+	}
+
+	l.processTimeouts()
+	select {
+	case s := <-starts:
+		if s.bots != 3 || len(s.lobby.Players) != 1 {
+			t.Errorf("started %v with %d bots, want bob and 3 bots", s.lobby.Players, s.bots)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a lobby past its timeout should be backfilled")
+	}
+}
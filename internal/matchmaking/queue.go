@@ -1,15 +1,32 @@
 package matchmaking
 
 import (
+	"context"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/kafka"
+	"connect-four/internal/metrics"
+)
+
+// Pool separates waiting players by who they agree to be matched with.
+// Players are only ever matched within their own pool.
+type Pool string
+
+const (
+	PoolHumans Pool = "humans" // human players only
+	PoolOpen   Pool = "open"   // bot accounts and the humans willing to play them
 )
 
-// Player represents a player waiting in the matchmaking queue
+// Player represents a player waiting in the matchmaking queue. Players are
+// only matched with others waiting for the same variant, rated or casual.
 type Player struct {
 	Username  string
+	Pool      Pool
+	Variant   string
+	Casual    bool
 	JoinedAt  time.Time
 	OnMatch   func(opponent *Player, isBotGame bool) // Callback when matched
 	OnTimeout func()                                 // Callback when bot assigned
@@ -23,16 +40,19 @@ type Queue struct {
 	addChan    chan *Player
 	removeChan chan string
 	stopChan   chan struct{}
+
+	kafkaProducer *kafka.Producer
 }
 
 // NewQueue creates a new matchmaking queue
-func NewQueue(timeout time.Duration) *Queue {
+func NewQueue(timeout time.Duration, kafkaProducer *kafka.Producer) *Queue {
 	return &Queue{
-		players:    make([]*Player, 0),
-		timeout:    timeout,
-		addChan:    make(chan *Player, 10),
-		removeChan: make(chan string, 10),
-		stopChan:   make(chan struct{}),
+		players:       make([]*Player, 0),
+		timeout:       timeout,
+		addChan:       make(chan *Player, 10),
+		removeChan:    make(chan string, 10),
+		stopChan:      make(chan struct{}),
+		kafkaProducer: kafkaProducer,
 	}
 }
 
@@ -47,10 +67,14 @@ func (q *Queue) Stop() {
 	close(q.stopChan)
 }
 
-// AddPlayer adds a player to the matchmaking queue
-func (q *Queue) AddPlayer(username string, onMatch func(*Player, bool), onTimeout func()) {
+// AddPlayer adds a player waiting for a rated or casual game of the
+// variant to a matchmaking pool
+func (q *Queue) AddPlayer(username string, pool Pool, variant string, casual bool, onMatch func(*Player, bool), onTimeout func()) {
 	player := &Player{
 		Username:  username,
+		Pool:      pool,
+		Variant:   variant,
+		Casual:    casual,
 		JoinedAt:  time.Now(),
 		OnMatch:   onMatch,
 		OnTimeout: onTimeout,
@@ -63,14 +87,29 @@ func (q *Queue) RemovePlayer(username string) {
 	q.removeChan <- username
 }
 
-// QueuePosition returns the player's position in queue (1-indexed)
+// QueuePosition returns the player's position among those waiting in the
+// same pool for the same kind of game (1-indexed)
 func (q *Queue) QueuePosition(username string) int {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	for i, p := range q.players {
+	var player *Player
+	for _, p := range q.players {
 		if p.Username == username {
-			return i + 1
+			player = p
+		}
+	}
+	if player == nil {
+		return 0
+	}
+	pos := 0
+	for _, p := range q.players {
+		if !p.matches(player) {
+			continue
+		}
+		pos++
+		if p == player {
+			return pos
 		}
 	}
 	return 0
@@ -105,16 +144,25 @@ func (q *Queue) handleAdd(player *Player) {
 		}
 	}
 
-	// If there's another player waiting, match them
-	if len(q.players) > 0 {
-		opponent := q.players[0]
-		q.players = q.players[1:]
+	// If there's another player waiting in the same pool for the same kind
+	// of game, match them
+	for i, opponent := range q.players {
+		if !opponent.matches(player) {
+			continue
+		}
+		q.players = append(q.players[:i], q.players[i+1:]...)
 
 		log.Info().
 			Str("player1", opponent.Username).
 			Str("player2", player.Username).
+			Str("pool", string(player.Pool)).
+			Str("variant", player.Variant).
+			Bool("casual", player.Casual).
 			Msg("Players matched")
 
+		q.updateSize()
+		metrics.QueueWait.WithLabelValues("matched").Observe(time.Since(opponent.JoinedAt).Seconds())
+
 		// Only call opponent's OnMatch callback to avoid calling startGame twice
 		// The opponent is player1 (arrived first), player is player2 (just arrived)
 		go opponent.OnMatch(player, false)
@@ -123,7 +171,24 @@ func (q *Queue) handleAdd(player *Player) {
 
 	// Otherwise, add to queue
 	q.players = append(q.players, player)
-	log.Info().Str("username", player.Username).Int("queueSize", len(q.players)).Msg("Player added to queue")
+	q.updateSize()
+	log.Info().Str("username", player.Username).Str("pool", string(player.Pool)).Int("queueSize", len(q.players)).Msg("Player added to queue")
+}
+
+// matches reports whether two waiting players may be matched
+func (p *Player) matches(other *Player) bool {
+	return p.Pool == other.Pool && p.Variant == other.Variant && p.Casual == other.Casual
+}
+
+// updateSize refreshes the per-pool queue gauges. Caller must hold q.mu.
+func (q *Queue) updateSize() {
+	sizes := map[Pool]int{PoolHumans: 0, PoolOpen: 0}
+	for _, p := range q.players {
+		sizes[p.Pool]++
+	}
+	for pool, n := range sizes {
+		metrics.QueueSize.WithLabelValues(string(pool)).Set(float64(n))
+	}
 }
 
 // handleRemove removes a player from the queue
@@ -134,6 +199,7 @@ func (q *Queue) handleRemove(username string) {
 	for i, p := range q.players {
 		if p.Username == username {
 			q.players = append(q.players[:i], q.players[i+1:]...)
+			q.updateSize()
 			log.Info().Str("username", username).Msg("Player removed from queue")
 			return
 		}
@@ -165,8 +231,12 @@ func (q *Queue) processTimeouts() {
 	var remaining []*Player
 
 	for _, p := range q.players {
-		if now.Sub(p.JoinedAt) >= q.timeout {
+		if waited := now.Sub(p.JoinedAt); waited >= q.timeout {
 			log.Info().Str("username", p.Username).Msg("Matchmaking timeout - assigning bot")
+			metrics.QueueWait.WithLabelValues("timeout").Observe(waited.Seconds())
+			if q.kafkaProducer != nil {
+				go q.kafkaProducer.PublishMatchmakingTimeout(context.Background(), p.Username, waited)
+			}
 			go p.OnTimeout()
 		} else {
 			remaining = append(remaining, p)
@@ -174,6 +244,37 @@ func (q *Queue) processTimeouts() {
 	}
 
 	q.players = remaining
+	q.updateSize()
+}
+
+// Entry is a snapshot of a waiting player
+type Entry struct {
+	Username string    `json:"username"`
+	Pool     Pool      `json:"pool"`
+	Variant  string    `json:"variant"`
+	Casual   bool      `json:"casual,omitempty"`
+	JoinedAt time.Time `json:"joinedAt"`
+	Waited   float64   `json:"waitedSeconds"`
+}
+
+// Snapshot returns the players currently waiting, in queue order
+func (q *Queue) Snapshot() []Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	entries := make([]Entry, 0, len(q.players))
+	for _, p := range q.players {
+		entries = append(entries, Entry{
+			Username: p.Username,
+			Pool:     p.Pool,
+			Variant:  p.Variant,
+			Casual:   p.Casual,
+			JoinedAt: p.JoinedAt,
+			Waited:   now.Sub(p.JoinedAt).Seconds(),
+		})
+	}
+	return entries
 }
 
 // Size returns the current queue size
@@ -0,0 +1,71 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolsAreSeparate(t *testing.T) {
+	q := NewQueue(time.Minute, nil)
+	matched := make(chan string, 4)
+	add := func(username string, pool Pool) {
+		q.handleAdd(&Player{
+			Username: username,
+			Pool:     pool,
+			JoinedAt: time.Now(),
+			OnMatch:  func(opponent *Player, _ bool) { matched <- username + "-" + opponent.Username },
+		})
+	}
+
+	add("alice", PoolHumans)
+	add("robot", PoolOpen)
+	if q.Size() != 2 {
+		t.Fatalf("players in different pools were matched")
+	}
+	if pos := q.QueuePosition("robot"); pos != 1 {
+		t.Errorf("position is counted per pool, got %d", pos)
+	}
+
+	add("bob", PoolOpen)
+	select {
+	case m := <-matched:
+		if m != "robot-bob" {
+			t.Errorf("matched %s, want robot-bob", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("players in the same pool weren't matched")
+	}
+	if q.Size() != 1 || q.QueuePosition("alice") != 1 {
+		t.Errorf("alice should still be waiting alone")
+	}
+}
+
+func TestVariantsAreSeparate(t *testing.T) {
+	q := NewQueue(time.Minute, nil)
+	matched := make(chan string, 4)
+	add := func(username, variant string) {
+		q.handleAdd(&Player{
+			Username: username,
+			Pool:     PoolHumans,
+			Variant:  variant,
+			JoinedAt: time.Now(),
+			OnMatch:  func(opponent *Player, _ bool) { matched <- username + "-" + opponent.Username },
+		})
+	}
+
+	add("alice", "classic")
+	add("bob", "popout")
+	if q.Size() != 2 {
+		t.Fatalf("players waiting for different variants were matched")
+	}
+
+	add("carol", "popout")
+	select {
+	case m := <-matched:
+		if m != "bob-carol" {
+			t.Errorf("matched %s, want bob-carol", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("players waiting for the same variant weren't matched")
+	}
+}
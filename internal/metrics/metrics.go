@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "connect_four"
+
+// Game type labels for ActiveGames
+const (
+	GameTypePvP   = "pvp"
+	GameTypeBot   = "bot"
+	GameTypeMulti = "multi" // three or four players
+)
+
+var (
+	// ConnectedClients tracks the number of WebSocket clients registered with the hub
+	ConnectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "connected_clients",
+		Help:      "Number of WebSocket clients currently connected.",
+	})
+
+	// ActiveGames tracks in-progress game sessions split by opponent type
+	ActiveGames = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_games",
+		Help:      "Number of active game sessions.",
+	}, []string{"type"})
+
+	// QueueSize tracks players waiting in each matchmaking pool
+	QueueSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "matchmaking_queue_size",
+		Help:      "Number of players waiting for a match.",
+	}, []string{"pool"})
+
+	// QueueWait records how long players waited before being matched or assigned a bot
+	QueueWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "matchmaking_wait_seconds",
+		Help:      "Time spent in the matchmaking queue.",
+		Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 15, 30, 60},
+	}, []string{"outcome"})
+
+	// MoveLatency records server-side processing time for a player move
+	MoveLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "move_latency_seconds",
+		Help:      "Time from receiving a move to broadcasting it.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25},
+	})
+
+	// BotThinkTime records how long the bot spends selecting a move
+	BotThinkTime = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "bot_think_seconds",
+		Help:      "Time the bot spends selecting a move, excluding the artificial delay.",
+		Buckets:   []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5},
+	})
+
+	// SendDropped counts messages dropped because a client's send channel was full
+	SendDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ws_send_dropped_total",
+		Help:      "Messages dropped because the client send channel was full.",
+	})
+
+	// KafkaPublishFailures counts events that failed to publish
+	KafkaPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_publish_failures_total",
+		Help:      "Kafka events that failed to marshal or publish.",
+	}, []string{"type"})
+
+	// HTTPRequestDuration records HTTP request latency by route template
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+)
@@ -9,13 +9,17 @@ import (
 
 // Player represents a user in the system (GORM model)
 type Player struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	Username  string    `gorm:"uniqueIndex;size:50;not null"`
-	Wins      int       `gorm:"default:0"`
-	Losses    int       `gorm:"default:0"`
-	Draws     int       `gorm:"default:0"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Username     string    `gorm:"uniqueIndex;size:50;not null"`
+	Wins         int       `gorm:"default:0"`
+	Losses       int       `gorm:"default:0"`
+	Draws        int       `gorm:"default:0"`
+	Rating       int       `gorm:"default:1200"`  // Elo, updated after rated games
+	PuzzleRating int       `gorm:"default:1200"`  // Elo against puzzles, first attempts only
+	IsBot        bool      `gorm:"default:false"` // bot account, connects with an API key
+	APIKeyHash   string    `gorm:"size:64;index" json:"-"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
 }
 
 // BeforeCreate generates UUID if not set
@@ -34,6 +38,7 @@ const (
 	GameResultPlayer2Win GameResultType = "player2"
 	GameResultDraw       GameResultType = "draw"
 	GameResultForfeit    GameResultType = "forfeit"
+	GameResultDrawAgreed GameResultType = "draw_agreed"
 )
 
 // GameRecord represents a game in the database (GORM model)
@@ -46,12 +51,122 @@ type GameRecord struct {
 	IsBotGame       bool           `gorm:"default:false"`
 	WinnerID        *uuid.UUID     `gorm:"type:uuid"`
 	Winner          *Player        `gorm:"foreignKey:WinnerID"`
-	Result          GameResultType `gorm:"size:10"`
+	Result          GameResultType `gorm:"size:20"`
 	Moves           string         `gorm:"type:jsonb;default:'[]'"`
 	DurationSeconds int            `gorm:"default:0"`
+	SeriesID        *uuid.UUID     `gorm:"type:uuid;index"`
+	BotPersonality  string         `gorm:"size:20"`       // built-in bot personality, empty otherwise
+	Variant         string         `gorm:"size:20"`       // rules played by; empty for classic games stored before variants
+	Cylinder        bool           `gorm:"default:false"` // played on a board whose edges meet
 	StartedAt       time.Time
 	EndedAt         *time.Time
 	CreatedAt       time.Time
+
+	// Correspondence games are stored from the challenge on and played a
+	// move at a time: each move may take up to DaysPerMove days (0 for live
+	// games), and the player to move forfeits at MoveDeadline. Pending is
+	// set until the opponent accepts the challenge; the clock only starts
+	// then, and MoveDeadline is nil before that and once the game is over.
+	DaysPerMove  int        `gorm:"default:0"`
+	MoveDeadline *time.Time `gorm:"index"`
+	Pending      bool       `gorm:"default:false"`
+
+	// Cached post-game analysis report (JSON), nil until it has been run
+	Analysis   *string    `gorm:"type:jsonb" json:"-"`
+	AnalyzedAt *time.Time `json:"-"`
+}
+
+// InProgress reports whether the record is a correspondence game still
+// being played, its challenge accepted; live games are only stored once
+// they are over
+func (g *GameRecord) InProgress() bool {
+	return g.DaysPerMove > 0 && !g.Pending && g.EndedAt == nil
+}
+
+// Series lengths players can choose
+var SeriesLengths = map[int]bool{3: true, 5: true, 7: true}
+
+// SeriesStatus tracks the lifecycle of a best-of-N series
+type SeriesStatus string
+
+const (
+	SeriesStatusInProgress SeriesStatus = "in_progress"
+	SeriesStatusCompleted  SeriesStatus = "completed"
+	SeriesStatusAbandoned  SeriesStatus = "abandoned"
+)
+
+// Series is a best-of-N match between two players (GORM model).
+// Player1/Player2 are fixed for the series; colors alternate between games.
+type Series struct {
+	ID              uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Player1Username string       `gorm:"size:50;not null;index" json:"player1"`
+	Player2Username string       `gorm:"size:50;not null;index" json:"player2"`
+	BestOf          int          `gorm:"not null" json:"bestOf"`
+	Player1Wins     int          `gorm:"default:0" json:"player1Wins"`
+	Player2Wins     int          `gorm:"default:0" json:"player2Wins"`
+	Draws           int          `gorm:"default:0" json:"draws"`
+	WinnerUsername  string       `gorm:"size:50" json:"winner,omitempty"`
+	Status          SeriesStatus `gorm:"size:20;default:'in_progress';index" json:"status"`
+	Games           []GameRecord `gorm:"foreignKey:SeriesID" json:"games,omitempty"`
+	CreatedAt       time.Time    `json:"createdAt"`
+	EndedAt         *time.Time   `json:"endedAt,omitempty"`
+}
+
+// GamesPlayed returns how many games of the series have finished
+func (s *Series) GamesPlayed() int {
+	return s.Player1Wins + s.Player2Wins + s.Draws
+}
+
+// RecordGame adds a finished game to the score; winner is empty for a draw.
+// The series completes once a player has won a majority of BestOf games, or
+// after BestOf games with the player on more wins (or no winner if level).
+func (s *Series) RecordGame(winner string) {
+	if s.Status != SeriesStatusInProgress {
+		return
+	}
+
+	switch winner {
+	case s.Player1Username:
+		s.Player1Wins++
+	case s.Player2Username:
+		s.Player2Wins++
+	default:
+		s.Draws++
+	}
+
+	needed := s.BestOf/2 + 1
+	switch {
+	case s.Player1Wins >= needed:
+		s.complete(s.Player1Username)
+	case s.Player2Wins >= needed:
+		s.complete(s.Player2Username)
+	case s.GamesPlayed() >= s.BestOf:
+		switch {
+		case s.Player1Wins > s.Player2Wins:
+			s.complete(s.Player1Username)
+		case s.Player2Wins > s.Player1Wins:
+			s.complete(s.Player2Username)
+		default:
+			s.complete("")
+		}
+	}
+}
+
+// Abandon ends an unfinished series without a winner
+func (s *Series) Abandon() {
+	if s.Status != SeriesStatusInProgress {
+		return
+	}
+	now := time.Now()
+	s.Status = SeriesStatusAbandoned
+	s.EndedAt = &now
+}
+
+func (s *Series) complete(winner string) {
+	now := time.Now()
+	s.Status = SeriesStatusCompleted
+	s.WinnerUsername = winner
+	s.EndedAt = &now
 }
 
 // GameEvent represents an analytics event (GORM model)
@@ -63,6 +178,185 @@ type GameEvent struct {
 	CreatedAt time.Time
 }
 
+// Ban blocks a username from connecting or registering (GORM model)
+type Ban struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Username  string    `gorm:"uniqueIndex;size:50;not null" json:"username"`
+	Reason    string    `gorm:"size:255" json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReportStatus tracks the review state of a player report
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "open"
+	ReportStatusReviewed ReportStatus = "reviewed"
+)
+
+// PlayerReport is a complaint filed by one player about another (GORM model)
+type PlayerReport struct {
+	ID               uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	ReporterUsername string       `gorm:"size:50;not null;index" json:"reporter"`
+	ReportedUsername string       `gorm:"size:50;not null;index" json:"reported"`
+	GameID           *uuid.UUID   `gorm:"type:uuid;index" json:"gameId"`
+	Reason           string       `gorm:"size:30;not null" json:"reason"`
+	Details          string       `gorm:"size:500" json:"details"`
+	Status           ReportStatus `gorm:"size:10;default:'open';index" json:"status"`
+	CreatedAt        time.Time    `json:"createdAt"`
+}
+
+// Report reasons accepted from players
+var ReportReasons = map[string]bool{
+	"abusive_chat":       true,
+	"offensive_username": true,
+	"cheating":           true,
+	"stalling":           true,
+	"other":              true,
+}
+
+// ChatKind distinguishes free-text chat from emotes
+type ChatKind string
+
+const (
+	ChatKindText  ChatKind = "chat"
+	ChatKindEmote ChatKind = "emote"
+)
+
+// ChatMessage is a chat line or emote sent during a game (GORM model)
+type ChatMessage struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	GameID    uuid.UUID `gorm:"type:uuid;not null;index" json:"gameId"`
+	Sender    string    `gorm:"size:50;not null" json:"sender"`
+	Kind      ChatKind  `gorm:"size:10;not null" json:"kind"`
+	Content   string    `gorm:"size:255;not null" json:"content"` // as sent, before filtering
+	Filtered  bool      `gorm:"default:false" json:"filtered"`    // profanity filter changed the text players saw
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TournamentFormat selects how a tournament pairs players
+type TournamentFormat string
+
+const (
+	TournamentFormatSwiss             TournamentFormat = "swiss"
+	TournamentFormatRoundRobin        TournamentFormat = "round_robin"
+	TournamentFormatSingleElimination TournamentFormat = "single_elimination"
+)
+
+// TournamentSeeding selects how knockout players are seeded into the bracket
+type TournamentSeeding string
+
+const (
+	TournamentSeedingRating TournamentSeeding = "rating"
+	TournamentSeedingWins   TournamentSeeding = "wins"
+)
+
+// TournamentStatus tracks the lifecycle of a tournament
+type TournamentStatus string
+
+const (
+	TournamentStatusRegistering TournamentStatus = "registering"
+	TournamentStatusRunning     TournamentStatus = "running"
+	TournamentStatusFinished    TournamentStatus = "finished"
+)
+
+// Tournament is an event of several rounds between registered players (GORM model)
+type Tournament struct {
+	ID             uuid.UUID        `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name           string           `gorm:"size:100;not null" json:"name"`
+	Format         TournamentFormat `gorm:"size:20;not null" json:"format"`
+	Rounds         int              `gorm:"default:0" json:"rounds"` // 0 until started for Swiss without a fixed count
+	CurrentRound   int              `gorm:"default:0" json:"currentRound"`
+	Status         TournamentStatus `gorm:"size:20;default:'registering';index" json:"status"`
+	WinnerUsername string           `gorm:"size:50" json:"winner,omitempty"`
+
+	// Knockout settings: a match is GamesPerMatch games; if it ends level,
+	// up to Tiebreaks extra games are played before the higher seed advances
+	Seeding       TournamentSeeding `gorm:"size:10" json:"seeding,omitempty"`
+	GamesPerMatch int               `gorm:"default:1" json:"gamesPerMatch"`
+	Tiebreaks     int               `gorm:"default:0" json:"tiebreaks"`
+
+	Players   []TournamentPlayer `gorm:"foreignKey:TournamentID" json:"players,omitempty"`
+	Games     []TournamentGame   `gorm:"foreignKey:TournamentID" json:"games,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	StartedAt *time.Time         `json:"startedAt,omitempty"`
+	EndedAt   *time.Time         `json:"endedAt,omitempty"`
+}
+
+// TournamentPlayer is a player registered for a tournament (GORM model)
+type TournamentPlayer struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"-"`
+	TournamentID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tournament_player" json:"-"`
+	Username     string    `gorm:"size:50;not null;uniqueIndex:idx_tournament_player" json:"username"`
+	Seed         int       `gorm:"default:0" json:"seed,omitempty"` // knockout seed, 1 is the top seed
+	CreatedAt    time.Time `json:"registeredAt"`
+}
+
+// TournamentGameResult is the outcome of one tournament pairing
+type TournamentGameResult string
+
+const (
+	TournamentResultPending       TournamentGameResult = "pending"
+	TournamentResultPlayer1       TournamentGameResult = "player1"
+	TournamentResultPlayer2       TournamentGameResult = "player2"
+	TournamentResultDraw          TournamentGameResult = "draw"
+	TournamentResultBye           TournamentGameResult = "bye"            // Player1 sits out and scores a win
+	TournamentResultDoubleForfeit TournamentGameResult = "double_forfeit" // neither player showed up
+)
+
+// TournamentGame is one pairing of a tournament round (GORM model)
+type TournamentGame struct {
+	ID           uuid.UUID            `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TournamentID uuid.UUID            `gorm:"type:uuid;not null;index" json:"-"`
+	Round        int                  `gorm:"not null" json:"round"`
+	Board        int                  `gorm:"not null" json:"board"` // table number within the round
+	Game         int                  `gorm:"default:1" json:"game"` // game number within a knockout match
+	Tiebreak     bool                 `gorm:"default:false" json:"tiebreak"`
+	Player1      string               `gorm:"size:50;not null" json:"player1"`
+	Player2      string               `gorm:"size:50" json:"player2,omitempty"` // empty for a bye
+	GameID       *uuid.UUID           `gorm:"type:uuid;index" json:"gameId,omitempty"`
+	Result       TournamentGameResult `gorm:"size:20;default:'pending'" json:"result"`
+	Forfeit      bool                 `gorm:"default:false" json:"forfeit"` // loser didn't show or timed out
+	CreatedAt    time.Time            `json:"-"`
+	UpdatedAt    time.Time            `json:"-"`
+}
+
+// Puzzle is a "win in N" position mined from a finished game (GORM model).
+// The side to move can force a win in Moves of its own moves.
+type Puzzle struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Position     string     `gorm:"size:42;not null;uniqueIndex:idx_puzzle_position" json:"-"` // game.Board.Encode
+	ToMove       int        `gorm:"not null;uniqueIndex:idx_puzzle_position" json:"toMove"`
+	Moves        int        `gorm:"not null;index" json:"moves"`
+	Solution     int        `gorm:"not null" json:"-"` // a winning first column
+	Rating       int        `gorm:"default:1200" json:"rating"`
+	Attempts     int        `gorm:"default:0" json:"attempts"`
+	Solves       int        `gorm:"default:0" json:"solves"`
+	SourceGameID *uuid.UUID `gorm:"type:uuid" json:"sourceGameId,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// PuzzleAttempt is one player's try at a puzzle (GORM model)
+type PuzzleAttempt struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	PuzzleID     uuid.UUID `gorm:"type:uuid;not null;index" json:"puzzleId"`
+	Username     string    `gorm:"size:50;not null;index" json:"username"`
+	Solved       bool      `gorm:"default:false" json:"solved"`
+	Rated        bool      `gorm:"default:false" json:"rated"` // only a player's first attempt counts
+	RatingChange int       `gorm:"default:0" json:"ratingChange"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Notification is a WebSocket message for a player who wasn't connected
+// when it was sent, delivered and deleted once they connect (GORM model)
+type Notification struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Username  string    `gorm:"size:50;not null;index"`
+	Type      string    `gorm:"size:50;not null"`
+	Payload   string    `gorm:"type:jsonb;not null"`
+	CreatedAt time.Time
+}
+
 // LeaderboardEntry represents a player's ranking (used for API responses)
 type LeaderboardEntry struct {
 	Rank     int    `json:"rank"`
@@ -70,10 +364,12 @@ type LeaderboardEntry struct {
 	Wins     int    `json:"wins"`
 	Losses   int    `json:"losses"`
 	Draws    int    `json:"draws"`
+	Rating   int    `json:"rating"`
 	Games    int    `json:"games"`
+	IsBot    bool   `json:"isBot"`
 }
 
 // AutoMigrate runs GORM auto-migration for all models
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&Player{}, &GameRecord{}, &GameEvent{})
+	return db.AutoMigrate(&Player{}, &GameRecord{}, &GameEvent{}, &Ban{}, &PlayerReport{}, &ChatMessage{}, &Series{}, &Tournament{}, &TournamentPlayer{}, &TournamentGame{}, &Puzzle{}, &PuzzleAttempt{}, &Notification{})
 }
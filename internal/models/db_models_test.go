@@ -0,0 +1,57 @@
+package models
+
+import "testing"
+
+func TestSeriesRecordGameMajority(t *testing.T) {
+	s := &Series{Player1Username: "alice", Player2Username: "bob", BestOf: 3, Status: SeriesStatusInProgress}
+
+	s.RecordGame("alice")
+	if s.Status != SeriesStatusInProgress {
+		t.Fatalf("series finished after one game")
+	}
+	s.RecordGame("alice")
+	if s.Status != SeriesStatusCompleted || s.WinnerUsername != "alice" {
+		t.Fatalf("expected alice to win 2-0, got status=%s winner=%q", s.Status, s.WinnerUsername)
+	}
+
+	// Further games are ignored once the series is decided
+	s.RecordGame("bob")
+	if s.Player2Wins != 0 {
+		t.Errorf("game recorded after series completed")
+	}
+}
+
+func TestSeriesRecordGameDraws(t *testing.T) {
+	s := &Series{Player1Username: "alice", Player2Username: "bob", BestOf: 3, Status: SeriesStatusInProgress}
+	s.RecordGame("bob")
+	s.RecordGame("")
+	s.RecordGame("")
+
+	if s.Status != SeriesStatusCompleted || s.WinnerUsername != "bob" {
+		t.Fatalf("expected bob to win 1-0 with two draws, got status=%s winner=%q", s.Status, s.WinnerUsername)
+	}
+	if s.GamesPlayed() != 3 {
+		t.Errorf("GamesPlayed = %d, want 3", s.GamesPlayed())
+	}
+}
+
+func TestSeriesRecordGameLevel(t *testing.T) {
+	s := &Series{Player1Username: "alice", Player2Username: "bob", BestOf: 3, Status: SeriesStatusInProgress}
+	s.RecordGame("alice")
+	s.RecordGame("bob")
+	s.RecordGame("")
+
+	if s.Status != SeriesStatusCompleted || s.WinnerUsername != "" {
+		t.Fatalf("expected drawn series, got status=%s winner=%q", s.Status, s.WinnerUsername)
+	}
+}
+
+func TestSeriesAbandon(t *testing.T) {
+	s := &Series{Player1Username: "alice", Player2Username: "bob", BestOf: 5, Status: SeriesStatusInProgress}
+	s.RecordGame("alice")
+	s.Abandon()
+
+	if s.Status != SeriesStatusAbandoned || s.EndedAt == nil {
+		t.Fatalf("expected abandoned series with end time, got status=%s", s.Status)
+	}
+}
@@ -24,12 +24,32 @@ type WSMessageType string
 
 const (
 	// Client -> Server
-	WSTypeJoinQueue      WSMessageType = "join_queue"
-	WSTypeMakeMove       WSMessageType = "make_move"
-	WSTypeReconnect      WSMessageType = "reconnect"
-	WSTypeLeaveGame      WSMessageType = "leave_game"
-	WSTypeResumeSession  WSMessageType = "resume_session"
-	WSTypeAbandonSession WSMessageType = "abandon_session"
+	WSTypeJoinQueue       WSMessageType = "join_queue"
+	WSTypeMakeMove        WSMessageType = "make_move"
+	WSTypeReconnect       WSMessageType = "reconnect"
+	WSTypeLeaveGame       WSMessageType = "leave_game"
+	WSTypeResumeSession   WSMessageType = "resume_session"
+	WSTypeAbandonSession  WSMessageType = "abandon_session"
+	WSTypeReportPlayer    WSMessageType = "report_player"
+	WSTypeChatMessage     WSMessageType = "chat_message"
+	WSTypeEmote           WSMessageType = "emote"
+	WSTypeMutePlayer      WSMessageType = "mute_player"
+	WSTypeSpectateGame    WSMessageType = "spectate_game"
+	WSTypeStopSpectating  WSMessageType = "stop_spectating"
+	WSTypeOfferRematch    WSMessageType = "offer_rematch"
+	WSTypeAcceptRematch   WSMessageType = "accept_rematch"
+	WSTypeDeclineRematch  WSMessageType = "decline_rematch"
+	WSTypeOfferDraw       WSMessageType = "offer_draw"
+	WSTypeRespondDraw     WSMessageType = "respond_draw"
+	WSTypeRequestTakeback WSMessageType = "request_takeback"
+	WSTypeRespondTakeback WSMessageType = "respond_takeback"
+	WSTypeStartPuzzle     WSMessageType = "start_puzzle"
+	WSTypePuzzleMove      WSMessageType = "puzzle_move"
+	WSTypeRequestHint     WSMessageType = "request_hint"
+	WSTypeJoinLobby       WSMessageType = "join_lobby"
+	WSTypeLeaveLobby      WSMessageType = "leave_lobby"
+
+	WSTypeCorrespondenceMove WSMessageType = "correspondence_move"
 
 	// Server -> Client
 	WSTypeQueueJoined          WSMessageType = "queue_joined"
@@ -43,8 +63,53 @@ const (
 	WSTypeError                WSMessageType = "error"
 	WSTypeGameState            WSMessageType = "game_state"
 	WSTypeExistingSession      WSMessageType = "existing_session"
+	WSTypeKicked               WSMessageType = "kicked"
+	WSTypeServerNotice         WSMessageType = "server_notice"
+	WSTypeReportReceived       WSMessageType = "report_received"
+	WSTypeChatReceived         WSMessageType = "chat_received"
+	WSTypeEmoteReceived        WSMessageType = "emote_received"
+	WSTypeSpectating           WSMessageType = "spectating"
+	WSTypeRematchOffered       WSMessageType = "rematch_offered"
+	WSTypeRematchDeclined      WSMessageType = "rematch_declined"
+	WSTypeSeriesUpdate         WSMessageType = "series_update"
+	WSTypeDrawOffered          WSMessageType = "draw_offered"
+	WSTypeDrawDeclined         WSMessageType = "draw_declined"
+	WSTypeTakebackRequested    WSMessageType = "takeback_requested"
+	WSTypeTakebackDeclined     WSMessageType = "takeback_declined"
+	WSTypeTakebackApplied      WSMessageType = "takeback_applied"
+	WSTypeTournamentNextGame   WSMessageType = "tournament_next_game"
+	WSTypeTournamentBye        WSMessageType = "tournament_bye"
+	WSTypeTournamentFinished   WSMessageType = "tournament_finished"
+	WSTypePuzzleStarted        WSMessageType = "puzzle_started"
+	WSTypePuzzleReply          WSMessageType = "puzzle_reply"
+	WSTypePuzzleFinished       WSMessageType = "puzzle_finished"
+	WSTypeHint                 WSMessageType = "hint"
+	WSTypeLobbyUpdate          WSMessageType = "lobby_update"
+	WSTypePlayerPlaced         WSMessageType = "player_placed"
+
+	WSTypeCorrespondenceGame      WSMessageType = "correspondence_game"      // answer to correspondence_move
+	WSTypeCorrespondenceChallenge WSMessageType = "correspondence_challenge" // someone challenged you
+	WSTypeCorrespondenceStarted   WSMessageType = "correspondence_started"   // your challenge was accepted
+	WSTypeCorrespondenceDeclined  WSMessageType = "correspondence_declined"  // a challenge was declined or withdrawn
+	WSTypeCorrespondenceTurn      WSMessageType = "correspondence_turn"      // your opponent moved
+	WSTypeCorrespondenceOver      WSMessageType = "correspondence_over"
 )
 
+// MaxChatLength is the longest chat message accepted, in characters
+const MaxChatLength = 200
+
+// Emotes lists the emotes players can send
+var Emotes = map[string]bool{
+	"hello":     true,
+	"good_luck": true,
+	"nice_move": true,
+	"oops":      true,
+	"thinking":  true,
+	"wow":       true,
+	"gg":        true,
+	"thanks":    true,
+}
+
 // WSMessage is the envelope for WebSocket messages
 // SYNC: shared/schema.json -> definitions.WSMessage
 type WSMessage struct {
@@ -59,12 +124,45 @@ type WSMessage struct {
 
 // JoinQueuePayload - SYNC: shared/schema.json -> definitions.JoinQueuePayload
 type JoinQueuePayload struct {
-	Username string `json:"username"`
+	Username  string `json:"username"`
+	Engine    string `json:"engine,omitempty"` // play this engine right away instead of queueing
+	AllowBots bool   `json:"allowBots"`        // also accept bot accounts as opponents
+
+	// Bot personality if matchmaking falls back to a bot; random if empty
+	Personality string `json:"personality,omitempty"`
+
+	// Rules to play by: "classic" (default), "popout", "pop10",
+	// "five_in_a_row" or "power_up"; each has its own queue
+	Variant string `json:"variant,omitempty"`
+	// Play on a cylinder, where lines may wrap from the last column to the
+	// first; cylinder games queue apart from flat ones
+	Cylinder bool `json:"cylinder,omitempty"`
+	// Play an unrated game, where takebacks and hints are allowed; casual
+	// players are only matched with each other
+	Casual bool `json:"casual,omitempty"`
+}
+
+// CorrespondenceMovePayload - a move in a correspondence game, over
+// WebSocket or posted to /api/games/{id}/moves
+type CorrespondenceMovePayload struct {
+	GameID string `json:"gameId"`
+	Column int    `json:"column"`
+	Type   string `json:"type,omitempty"` // as in MakeMovePayload
+	Disc   string `json:"disc,omitempty"`
+}
+
+// JoinLobbyPayload - wait for a 3- or 4-player game with the given rule
+// ("first_to_connect" or "elimination")
+type JoinLobbyPayload struct {
+	Seats int    `json:"seats"`
+	Rule  string `json:"rule"`
 }
 
 // MakeMovePayload - SYNC: shared/schema.json -> definitions.MakeMovePayload
 type MakeMovePayload struct {
-	Column int `json:"column"` // 0-6
+	Column int    `json:"column"`         // 0-6, or 0-8 in Five-in-a-Row
+	Type   string `json:"type,omitempty"` // "drop" (default) or, in PopOut and Pop 10, "pop"
+	Disc   string `json:"disc,omitempty"` // Power Up: "anvil", "bomb", "wall" or "double"
 }
 
 // ReconnectPayload - SYNC: shared/schema.json -> definitions.ReconnectPayload
@@ -73,6 +171,35 @@ type ReconnectPayload struct {
 	Username string `json:"username"`
 }
 
+// ReportPlayerPayload - GameID defaults to the reporter's current game
+type ReportPlayerPayload struct {
+	Username string `json:"username"`
+	GameID   string `json:"gameId,omitempty"`
+	Reason   string `json:"reason"`
+	Details  string `json:"details,omitempty"`
+}
+
+// ChatMessagePayload - free text sent to the opponent and spectators
+type ChatMessagePayload struct {
+	Text string `json:"text"`
+}
+
+// EmotePayload - Emote must be one of Emotes
+type EmotePayload struct {
+	Emote string `json:"emote"`
+}
+
+// MutePlayerPayload - stop (or resume) receiving chat from a player
+type MutePlayerPayload struct {
+	Username string `json:"username"`
+	Muted    bool   `json:"muted"`
+}
+
+// SpectateGamePayload - watch a game in progress
+type SpectateGamePayload struct {
+	GameID string `json:"gameId"`
+}
+
 // =============================================================================
 // Server -> Client Payloads
 // =============================================================================
@@ -87,15 +214,45 @@ type GameStartedPayload struct {
 	GameID    string `json:"gameId"`
 	Opponent  string `json:"opponent"`
 	YourTurn  bool   `json:"yourTurn"`
-	YourColor int    `json:"yourColor"` // 1 = Red, 2 = Yellow
+	YourColor int    `json:"yourColor"`       // 1 = Red, 2 = Yellow
+	Rated     bool   `json:"rated,omitempty"` // the result changes ratings; takebacks are limited and hints are off
+
+	Variant        string `json:"variant,omitempty"`        // the rules, as in JoinQueuePayload
+	Cylinder       bool   `json:"cylinder,omitempty"`       // lines wrap around the board's edges
+	OpponentIsBot  bool   `json:"opponentIsBot,omitempty"`  // the opponent is a bot account or engine
+	BotPersonality string `json:"botPersonality,omitempty"` // the built-in bot's personality this game
+
+	Series     *SeriesPayload         `json:"series,omitempty"`     // set for games that are part of a series
+	Tournament *TournamentInfoPayload `json:"tournament,omitempty"` // set for tournament games
+
+	// The board size
+	Rows    int `json:"rows,omitempty"`
+	Columns int `json:"columns,omitempty"`
+
+	// Multiplayer games only: every seat in turn order and the rule.
+	// Opponent is empty.
+	Players []SeatPayload `json:"players,omitempty"`
+	Rule    string        `json:"rule,omitempty"`
+}
+
+// SeatPayload - a seat in a multiplayer game
+type SeatPayload struct {
+	Username string `json:"username"`
+	Color    int    `json:"color"` // 1 = Red, 2 = Yellow, 3 = Green, 4 = Blue
+	IsBot    bool   `json:"isBot,omitempty"`
 }
 
 // MoveMadePayload - SYNC: shared/schema.json -> definitions.MoveMadePayload
 type MoveMadePayload struct {
 	Column int     `json:"column"`
 	Row    int     `json:"row"`
-	Player int     `json:"player"` // 1 or 2
-	Board  [][]int `json:"board"`  // 6 rows x 7 columns, 0=empty, 1=P1, 2=P2
+	Player int     `json:"player"`         // 1 or 2
+	Board  [][]int `json:"board"`          // rows x columns, 0=empty, 1=P1, 2=P2, 5=Power Up wall
+	Type   string  `json:"type,omitempty"` // "pop" when a disc was popped from the bottom (Row), else a drop
+	Disc   string  `json:"disc,omitempty"` // Power Up's special disc, if one was dropped
+
+	NextPlayer int   `json:"nextPlayer,omitempty"` // color to move next, 0 once over; some rules give a player several moves in a row
+	Captured   []int `json:"captured,omitempty"`   // Pop 10: discs each player has set aside, Player1's first
 }
 
 // InvalidMovePayload - SYNC: shared/schema.json -> definitions.InvalidMovePayload
@@ -108,11 +265,40 @@ type GameOverPayload struct {
 	Winner     string  `json:"winner"`     // username or "draw"
 	Result     string  `json:"result"`     // "win", "loss", "draw", "forfeit"
 	FinalBoard [][]int `json:"finalBoard"` // Final board state
+
+	// Multiplayer games: every seat's place, best first. Result is "win"
+	// for a sole first place, "draw" for a shared one and "loss" otherwise.
+	Placements []PlacementPayload `json:"placements,omitempty"`
+}
+
+// PlacementPayload - where a seat of a multiplayer game finished. Reason
+// says how: "connected", "left" (or timed out) or "finished" for the seats
+// still playing when the game ended.
+type PlacementPayload struct {
+	Username string `json:"username"`
+	Color    int    `json:"color"`
+	Place    int    `json:"place"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// LobbyPayload - the multiplayer lobby you are waiting in
+type LobbyPayload struct {
+	Seats   int      `json:"seats"`
+	Rule    string   `json:"rule"`
+	Players []string `json:"players"` // in join order
+	Timeout int      `json:"timeout"` // seconds until bots fill the empty seats
 }
 
 // OpponentDisconnectedPayload - SYNC: shared/schema.json -> definitions.OpponentDisconnectedPayload
 type OpponentDisconnectedPayload struct {
-	Timeout int `json:"timeout"` // seconds remaining for reconnect
+	Timeout  int    `json:"timeout"`            // seconds remaining for reconnect
+	Username string `json:"username,omitempty"` // multiplayer games: who dropped off
+}
+
+// OpponentReconnectedPayload - multiplayer games: who came back. Two-player
+// games send opponent_reconnected without a payload.
+type OpponentReconnectedPayload struct {
+	Username string `json:"username"`
 }
 
 // GameForfeitedPayload - SYNC: shared/schema.json -> definitions.GameForfeitedPayload
@@ -133,6 +319,10 @@ type GameStatePayload struct {
 	YourColor   int     `json:"yourColor"`   // 1 or 2
 	YourTurn    bool    `json:"yourTurn"`
 	Opponent    string  `json:"opponent"`
+	Variant     string  `json:"variant,omitempty"`
+	Cylinder    bool    `json:"cylinder,omitempty"`
+
+	Players []SeatPayload `json:"players,omitempty"` // multiplayer games: every seat, Opponent is empty
 }
 
 // ExistingSessionPayload - sent when player has an active game session
@@ -140,4 +330,211 @@ type ExistingSessionPayload struct {
 	GameID   string `json:"gameId"`
 	Opponent string `json:"opponent"`
 	IsBot    bool   `json:"isBot"`
+
+	Players []SeatPayload `json:"players,omitempty"` // multiplayer games: every seat, Opponent is empty
+}
+
+// KickedPayload - sent right before an admin disconnects the client
+type KickedPayload struct {
+	Reason string `json:"reason"`
+}
+
+// ServerNoticePayload - operator announcement broadcast to every client
+type ServerNoticePayload struct {
+	Message string `json:"message"`
+}
+
+// ReportReceivedPayload - acknowledges a report_player message
+type ReportReceivedPayload struct {
+	ReportID string `json:"reportId"`
+}
+
+// ChatReceivedPayload - a chat line from a player in the game
+type ChatReceivedPayload struct {
+	GameID string `json:"gameId"`
+	From   string `json:"from"`
+	Text   string `json:"text"`
+}
+
+// EmoteReceivedPayload - an emote from a player in the game
+type EmoteReceivedPayload struct {
+	GameID string `json:"gameId"`
+	From   string `json:"from"`
+	Emote  string `json:"emote"`
+}
+
+// OfferRematchPayload - client offers another game to the last opponent.
+// BestOf 3, 5 or 7 proposes a series; it is ignored while a series is running.
+type OfferRematchPayload struct {
+	BestOf int `json:"bestOf,omitempty"`
+}
+
+// RematchOfferedPayload - the last opponent wants to play again
+type RematchOfferedPayload struct {
+	From     string `json:"from"`
+	BestOf   int    `json:"bestOf"`
+	SeriesID string `json:"seriesId,omitempty"`
+}
+
+// RematchDeclinedPayload - the rematch offer is no longer available
+type RematchDeclinedPayload struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+// SeriesPayload - running score of a best-of-N series
+type SeriesPayload struct {
+	SeriesID    string `json:"seriesId"`
+	BestOf      int    `json:"bestOf"`
+	Player1     string `json:"player1"`
+	Player2     string `json:"player2"`
+	Player1Wins int    `json:"player1Wins"`
+	Player2Wins int    `json:"player2Wins"`
+	Draws       int    `json:"draws"`
+	GamesPlayed int    `json:"gamesPlayed"`
+	Finished    bool   `json:"finished"`
+	Winner      string `json:"winner,omitempty"` // empty when unfinished or drawn
+}
+
+// RespondOfferPayload - answer to a draw offer or takeback request
+type RespondOfferPayload struct {
+	Accept bool `json:"accept"`
+}
+
+// OfferPayload - the opponent offered a draw or asked for a takeback
+type OfferPayload struct {
+	From string `json:"from"`
+}
+
+// OfferDeclinedPayload - a draw offer or takeback request was turned down
+type OfferDeclinedPayload struct {
+	By     string `json:"by"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// TakebackAppliedPayload - moves were taken back; board and turn after undo
+type TakebackAppliedPayload struct {
+	Board       [][]int `json:"board"`
+	CurrentTurn int     `json:"currentTurn"`
+	MovesUndone int     `json:"movesUndone"`
+}
+
+// TournamentInfoPayload - identifies the tournament round a game belongs to
+type TournamentInfoPayload struct {
+	TournamentID string `json:"tournamentId"`
+	Name         string `json:"name"`
+	Round        int    `json:"round"`
+	Game         int    `json:"game,omitempty"` // knockout: game number within the match
+	Tiebreak     bool   `json:"tiebreak,omitempty"`
+}
+
+// TournamentNextGamePayload - your pairing for the next round and when it starts
+type TournamentNextGamePayload struct {
+	TournamentInfoPayload
+	Opponent  string `json:"opponent"`
+	YourColor int    `json:"yourColor"`
+	StartsIn  int    `json:"startsIn"` // seconds
+}
+
+// TournamentByePayload - you sit out this round and score a win
+type TournamentByePayload struct {
+	TournamentInfoPayload
+}
+
+// TournamentFinishedPayload - final result of a tournament for one player
+type TournamentFinishedPayload struct {
+	TournamentID string  `json:"tournamentId"`
+	Name         string  `json:"name"`
+	Winner       string  `json:"winner"`
+	Rank         int     `json:"rank"`
+	Points       float64 `json:"points"`
+}
+
+// SpectatingPayload - current state of the game a spectator joined
+type SpectatingPayload struct {
+	GameID      string  `json:"gameId"`
+	Player1     string  `json:"player1"`
+	Player2     string  `json:"player2"`
+	Board       [][]int `json:"board"`
+	CurrentTurn int     `json:"currentTurn"`
+	Variant     string  `json:"variant"`
+	Cylinder    bool    `json:"cylinder,omitempty"`
+}
+
+// StartPuzzlePayload - start a puzzle; an empty ID starts the daily puzzle
+type StartPuzzlePayload struct {
+	PuzzleID string `json:"puzzleId,omitempty"`
+}
+
+// PuzzleStartedPayload - the position to solve; you move first as YourColor
+type PuzzleStartedPayload struct {
+	PuzzleID     string  `json:"puzzleId"`
+	Board        [][]int `json:"board"`
+	YourColor    int     `json:"yourColor"`
+	Moves        int     `json:"moves"` // win in this many of your moves
+	Rating       int     `json:"rating"`
+	Rated        bool    `json:"rated"`
+	PlayerRating int     `json:"playerRating"`
+}
+
+// PuzzleReplyPayload - your move was correct; the defender's answer
+type PuzzleReplyPayload struct {
+	Column    int     `json:"column"`
+	Row       int     `json:"row"`
+	Board     [][]int `json:"board"`
+	MovesLeft int     `json:"movesLeft"`
+}
+
+// PuzzleFinishedPayload - the puzzle was solved or failed
+type PuzzleFinishedPayload struct {
+	PuzzleID     string `json:"puzzleId"`
+	Solved       bool   `json:"solved"`
+	Solution     []int  `json:"solution,omitempty"` // winning columns you could have played
+	Rated        bool   `json:"rated"`
+	RatingChange int    `json:"ratingChange"`
+	PlayerRating int    `json:"playerRating"`
+}
+
+// HintPayload - a suggested move with the bot's reason for it
+type HintPayload struct {
+	Column    int    `json:"column"`
+	Row       int    `json:"row"`
+	Reason    string `json:"reason"` // wins_immediately, blocks_threat, creates_double_threat, ...
+	Text      string `json:"text"`   // e.g. "blocks opponent's threat at row 3"
+	HintsLeft int    `json:"hintsLeft"`
+}
+
+// CorrespondenceGamePayload - a correspondence game as both players see it:
+// the answer to its REST calls and correspondence_move, and the payload of
+// every correspondence_* event
+type CorrespondenceGamePayload struct {
+	GameID      string  `json:"gameId"`
+	Player1     string  `json:"player1"` // moves first
+	Player2     string  `json:"player2"`
+	Variant     string  `json:"variant"`
+	Cylinder    bool    `json:"cylinder,omitempty"`
+	DaysPerMove int     `json:"daysPerMove"`
+	Board       [][]int `json:"board"`
+	Moves       int     `json:"moves"`             // plies played
+	Pending     bool    `json:"pending,omitempty"` // challenged, not yet accepted
+
+	CurrentTurn int                     `json:"currentTurn,omitempty"` // 1 or 2, 0 once over
+	Deadline    *time.Time              `json:"deadline,omitempty"`    // when the player to move forfeits
+	LastMove    *CorrespondenceLastMove `json:"lastMove,omitempty"`
+	Captured    []int                   `json:"captured,omitempty"` // Pop 10, as in MoveMadePayload
+
+	// Set once over; Winner is empty for a draw
+	Result       string   `json:"result,omitempty"` // "player1", "player2", "draw" or "forfeit" (resigned or out of time)
+	Winner       string   `json:"winner,omitempty"`
+	WinningCells [][2]int `json:"winningCells,omitempty"`
+}
+
+// CorrespondenceLastMove - the move that led to a correspondence game's
+// position
+type CorrespondenceLastMove struct {
+	Player int    `json:"player"`
+	Column int    `json:"column"`
+	Row    int    `json:"row"`
+	Type   string `json:"type,omitempty"`
+	Disc   string `json:"disc,omitempty"`
 }
@@ -0,0 +1,165 @@
+package moderation
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Username length limits (the players.username column is size 50)
+const (
+	MinUsernameLength = 1
+	MaxUsernameLength = 50
+)
+
+// Username validation errors, safe to show to the user
+var (
+	ErrUsernameEmpty     = errors.New("username is required")
+	ErrUsernameTooLong   = errors.New("username too long (max 50 chars)")
+	ErrUsernameCharset   = errors.New("username may only contain letters, digits, '_', '-' and '.'")
+	ErrUsernameReserved  = errors.New("username is reserved")
+	ErrUsernameProfanity = errors.New("username is not allowed")
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// tokenPattern matches the whitespace-separated tokens Filter checks
+var tokenPattern = regexp.MustCompile(`\S+`)
+
+// reservedNames can't be registered by players (compared case-insensitively)
+var reservedNames = []string{"bot", "admin", "administrator", "moderator", "system", "server", "draw"}
+
+// leetReplacer folds common character substitutions before profanity matching
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+// Policy enforces username rules and filters offensive text
+type Policy struct {
+	reserved  map[string]struct{}
+	profanity map[string]struct{}
+}
+
+// NewPolicy creates a policy, loading the profanity list from path if set.
+// The file holds one word per line; blank lines and '#' comments are ignored.
+func NewPolicy(profanityPath string) (*Policy, error) {
+	p := &Policy{
+		reserved:  make(map[string]struct{}, len(reservedNames)),
+		profanity: make(map[string]struct{}),
+	}
+	for _, name := range reservedNames {
+		p.reserved[name] = struct{}{}
+	}
+
+	if profanityPath == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(profanityPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.TrimSpace(scanner.Text())
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		p.profanity[normalize(word)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	log.Info().Int("words", len(p.profanity)).Str("path", profanityPath).Msg("Profanity list loaded")
+	return p, nil
+}
+
+// ValidateUsername checks length, charset, reserved names and profanity
+func (p *Policy) ValidateUsername(username string) error {
+	if len(username) < MinUsernameLength {
+		return ErrUsernameEmpty
+	}
+	if len(username) > MaxUsernameLength {
+		return ErrUsernameTooLong
+	}
+	if !usernamePattern.MatchString(username) {
+		return ErrUsernameCharset
+	}
+	if _, ok := p.reserved[strings.ToLower(username)]; ok {
+		return ErrUsernameReserved
+	}
+	if p.ContainsProfanity(username) {
+		return ErrUsernameProfanity
+	}
+	return nil
+}
+
+// ContainsProfanity reports whether text contains a listed word as a whole
+// token, ignoring case and common leetspeak substitutions. Innocent words
+// that merely contain a listed word don't count.
+func (p *Policy) ContainsProfanity(text string) bool {
+	if len(p.profanity) == 0 {
+		return false
+	}
+	for _, token := range strings.Fields(text) {
+		if p.profane(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter masks each whitespace-separated token holding a listed word with
+// asterisks, leaving the rest of the text, spacing included, as it was
+func (p *Policy) Filter(text string) string {
+	if len(p.profanity) == 0 {
+		return text
+	}
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if !p.profane(token) {
+			return token
+		}
+		return strings.Repeat("*", len([]rune(token)))
+	})
+}
+
+// profane reports whether a token is a listed word, either whole with its
+// separators dropped ("b.a.d") or in one of the parts they split it into
+// ("bad_name", "bad!")
+func (p *Policy) profane(token string) bool {
+	if _, ok := p.profanity[normalize(token)]; ok {
+		return true
+	}
+	for _, part := range strings.FieldsFunc(token, isSeparator) {
+		if _, ok := p.profanity[normalize(part)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isSeparator reports whether r splits a token into parts; letters, digits
+// and the symbols leetspeak uses for letters don't
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+}
+
+// normalize lowercases, folds leetspeak and drops everything but letters
+func normalize(s string) string {
+	s = leetReplacer.Replace(strings.ToLower(s))
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package moderation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestPolicy(t *testing.T) *Policy {
+	path := filepath.Join(t.TempDir(), "profanity.txt")
+	if err := os.WriteFile(path, []byte("# test list\nbadword\n\nheck\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicy(path)
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+	return policy
+}
+
+func TestValidateUsername(t *testing.T) {
+	policy := newTestPolicy(t)
+
+	tests := []struct {
+		username string
+		want     error
+	}{
+		{"alice", nil},
+		{"Player_1.test-2", nil},
+		{"", ErrUsernameEmpty},
+		{string(make([]byte, 51)), ErrUsernameTooLong},
+		{"has space", ErrUsernameCharset},
+		{"emoji😀", ErrUsernameCharset},
+		{"Bot", ErrUsernameReserved},
+		{"ADMIN", ErrUsernameReserved},
+		{"BadWord", ErrUsernameProfanity},
+		{"b4dw0rd", ErrUsernameProfanity},
+		{"bad.word", ErrUsernameProfanity},
+		{"heck_master", ErrUsernameProfanity},
+		{"check_mate", nil},
+		{"xBadWordx", nil},
+	}
+
+	for _, tt := range tests {
+		if got := policy.ValidateUsername(tt.username); got != tt.want {
+			t.Errorf("ValidateUsername(%q) = %v, want %v", tt.username, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	policy := newTestPolicy(t)
+
+	if got := policy.Filter("well heck that was close"); got != "well **** that was close" {
+		t.Errorf("Unexpected filtered text: %q", got)
+	}
+	if got := policy.Filter("good game"); got != "good game" {
+		t.Errorf("Clean text should be unchanged, got %q", got)
+	}
+	if got := policy.Filter("oh  HECK!\n\tagain"); got != "oh  *****\n\tagain" {
+		t.Errorf("Filter should keep the spacing around masked words, got %q", got)
+	}
+}
+
+func TestInnocentWordsPass(t *testing.T) {
+	policy := newTestPolicy(t)
+
+	// "check" and "checkmate" contain "heck" but aren't it
+	for _, text := range []string{"check", "checkmate in two", "Check, then mate"} {
+		if policy.ContainsProfanity(text) {
+			t.Errorf("%q should not count as profanity", text)
+		}
+		if got := policy.Filter(text); got != text {
+			t.Errorf("Filter(%q) = %q, want it unchanged", text, got)
+		}
+	}
+}
+
+func TestEmptyPolicy(t *testing.T) {
+	policy, err := NewPolicy("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.ContainsProfanity("badword") {
+		t.Error("Policy without a list should not flag anything")
+	}
+	if err := policy.ValidateUsername("bot"); err != ErrUsernameReserved {
+		t.Error("Reserved names should apply without a profanity list")
+	}
+}
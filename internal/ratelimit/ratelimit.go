@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket: it holds up to burst tokens and refills at
+// perSecond tokens per second. A Bucket is not safe for concurrent use;
+// Limiter wraps buckets with a mutex when they are shared.
+type Bucket struct {
+	tokens    float64
+	capacity  float64
+	perSecond float64
+	last      time.Time
+}
+
+// NewBucket creates a full bucket
+func NewBucket(perSecond float64, burst int) *Bucket {
+	return &Bucket{
+		tokens:    float64(burst),
+		capacity:  float64(burst),
+		perSecond: perSecond,
+		last:      time.Now(),
+	}
+}
+
+// Allow takes a token if one is available
+func (b *Bucket) Allow() bool {
+	return b.AllowAt(time.Now())
+}
+
+// AllowAt takes a token at the given time (exposed for deterministic tests)
+func (b *Bucket) AllowAt(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.perSecond
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// RetryAfter returns how long until the next token is available
+func (b *Bucket) RetryAfter() time.Duration {
+	if b.tokens >= 1 || b.perSecond <= 0 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.perSecond * float64(time.Second))
+}
+
+// Limiter keeps one bucket per key (IP, player, API key, ...)
+type Limiter struct {
+	buckets   map[string]*Bucket
+	mu        sync.Mutex
+	perSecond float64
+	burst     int
+}
+
+// NewLimiter creates a keyed limiter and starts evicting idle buckets
+func NewLimiter(perSecond float64, burst int) *Limiter {
+	l := &Limiter{
+		buckets:   make(map[string]*Bucket),
+		perSecond: perSecond,
+		burst:     burst,
+	}
+	go l.cleanup()
+	return l
+}
+
+// Allow takes a token from key's bucket. When denied it also returns how
+// long the caller should wait before retrying.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = NewBucket(l.perSecond, l.burst)
+		l.buckets[key] = b
+	}
+
+	if b.Allow() {
+		return true, 0
+	}
+	return false, b.RetryAfter()
+}
+
+// cleanup removes buckets that have refilled completely and gone idle
+func (l *Limiter) cleanup() {
+	ticker := time.NewTicker(5 * time.Minute)
+	for range ticker.C {
+		l.mu.Lock()
+		for key, b := range l.buckets {
+			if time.Since(b.last) > 5*time.Minute {
+				delete(l.buckets, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	b := NewBucket(2, 3) // 2 tokens/sec, burst 3
+	now := b.last
+
+	for i := 0; i < 3; i++ {
+		if !b.AllowAt(now) {
+			t.Fatalf("Request %d within burst should be allowed", i+1)
+		}
+	}
+	if b.AllowAt(now) {
+		t.Fatal("Request beyond burst should be denied")
+	}
+
+	// Half a second refills one token at 2/sec
+	now = now.Add(500 * time.Millisecond)
+	if !b.AllowAt(now) {
+		t.Error("Token should have refilled after 500ms")
+	}
+	if b.AllowAt(now) {
+		t.Error("Only one token should have refilled")
+	}
+
+	// A long idle period never exceeds the burst capacity
+	now = now.Add(time.Hour)
+	allowed := 0
+	for b.AllowAt(now) {
+		allowed++
+	}
+	if allowed != 3 {
+		t.Errorf("Expected refill capped at burst 3, got %d", allowed)
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	l := NewLimiter(0.001, 1)
+
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("First request for key a should be allowed")
+	}
+	ok, retry := l.Allow("a")
+	if ok {
+		t.Fatal("Second request for key a should be denied")
+	}
+	if retry <= 0 {
+		t.Error("Denied request should report a retry delay")
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("Key b should have its own bucket")
+	}
+}
@@ -0,0 +1,21 @@
+package rating
+
+import "math"
+
+// Elo parameters
+const (
+	DefaultRating = 1200
+	KFactor       = 32
+)
+
+// Expected returns the expected score of a player rated a against one rated b
+func Expected(a, b int) float64 {
+	return 1 / (1 + math.Pow(10, float64(b-a)/400))
+}
+
+// Update returns both players' new ratings after a game. scoreA is 1 if a
+// won, 0.5 for a draw and 0 if a lost.
+func Update(a, b int, scoreA float64) (int, int) {
+	delta := int(math.Round(KFactor * (scoreA - Expected(a, b))))
+	return a + delta, b - delta
+}
@@ -0,0 +1,31 @@
+package rating
+
+import "testing"
+
+func TestExpectedIsSymmetric(t *testing.T) {
+	if e := Expected(1200, 1200); e != 0.5 {
+		t.Errorf("Expected between equals = %v, want 0.5", e)
+	}
+	if sum := Expected(1400, 1200) + Expected(1200, 1400); sum < 0.999 || sum > 1.001 {
+		t.Errorf("expected scores should sum to 1, got %v", sum)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	a, b := Update(1200, 1200, 1)
+	if a != 1216 || b != 1184 {
+		t.Errorf("win between equals: got %d/%d, want 1216/1184", a, b)
+	}
+
+	a, b = Update(1200, 1200, 0.5)
+	if a != 1200 || b != 1200 {
+		t.Errorf("draw between equals should not change ratings: %d/%d", a, b)
+	}
+
+	// Upsets move ratings more than expected wins
+	upsetGain, _ := Update(1000, 1400, 1)
+	favouriteGain, _ := Update(1400, 1000, 1)
+	if upsetGain-1000 <= favouriteGain-1400 {
+		t.Errorf("upset gain %d should exceed favourite gain %d", upsetGain-1000, favouriteGain-1400)
+	}
+}
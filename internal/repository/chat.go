@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"connect-four/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChatRepository handles in-game chat database operations
+type ChatRepository struct {
+	db *gorm.DB
+}
+
+// NewChatRepository creates a new chat repository
+func NewChatRepository(db *gorm.DB) *ChatRepository {
+	return &ChatRepository{db: db}
+}
+
+// Create stores a chat message or emote
+func (r *ChatRepository) Create(ctx context.Context, msg *models.ChatMessage) (err error) {
+	ctx, span := startSpan(ctx, "ChatRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(msg).Error
+}
+
+// GetByGameID returns a game's chat in the order it was sent
+func (r *ChatRepository) GetByGameID(ctx context.Context, gameID uuid.UUID) (_ []models.ChatMessage, err error) {
+	ctx, span := startSpan(ctx, "ChatRepository.GetByGameID")
+	defer func() { endSpan(span, err) }()
+
+	var messages []models.ChatMessage
+	err = r.db.WithContext(ctx).Where("game_id = ?", gameID).Order("created_at ASC").Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
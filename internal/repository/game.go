@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"connect-four/internal/models"
 
 	"github.com/google/uuid"
@@ -18,14 +21,20 @@ func NewGameRepository(db *gorm.DB) *GameRepository {
 }
 
 // Create creates a new game record
-func (r *GameRepository) Create(game *models.GameRecord) error {
-	return r.db.Create(game).Error
+func (r *GameRepository) Create(ctx context.Context, game *models.GameRecord) (err error) {
+	ctx, span := startSpan(ctx, "GameRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(game).Error
 }
 
 // GetByID retrieves a game by ID
-func (r *GameRepository) GetByID(id uuid.UUID) (*models.GameRecord, error) {
+func (r *GameRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.GameRecord, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+
 	var game models.GameRecord
-	err := r.db.Preload("Player1").Preload("Player2").Preload("Winner").
+	err = r.db.WithContext(ctx).Preload("Player1").Preload("Player2").Preload("Winner").
 		First(&game, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -37,9 +46,12 @@ func (r *GameRepository) GetByID(id uuid.UUID) (*models.GameRecord, error) {
 }
 
 // GetByPlayerID retrieves games for a player
-func (r *GameRepository) GetByPlayerID(playerID uuid.UUID, limit int) ([]models.GameRecord, error) {
+func (r *GameRepository) GetByPlayerID(ctx context.Context, playerID uuid.UUID, limit int) (_ []models.GameRecord, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.GetByPlayerID")
+	defer func() { endSpan(span, err) }()
+
 	var games []models.GameRecord
-	err := r.db.Where("player1_id = ? OR player2_id = ?", playerID, playerID).
+	err = r.db.WithContext(ctx).Where("player1_id = ? OR player2_id = ?", playerID, playerID).
 		Preload("Player1").Preload("Player2").
 		Order("ended_at DESC NULLS LAST").
 		Limit(limit).
@@ -51,6 +63,116 @@ func (r *GameRepository) GetByPlayerID(playerID uuid.UUID, limit int) ([]models.
 }
 
 // Update updates a game record
-func (r *GameRepository) Update(game *models.GameRecord) error {
-	return r.db.Save(game).Error
+func (r *GameRepository) Update(ctx context.Context, game *models.GameRecord) (err error) {
+	ctx, span := startSpan(ctx, "GameRepository.Update")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Save(game).Error
+}
+
+// ListFinished returns finished games with their moves, most recent first
+func (r *GameRepository) ListFinished(ctx context.Context, limit int) (_ []models.GameRecord, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.ListFinished")
+	defer func() { endSpan(span, err) }()
+
+	var games []models.GameRecord
+	err = r.db.WithContext(ctx).Where("ended_at IS NOT NULL").
+		Order("ended_at DESC").
+		Limit(limit).
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// SaveAnalysis caches a game's analysis report
+func (r *GameRepository) SaveAnalysis(ctx context.Context, id uuid.UUID, report string) (err error) {
+	ctx, span := startSpan(ctx, "GameRepository.SaveAnalysis")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.GameRecord{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"analysis": report, "analyzed_at": time.Now()}).Error
+}
+
+// ListInProgress returns the correspondence games a player is still
+// playing, the longest-waiting move first
+func (r *GameRepository) ListInProgress(ctx context.Context, playerID uuid.UUID) (_ []models.GameRecord, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.ListInProgress")
+	defer func() { endSpan(span, err) }()
+
+	var games []models.GameRecord
+	err = r.db.WithContext(ctx).Where("(player1_id = ? OR player2_id = ?) AND days_per_move > 0 AND ended_at IS NULL", playerID, playerID).
+		Preload("Player1").Preload("Player2").
+		Order("move_deadline ASC").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// ListOverdue returns the correspondence games whose player to move ran
+// out of time before now
+func (r *GameRepository) ListOverdue(ctx context.Context, now time.Time) (_ []models.GameRecord, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.ListOverdue")
+	defer func() { endSpan(span, err) }()
+
+	var games []models.GameRecord
+	err = r.db.WithContext(ctx).Where("days_per_move > 0 AND ended_at IS NULL AND move_deadline < ?", now).
+		Preload("Player1").Preload("Player2").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+// Accept saves a correspondence challenge as accepted, with its first
+// deadline and start time, unless it was answered meanwhile. Reports
+// whether it was saved.
+func (r *GameRepository) Accept(ctx context.Context, game *models.GameRecord) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.Accept")
+	defer func() { endSpan(span, err) }()
+
+	result := r.db.WithContext(ctx).Model(game).
+		Where("pending AND ended_at IS NULL").
+		Select("pending", "move_deadline", "started_at").
+		Updates(game)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeletePending removes a correspondence challenge nobody has accepted,
+// unless it was answered meanwhile. Reports whether it was removed.
+func (r *GameRepository) DeletePending(ctx context.Context, id uuid.UUID) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.DeletePending")
+	defer func() { endSpan(span, err) }()
+
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND pending AND ended_at IS NULL", id).
+		Delete(&models.GameRecord{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateInProgress saves a correspondence game's moves and result, unless
+// it has changed since it was read with the given deadline: every move
+// sets a new one. Reports whether it was saved.
+func (r *GameRepository) UpdateInProgress(ctx context.Context, game *models.GameRecord, deadline time.Time) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "GameRepository.UpdateInProgress")
+	defer func() { endSpan(span, err) }()
+
+	result := r.db.WithContext(ctx).Model(game).
+		Where("ended_at IS NULL AND move_deadline = ?", deadline).
+		Select("moves", "move_deadline", "result", "winner_id", "ended_at", "duration_seconds").
+		Updates(game)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
 }
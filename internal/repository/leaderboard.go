@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"connect-four/internal/models"
 
 	"gorm.io/gorm"
@@ -17,15 +19,18 @@ func NewLeaderboardRepository(db *gorm.DB) *LeaderboardRepository {
 }
 
 // GetTopPlayers retrieves the top players by wins
-func (r *LeaderboardRepository) GetTopPlayers(limit int) ([]models.LeaderboardEntry, error) {
+func (r *LeaderboardRepository) GetTopPlayers(ctx context.Context, limit int) (_ []models.LeaderboardEntry, err error) {
+	ctx, span := startSpan(ctx, "LeaderboardRepository.GetTopPlayers")
+	defer func() { endSpan(span, err) }()
+
 	if limit <= 0 {
 		limit = 10
 	}
 
 	var entries []models.LeaderboardEntry
 
-	err := r.db.Model(&models.Player{}).
-		Select("ROW_NUMBER() OVER (ORDER BY wins DESC, (wins - losses) DESC) as rank, username, wins, losses, draws, (wins + losses + draws) as games").
+	err = r.db.WithContext(ctx).Model(&models.Player{}).
+		Select("ROW_NUMBER() OVER (ORDER BY wins DESC, (wins - losses) DESC) as rank, username, wins, losses, draws, rating, (wins + losses + draws) as games, is_bot").
 		Where("(wins + losses + draws) > 0").
 		Order("wins DESC, (wins - losses) DESC").
 		Limit(limit).
@@ -39,14 +44,17 @@ func (r *LeaderboardRepository) GetTopPlayers(limit int) ([]models.LeaderboardEn
 }
 
 // GetPlayerRank retrieves a specific player's rank
-func (r *LeaderboardRepository) GetPlayerRank(username string) (*models.LeaderboardEntry, error) {
+func (r *LeaderboardRepository) GetPlayerRank(ctx context.Context, username string) (_ *models.LeaderboardEntry, err error) {
+	ctx, span := startSpan(ctx, "LeaderboardRepository.GetPlayerRank")
+	defer func() { endSpan(span, err) }()
+
 	var entry models.LeaderboardEntry
 
-	subQuery := r.db.Model(&models.Player{}).
-		Select("ROW_NUMBER() OVER (ORDER BY wins DESC, (wins - losses) DESC) as rank, username, wins, losses, draws, (wins + losses + draws) as games").
+	subQuery := r.db.WithContext(ctx).Model(&models.Player{}).
+		Select("ROW_NUMBER() OVER (ORDER BY wins DESC, (wins - losses) DESC) as rank, username, wins, losses, draws, rating, (wins + losses + draws) as games, is_bot").
 		Where("(wins + losses + draws) > 0")
 
-	err := r.db.Table("(?) as ranked", subQuery).
+	err = r.db.WithContext(ctx).Table("(?) as ranked", subQuery).
 		Where("username = ?", username).
 		Scan(&entry).Error
 
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"connect-four/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BanRepository handles ban list database operations
+type BanRepository struct {
+	db *gorm.DB
+}
+
+// NewBanRepository creates a new ban repository
+func NewBanRepository(db *gorm.DB) *BanRepository {
+	return &BanRepository{db: db}
+}
+
+// Create bans a username, updating the reason if already banned
+func (r *BanRepository) Create(ctx context.Context, username, reason string) (err error) {
+	ctx, span := startSpan(ctx, "BanRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "username"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason"}),
+	}).Create(&models.Ban{Username: username, Reason: reason}).Error
+}
+
+// Delete lifts a ban, returning false if the username wasn't banned
+func (r *BanRepository) Delete(ctx context.Context, username string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "BanRepository.Delete")
+	defer func() { endSpan(span, err) }()
+
+	result := r.db.WithContext(ctx).Where("username = ?", username).Delete(&models.Ban{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// IsBanned reports whether a username is banned
+func (r *BanRepository) IsBanned(ctx context.Context, username string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "BanRepository.IsBanned")
+	defer func() { endSpan(span, err) }()
+
+	var count int64
+	err = r.db.WithContext(ctx).Model(&models.Ban{}).Where("username = ?", username).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// List returns all bans, newest first
+func (r *BanRepository) List(ctx context.Context) (_ []models.Ban, err error) {
+	ctx, span := startSpan(ctx, "BanRepository.List")
+	defer func() { endSpan(span, err) }()
+
+	var bans []models.Ban
+	err = r.db.WithContext(ctx).Order("created_at DESC").Find(&bans).Error
+	if err != nil {
+		return nil, err
+	}
+	return bans, nil
+}
+
+// ReportRepository handles player report database operations
+type ReportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository creates a new report repository
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Create stores a new report
+func (r *ReportRepository) Create(ctx context.Context, report *models.PlayerReport) (err error) {
+	ctx, span := startSpan(ctx, "ReportRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(report).Error
+}
+
+// List returns reports with the given status (all if empty), newest first
+func (r *ReportRepository) List(ctx context.Context, status models.ReportStatus, limit int) (_ []models.PlayerReport, err error) {
+	ctx, span := startSpan(ctx, "ReportRepository.List")
+	defer func() { endSpan(span, err) }()
+
+	query := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reports []models.PlayerReport
+	if err = query.Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
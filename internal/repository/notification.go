@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"connect-four/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository handles messages queued for offline players
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create queues a notification
+func (r *NotificationRepository) Create(ctx context.Context, n *models.Notification) (err error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(n).Error
+}
+
+// ListByUsername returns a player's queued notifications, oldest first
+func (r *NotificationRepository) ListByUsername(ctx context.Context, username string) (_ []models.Notification, err error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.ListByUsername")
+	defer func() { endSpan(span, err) }()
+
+	var notifications []models.Notification
+	err = r.db.WithContext(ctx).Where("username = ?", username).Order("created_at ASC").Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// Delete removes delivered notifications
+func (r *NotificationRepository) Delete(ctx context.Context, ids []uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "NotificationRepository.Delete")
+	defer func() { endSpan(span, err) }()
+
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&models.Notification{}).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"connect-four/internal/models"
 
 	"github.com/google/uuid"
@@ -19,11 +21,14 @@ func NewPlayerRepository(db *gorm.DB) *PlayerRepository {
 }
 
 // Create creates a new player or returns existing one with the same username
-func (r *PlayerRepository) Create(username string) (*models.Player, error) {
+func (r *PlayerRepository) Create(ctx context.Context, username string) (_ *models.Player, err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.Create")
+	defer func() { endSpan(span, err) }()
+
 	player := &models.Player{Username: username}
 
 	// Upsert: create or update on conflict
-	err := r.db.Clauses(clause.OnConflict{
+	err = r.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "username"}},
 		DoUpdates: clause.AssignmentColumns([]string{"updated_at"}),
 	}).Create(player).Error
@@ -33,13 +38,16 @@ func (r *PlayerRepository) Create(username string) (*models.Player, error) {
 	}
 
 	// Fetch the full record
-	return r.GetByUsername(username)
+	return r.GetByUsername(ctx, username)
 }
 
 // GetByID retrieves a player by ID
-func (r *PlayerRepository) GetByID(id uuid.UUID) (*models.Player, error) {
+func (r *PlayerRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.Player, err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+
 	var player models.Player
-	err := r.db.First(&player, "id = ?", id).Error
+	err = r.db.WithContext(ctx).First(&player, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -50,9 +58,12 @@ func (r *PlayerRepository) GetByID(id uuid.UUID) (*models.Player, error) {
 }
 
 // GetByUsername retrieves a player by username
-func (r *PlayerRepository) GetByUsername(username string) (*models.Player, error) {
+func (r *PlayerRepository) GetByUsername(ctx context.Context, username string) (_ *models.Player, err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.GetByUsername")
+	defer func() { endSpan(span, err) }()
+
 	var player models.Player
-	err := r.db.First(&player, "username = ?", username).Error
+	err = r.db.WithContext(ctx).First(&player, "username = ?", username).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -63,19 +74,84 @@ func (r *PlayerRepository) GetByUsername(username string) (*models.Player, error
 }
 
 // IncrementWins increments a player's win count
-func (r *PlayerRepository) IncrementWins(id uuid.UUID) error {
-	return r.db.Model(&models.Player{}).Where("id = ?", id).
+func (r *PlayerRepository) IncrementWins(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.IncrementWins")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ?", id).
 		UpdateColumn("wins", gorm.Expr("wins + 1")).Error
 }
 
 // IncrementLosses increments a player's loss count
-func (r *PlayerRepository) IncrementLosses(id uuid.UUID) error {
-	return r.db.Model(&models.Player{}).Where("id = ?", id).
+func (r *PlayerRepository) IncrementLosses(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.IncrementLosses")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ?", id).
 		UpdateColumn("losses", gorm.Expr("losses + 1")).Error
 }
 
 // IncrementDraws increments a player's draw count
-func (r *PlayerRepository) IncrementDraws(id uuid.UUID) error {
-	return r.db.Model(&models.Player{}).Where("id = ?", id).
+func (r *PlayerRepository) IncrementDraws(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.IncrementDraws")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ?", id).
 		UpdateColumn("draws", gorm.Expr("draws + 1")).Error
 }
+
+// UpdateRating sets a player's Elo rating
+func (r *PlayerRepository) UpdateRating(ctx context.Context, id uuid.UUID, rating int) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.UpdateRating")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ?", id).
+		UpdateColumn("rating", rating).Error
+}
+
+// UpdatePuzzleRating sets a player's puzzle rating
+func (r *PlayerRepository) UpdatePuzzleRating(ctx context.Context, id uuid.UUID, rating int) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.UpdatePuzzleRating")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ?", id).
+		UpdateColumn("puzzle_rating", rating).Error
+}
+
+// CreateBot creates a bot account with the hash of its API key. It fails if
+// the username is already taken by a player or another bot.
+func (r *PlayerRepository) CreateBot(ctx context.Context, username, keyHash string) (_ *models.Player, err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.CreateBot")
+	defer func() { endSpan(span, err) }()
+
+	player := &models.Player{Username: username, IsBot: true, APIKeyHash: keyHash}
+	if err = r.db.WithContext(ctx).Create(player).Error; err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// GetByAPIKey retrieves the bot account a key hash belongs to
+func (r *PlayerRepository) GetByAPIKey(ctx context.Context, keyHash string) (_ *models.Player, err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.GetByAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	var player models.Player
+	err = r.db.WithContext(ctx).First(&player, "is_bot = ? AND api_key_hash = ?", true, keyHash).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &player, nil
+}
+
+// SetAPIKey replaces a bot account's key; the old key stops working
+func (r *PlayerRepository) SetAPIKey(ctx context.Context, id uuid.UUID, keyHash string) (err error) {
+	ctx, span := startSpan(ctx, "PlayerRepository.SetAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Model(&models.Player{}).Where("id = ? AND is_bot = ?", id, true).
+		UpdateColumn("api_key_hash", keyHash).Error
+}
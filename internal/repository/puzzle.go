@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"connect-four/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PuzzleRepository handles puzzle database operations
+type PuzzleRepository struct {
+	db *gorm.DB
+}
+
+// NewPuzzleRepository creates a new puzzle repository
+func NewPuzzleRepository(db *gorm.DB) *PuzzleRepository {
+	return &PuzzleRepository{db: db}
+}
+
+// Create stores a new puzzle
+func (r *PuzzleRepository) Create(ctx context.Context, p *models.Puzzle) (err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+// GetByID retrieves a puzzle by ID
+func (r *PuzzleRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.Puzzle, err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	var p models.Puzzle
+	err = r.db.WithContext(ctx).First(&p, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Exists reports whether a position is already stored as a puzzle
+func (r *PuzzleRepository) Exists(ctx context.Context, position string, toMove int) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.Exists")
+	defer func() { endSpan(span, err) }()
+
+	var count int64
+	err = r.db.WithContext(ctx).Model(&models.Puzzle{}).
+		Where("position = ? AND to_move = ?", position, toMove).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Daily returns the puzzle of the given day. Puzzles are cycled through in
+// the order they were created, so every server picks the same one.
+func (r *PuzzleRepository) Daily(ctx context.Context, day time.Time) (_ *models.Puzzle, err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.Daily")
+	defer func() { endSpan(span, err) }()
+
+	var count int64
+	if err = r.db.WithContext(ctx).Model(&models.Puzzle{}).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	index := (day.UTC().Unix() / 86400) % count
+	var p models.Puzzle
+	err = r.db.WithContext(ctx).Order("created_at ASC, id ASC").Offset(int(index)).First(&p).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// HasAttempted reports whether a player has tried a puzzle before
+func (r *PuzzleRepository) HasAttempted(ctx context.Context, puzzleID uuid.UUID, username string) (_ bool, err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.HasAttempted")
+	defer func() { endSpan(span, err) }()
+
+	var count int64
+	err = r.db.WithContext(ctx).Model(&models.PuzzleAttempt{}).
+		Where("puzzle_id = ? AND username = ?", puzzleID, username).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RecordAttempt stores an attempt and updates the puzzle's counters and
+// rating in one transaction
+func (r *PuzzleRepository) RecordAttempt(ctx context.Context, attempt *models.PuzzleAttempt, puzzleRating int) (err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.RecordAttempt")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"attempts": gorm.Expr("attempts + 1"),
+			"rating":   puzzleRating,
+		}
+		if attempt.Solved {
+			updates["solves"] = gorm.Expr("solves + 1")
+		}
+		return tx.Model(&models.Puzzle{}).Where("id = ?", attempt.PuzzleID).Updates(updates).Error
+	})
+}
+
+// ListAttempts returns a player's most recent puzzle attempts
+func (r *PuzzleRepository) ListAttempts(ctx context.Context, username string, limit int) (_ []models.PuzzleAttempt, err error) {
+	ctx, span := startSpan(ctx, "PuzzleRepository.ListAttempts")
+	defer func() { endSpan(span, err) }()
+
+	var attempts []models.PuzzleAttempt
+	err = r.db.WithContext(ctx).Where("username = ?", username).
+		Order("created_at DESC").Limit(limit).Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
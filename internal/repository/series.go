@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"connect-four/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SeriesRepository handles best-of-N series database operations
+type SeriesRepository struct {
+	db *gorm.DB
+}
+
+// NewSeriesRepository creates a new series repository
+func NewSeriesRepository(db *gorm.DB) *SeriesRepository {
+	return &SeriesRepository{db: db}
+}
+
+// Create stores a new series
+func (r *SeriesRepository) Create(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := startSpan(ctx, "SeriesRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(series).Error
+}
+
+// Update saves the score and status of a series
+func (r *SeriesRepository) Update(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := startSpan(ctx, "SeriesRepository.Update")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Omit("Games").Save(series).Error
+}
+
+// GetByID retrieves a series with its games in the order they were played
+func (r *SeriesRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.Series, err error) {
+	ctx, span := startSpan(ctx, "SeriesRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	var series models.Series
+	err = r.db.WithContext(ctx).
+		Preload("Games", func(db *gorm.DB) *gorm.DB { return db.Order("started_at ASC") }).
+		First(&series, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &series, nil
+}
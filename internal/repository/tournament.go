@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+
+	"connect-four/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TournamentRepository handles tournament database operations
+type TournamentRepository struct {
+	db *gorm.DB
+}
+
+// NewTournamentRepository creates a new tournament repository
+func NewTournamentRepository(db *gorm.DB) *TournamentRepository {
+	return &TournamentRepository{db: db}
+}
+
+// Create stores a new tournament
+func (r *TournamentRepository) Create(ctx context.Context, t *models.Tournament) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+// Update saves a tournament's own fields (not players or games)
+func (r *TournamentRepository) Update(ctx context.Context, t *models.Tournament) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.Update")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Omit("Players", "Games").Save(t).Error
+}
+
+// GetByID retrieves a tournament with its players and games
+func (r *TournamentRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.Tournament, err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+
+	var t models.Tournament
+	err = r.db.WithContext(ctx).
+		Preload("Players", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Games", func(db *gorm.DB) *gorm.DB { return db.Order("round ASC, board ASC") }).
+		First(&t, "id = ?", id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List returns the most recent tournaments without players or games
+func (r *TournamentRepository) List(ctx context.Context, limit int) (_ []models.Tournament, err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.List")
+	defer func() { endSpan(span, err) }()
+
+	var tournaments []models.Tournament
+	err = r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&tournaments).Error
+	if err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// ListByStatus returns every tournament in the given status
+func (r *TournamentRepository) ListByStatus(ctx context.Context, status models.TournamentStatus) (_ []models.Tournament, err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.ListByStatus")
+	defer func() { endSpan(span, err) }()
+
+	var tournaments []models.Tournament
+	err = r.db.WithContext(ctx).Where("status = ?", status).Find(&tournaments).Error
+	if err != nil {
+		return nil, err
+	}
+	return tournaments, nil
+}
+
+// AddPlayer registers a player for a tournament
+func (r *TournamentRepository) AddPlayer(ctx context.Context, p *models.TournamentPlayer) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.AddPlayer")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+// UpdatePlayer saves a registered player's seed
+func (r *TournamentRepository) UpdatePlayer(ctx context.Context, p *models.TournamentPlayer) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.UpdatePlayer")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Save(p).Error
+}
+
+// CreateGames stores the pairings of a round
+func (r *TournamentRepository) CreateGames(ctx context.Context, games []models.TournamentGame) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.CreateGames")
+	defer func() { endSpan(span, err) }()
+
+	if len(games) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&games).Error
+}
+
+// UpdateGame saves a pairing's live game and result
+func (r *TournamentRepository) UpdateGame(ctx context.Context, g *models.TournamentGame) (err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.UpdateGame")
+	defer func() { endSpan(span, err) }()
+
+	return r.db.WithContext(ctx).Save(g).Error
+}
+
+// GetGameByGameID finds the pairing a live game was started for
+func (r *TournamentRepository) GetGameByGameID(ctx context.Context, gameID uuid.UUID) (_ *models.TournamentGame, err error) {
+	ctx, span := startSpan(ctx, "TournamentRepository.GetGameByGameID")
+	defer func() { endSpan(span, err) }()
+
+	var g models.TournamentGame
+	err = r.db.WithContext(ctx).First(&g, "game_id = ?", gameID).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &g, nil
+}
@@ -0,0 +1,127 @@
+// Package solver searches Connect Four positions exactly for forced wins.
+// Depths are counted in the attacker's own moves: a win in 2 is a move, any
+// reply, then a winning move.
+package solver
+
+import "connect-four/internal/game"
+
+// Center-first order finds wins and refutations sooner
+var searchOrder = []int{3, 2, 4, 1, 5, 0, 6}
+
+// WinIn returns the fewest moves in which player, who is to move, can force
+// a win, searching up to maxMoves. 0 means no forced win within maxMoves.
+func WinIn(board *game.Board, player game.Cell, maxMoves int) int {
+	b := board.Clone()
+	for n := 1; n <= maxMoves; n++ {
+		if forcesWin(b, player, n) {
+			return n
+		}
+	}
+	return 0
+}
+
+// WinningMoves returns the columns after which player still forces a win
+// within moves (the move itself included)
+func WinningMoves(board *game.Board, player game.Cell, moves int) []int {
+	b := board.Clone()
+	var winning []int
+	for _, col := range searchOrder {
+		row := b.DropDisc(col, player)
+		if row == -1 {
+			continue
+		}
+		if b.WinsAt(row, col, player) || (moves > 1 && defenderLoses(b, opponent(player), player, moves-1)) {
+			winning = append(winning, col)
+		}
+		b[row][col] = game.Empty
+	}
+	return winning
+}
+
+// Defend returns the reply for defender that holds out longest against an
+// attacker who needs up to moves more moves to win, preferring replies that
+// escape the forced win entirely. Returns -1 if there is no legal reply.
+func Defend(board *game.Board, defender game.Cell, moves int) int {
+	b := board.Clone()
+	attacker := opponent(defender)
+
+	best, bestScore := -1, -1
+	for _, col := range searchOrder {
+		row := b.DropDisc(col, defender)
+		if row == -1 {
+			continue
+		}
+		if b.WinsAt(row, col, defender) {
+			b[row][col] = game.Empty
+			return col
+		}
+
+		score := WinIn(b, attacker, moves)
+		if score == 0 {
+			score = moves + 1
+		}
+		if score > bestScore {
+			best, bestScore = col, score
+		}
+		b[row][col] = game.Empty
+	}
+	return best
+}
+
+// forcesWin reports whether player, to move, wins within n moves whatever
+// the opponent does. b is restored before returning.
+func forcesWin(b *game.Board, player game.Cell, n int) bool {
+	for _, col := range searchOrder {
+		row := b.DropDisc(col, player)
+		if row == -1 {
+			continue
+		}
+		won := b.WinsAt(row, col, player)
+		b[row][col] = game.Empty
+		if won {
+			return true
+		}
+	}
+	if n <= 1 {
+		return false
+	}
+
+	for _, col := range searchOrder {
+		row := b.DropDisc(col, player)
+		if row == -1 {
+			continue
+		}
+		won := defenderLoses(b, opponent(player), player, n-1)
+		b[row][col] = game.Empty
+		if won {
+			return true
+		}
+	}
+	return false
+}
+
+// defenderLoses reports whether every reply of defender still lets attacker
+// win within n moves. A full board is a draw, not a loss.
+func defenderLoses(b *game.Board, defender, attacker game.Cell, n int) bool {
+	replies := 0
+	for _, col := range searchOrder {
+		row := b.DropDisc(col, defender)
+		if row == -1 {
+			continue
+		}
+		replies++
+		lost := !b.WinsAt(row, col, defender) && forcesWin(b, attacker, n)
+		b[row][col] = game.Empty
+		if !lost {
+			return false
+		}
+	}
+	return replies > 0
+}
+
+func opponent(player game.Cell) game.Cell {
+	if player == game.Player1 {
+		return game.Player2
+	}
+	return game.Player1
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+)
+
+// play drops discs in the given columns, alternating from Player1
+func play(cols ...int) *game.Board {
+	board := game.NewBoard()
+	player := game.Player1
+	for _, col := range cols {
+		board.DropDisc(col, player)
+		player = opponent(player)
+	}
+	return board
+}
+
+func TestWinInOne(t *testing.T) {
+	// Player1 has three on the bottom row
+	board := play(0, 0, 1, 1, 2, 6)
+	if n := WinIn(board, game.Player1, 3); n != 1 {
+		t.Errorf("WinIn = %d, want 1", n)
+	}
+	if moves := WinningMoves(board, game.Player1, 1); len(moves) != 1 || moves[0] != 3 {
+		t.Errorf("WinningMoves = %v, want [3]", moves)
+	}
+}
+
+func TestWinInTwo(t *testing.T) {
+	// Player1 holds the middle of the bottom row; playing 2 or 4 leaves an
+	// open three the opponent can only block on one side
+	board := play(3, 3, 4, 4)
+	if n := WinIn(board, game.Player1, 3); n != 2 {
+		t.Errorf("WinIn = %d, want 2", n)
+	}
+	for _, col := range WinningMoves(board, game.Player1, 2) {
+		if col != 2 && col != 5 {
+			t.Errorf("unexpected winning move %d", col)
+		}
+	}
+
+	// Against an open three every reply loses at once
+	board.DropDisc(2, game.Player1)
+	if col := Defend(board, game.Player2, 1); col < 0 || board.IsColumnFull(col) {
+		t.Errorf("Defend = %d, want a legal column", col)
+	}
+}
+
+func TestNoForcedWinOnEmptyBoard(t *testing.T) {
+	if n := WinIn(game.NewBoard(), game.Player1, 3); n != 0 {
+		t.Errorf("WinIn on empty board = %d, want 0", n)
+	}
+}
+
+func TestDefendBlocksImmediateThreat(t *testing.T) {
+	// Player1 threatens 3 on the bottom row; Player2 to move must block
+	board := play(0, 6, 1, 6, 2)
+	if col := Defend(board, game.Player2, 3); col != 3 {
+		t.Errorf("Defend = %d, want 3", col)
+	}
+}
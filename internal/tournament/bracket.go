@@ -0,0 +1,240 @@
+package tournament
+
+import (
+	"sort"
+
+	"connect-four/internal/models"
+)
+
+// BracketSize returns the smallest power of two that fits every player
+func BracketSize(players int) int {
+	size := 1
+	for size < players {
+		size *= 2
+	}
+	return size
+}
+
+// KnockoutRounds returns how many rounds a single-elimination bracket takes
+func KnockoutRounds(players int) int {
+	rounds := 0
+	for n := 1; n < players; n *= 2 {
+		rounds++
+	}
+	return rounds
+}
+
+// SeedOrder returns the seeds of a bracket in board order, so that adjacent
+// pairs meet in the first round and the top two seeds can only meet in the
+// final: for 8 it is 1 8 4 5 2 7 3 6. size must be a power of two.
+func SeedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		sum := len(order)*2 + 1
+		for _, seed := range order {
+			next = append(next, seed, sum-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// FirstRoundPairings places seeded players (best first) into a bracket.
+// Seeds without an opponent in a field that isn't a power of two get a bye,
+// so byes always go to the top seeds. The better seed moves first.
+func FirstRoundPairings(seeded []string) []Pairing {
+	if len(seeded) < 2 {
+		return nil
+	}
+	order := SeedOrder(BracketSize(len(seeded)))
+	pairs := make([]Pairing, 0, len(order)/2)
+	for i := 0; i < len(order); i += 2 {
+		p := Pairing{Player1: seeded[order[i]-1]}
+		if order[i+1] <= len(seeded) {
+			p.Player2 = seeded[order[i+1]-1]
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs
+}
+
+// MatchState is where a knockout match stands after its finished games
+type MatchState struct {
+	Decided bool
+	Winner  string
+
+	// Next is the game to play when the match is undecided and nothing is
+	// pending; colors are swapped from the previous game
+	Next     *Pairing
+	Tiebreak bool
+}
+
+// DecideMatch works out a knockout match from its games (ordered by game
+// number). A match is won by clinching more of its gamesPerMatch regular
+// games than the opponent can catch up with, or by a forfeit. A level match
+// gets up to tiebreaks extra games; if still level, or if neither player
+// showed up, the better seed (lower number in seeds) advances.
+func DecideMatch(games []models.TournamentGame, gamesPerMatch, tiebreaks int, seeds map[string]int) MatchState {
+	if len(games) == 0 {
+		return MatchState{}
+	}
+	first := games[0]
+	if first.Result == models.TournamentResultBye {
+		return MatchState{Decided: true, Winner: first.Player1}
+	}
+
+	a, b := first.Player1, first.Player2
+	better := a
+	if seeds[b] < seeds[a] {
+		better = b
+	}
+
+	var winsA, winsB, regular, extra int
+	for _, g := range games {
+		if g.Result == models.TournamentResultPending {
+			return MatchState{}
+		}
+
+		var winner string
+		switch g.Result {
+		case models.TournamentResultPlayer1:
+			winner = g.Player1
+		case models.TournamentResultPlayer2:
+			winner = g.Player2
+		case models.TournamentResultDoubleForfeit:
+			return MatchState{Decided: true, Winner: better}
+		}
+		if g.Forfeit && winner != "" {
+			return MatchState{Decided: true, Winner: winner}
+		}
+
+		switch winner {
+		case a:
+			winsA++
+		case b:
+			winsB++
+		}
+		if g.Tiebreak {
+			extra++
+		} else {
+			regular++
+		}
+	}
+
+	remaining := gamesPerMatch - regular
+	switch {
+	case remaining > 0 && winsA > winsB+remaining:
+		return MatchState{Decided: true, Winner: a}
+	case remaining > 0 && winsB > winsA+remaining:
+		return MatchState{Decided: true, Winner: b}
+	case remaining <= 0 && winsA > winsB:
+		return MatchState{Decided: true, Winner: a}
+	case remaining <= 0 && winsB > winsA:
+		return MatchState{Decided: true, Winner: b}
+	case remaining <= 0 && extra >= tiebreaks:
+		return MatchState{Decided: true, Winner: better}
+	}
+
+	last := games[len(games)-1]
+	return MatchState{
+		Next:     &Pairing{Player1: last.Player2, Player2: last.Player1},
+		Tiebreak: remaining <= 0,
+	}
+}
+
+// matches groups a round's games by board, each ordered by game number
+func matches(games []models.TournamentGame, round int) map[int][]models.TournamentGame {
+	byBoard := make(map[int][]models.TournamentGame)
+	for _, g := range games {
+		if g.Round == round {
+			byBoard[g.Board] = append(byBoard[g.Board], g)
+		}
+	}
+	for _, match := range byBoard {
+		sort.Slice(match, func(i, j int) bool { return match[i].Game < match[j].Game })
+	}
+	return byBoard
+}
+
+// seedsOf maps each player of a tournament to their seed
+func seedsOf(t *models.Tournament) map[string]int {
+	seeds := make(map[string]int, len(t.Players))
+	for _, p := range t.Players {
+		seeds[p.Username] = p.Seed
+	}
+	return seeds
+}
+
+// NextRoundPairings pairs the winners of a finished knockout round: board k
+// of the next round is the winners of boards 2k-1 and 2k, with the better
+// seed moving first. ok is false while any match of the round is undecided.
+func NextRoundPairings(t *models.Tournament, round int) (_ []Pairing, ok bool) {
+	seeds := seedsOf(t)
+	byBoard := matches(t.Games, round)
+
+	winners := make([]string, len(byBoard))
+	for board, match := range byBoard {
+		state := DecideMatch(match, t.GamesPerMatch, t.Tiebreaks, seeds)
+		if !state.Decided || board < 1 || board > len(winners) {
+			return nil, false
+		}
+		winners[board-1] = state.Winner
+	}
+
+	pairs := make([]Pairing, 0, len(winners)/2)
+	for i := 0; i+1 < len(winners); i += 2 {
+		p := Pairing{Player1: winners[i], Player2: winners[i+1]}
+		if seeds[p.Player2] < seeds[p.Player1] {
+			p.Player1, p.Player2 = p.Player2, p.Player1
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, true
+}
+
+// KnockoutStandings ranks a knockout tournament: players who went further
+// rank higher, the champion first, and players knocked out in the same
+// round are ordered by seed. Game counts and points are as for Swiss.
+func KnockoutStandings(t *models.Tournament) []Standing {
+	seeds := seedsOf(t)
+	base := ComputeStandings(usernames(t), t.Games)
+
+	// Round each player was knocked out in; players still in rank above all
+	out := make(map[string]int, len(base))
+	for round := 1; round <= t.CurrentRound; round++ {
+		for _, match := range matches(t.Games, round) {
+			state := DecideMatch(match, t.GamesPerMatch, t.Tiebreaks, seeds)
+			if !state.Decided {
+				continue
+			}
+			for _, username := range []string{match[0].Player1, match[0].Player2} {
+				if username != "" && username != state.Winner {
+					out[username] = round
+				}
+			}
+		}
+	}
+	reached := func(username string) int {
+		if round, ok := out[username]; ok {
+			return round
+		}
+		return t.Rounds + 1
+	}
+
+	sort.Slice(base, func(i, j int) bool {
+		a, b := base[i], base[j]
+		if ra, rb := reached(a.Username), reached(b.Username); ra != rb {
+			return ra > rb
+		}
+		if seeds[a.Username] != seeds[b.Username] {
+			return seeds[a.Username] < seeds[b.Username]
+		}
+		return a.Username < b.Username
+	})
+	for i := range base {
+		base[i].Rank = i + 1
+		base[i].Seed = seeds[base[i].Username]
+	}
+	return base
+}
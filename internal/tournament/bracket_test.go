@@ -0,0 +1,136 @@
+package tournament
+
+import (
+	"reflect"
+	"testing"
+
+	"connect-four/internal/models"
+)
+
+func TestSeedOrder(t *testing.T) {
+	want := []int{1, 8, 4, 5, 2, 7, 3, 6}
+	if got := SeedOrder(8); !reflect.DeepEqual(got, want) {
+		t.Errorf("SeedOrder(8) = %v, want %v", got, want)
+	}
+}
+
+func TestFirstRoundPairingsGivesTopSeedsByes(t *testing.T) {
+	pairs := FirstRoundPairings([]string{"s1", "s2", "s3", "s4", "s5", "s6"})
+	want := []Pairing{
+		{Player1: "s1"},
+		{Player1: "s4", Player2: "s5"},
+		{Player1: "s2"},
+		{Player1: "s3", Player2: "s6"},
+	}
+	if !reflect.DeepEqual(pairs, want) {
+		t.Errorf("got %+v, want %+v", pairs, want)
+	}
+	if KnockoutRounds(6) != 3 || BracketSize(6) != 8 {
+		t.Errorf("6 players: %d rounds in a bracket of %d, want 3 and 8", KnockoutRounds(6), BracketSize(6))
+	}
+}
+
+func match(results ...models.TournamentGameResult) []models.TournamentGame {
+	games := make([]models.TournamentGame, len(results))
+	for i, r := range results {
+		p1, p2 := "a", "b"
+		if i%2 == 1 {
+			p1, p2 = p2, p1
+		}
+		games[i] = models.TournamentGame{Game: i + 1, Player1: p1, Player2: p2, Result: r}
+	}
+	return games
+}
+
+func TestDecideMatch(t *testing.T) {
+	seeds := map[string]int{"a": 1, "b": 2}
+
+	// Pending game: nothing to do yet
+	if s := DecideMatch(match(models.TournamentResultPending), 1, 1, seeds); s.Decided || s.Next != nil {
+		t.Errorf("pending: %+v", s)
+	}
+
+	// Best of 3: b wins game 1 as Player2, a wins game 2 as Player2
+	s := DecideMatch(match(models.TournamentResultPlayer2, models.TournamentResultPlayer2), 3, 1, seeds)
+	if s.Decided || s.Next == nil || s.Tiebreak {
+		t.Fatalf("1-1 in best of 3 should continue: %+v", s)
+	}
+	if s.Next.Player1 != "a" || s.Next.Player2 != "b" {
+		t.Errorf("colors should swap from the previous game: %+v", s.Next)
+	}
+
+	// Clinched after two wins, third game not needed
+	s = DecideMatch(match(models.TournamentResultPlayer2, models.TournamentResultPlayer1), 3, 1, seeds)
+	if !s.Decided || s.Winner != "b" {
+		t.Errorf("b should clinch 2-0: %+v", s)
+	}
+
+	// Single game drawn: tiebreak with swapped colors
+	s = DecideMatch(match(models.TournamentResultDraw), 1, 1, seeds)
+	if s.Decided || s.Next == nil || !s.Tiebreak || s.Next.Player1 != "b" {
+		t.Errorf("draw should lead to a tiebreak: %+v", s)
+	}
+
+	// Tiebreaks exhausted: better seed advances
+	games := match(models.TournamentResultDraw, models.TournamentResultDraw)
+	games[1].Tiebreak = true
+	s = DecideMatch(games, 1, 1, seeds)
+	if !s.Decided || s.Winner != "a" {
+		t.Errorf("level after tiebreaks should go to seed 1: %+v", s)
+	}
+
+	// A resigned game is an ordinary loss, the match goes on
+	s = DecideMatch(match(models.TournamentResultPlayer1), 3, 1, seeds)
+	if s.Decided || s.Next == nil {
+		t.Errorf("one lost game of three shouldn't decide the match: %+v", s)
+	}
+
+	// Forfeit concedes the whole match
+	games = match(models.TournamentResultPlayer1)
+	games[0].Forfeit = true
+	s = DecideMatch(games, 3, 1, seeds)
+	if !s.Decided || s.Winner != "a" {
+		t.Errorf("forfeit should decide the match: %+v", s)
+	}
+
+	// Bye
+	s = DecideMatch([]models.TournamentGame{{Player1: "a", Result: models.TournamentResultBye}}, 3, 1, seeds)
+	if !s.Decided || s.Winner != "a" {
+		t.Errorf("bye should advance: %+v", s)
+	}
+}
+
+func TestKnockoutAdvancementAndStandings(t *testing.T) {
+	tr := &models.Tournament{
+		Format:        models.TournamentFormatSingleElimination,
+		Rounds:        2,
+		CurrentRound:  2,
+		GamesPerMatch: 1,
+		Tiebreaks:     1,
+		Players: []models.TournamentPlayer{
+			{Username: "s1", Seed: 1}, {Username: "s2", Seed: 2}, {Username: "s3", Seed: 3},
+		},
+		Games: []models.TournamentGame{
+			{Round: 1, Board: 1, Game: 1, Player1: "s1", Result: models.TournamentResultBye},
+			{Round: 1, Board: 2, Game: 1, Player1: "s2", Player2: "s3", Result: models.TournamentResultDraw},
+			{Round: 1, Board: 2, Game: 2, Player1: "s3", Player2: "s2", Result: models.TournamentResultPlayer1, Tiebreak: true},
+		},
+	}
+
+	pairs, ok := NextRoundPairings(tr, 1)
+	if !ok || len(pairs) != 1 || pairs[0] != (Pairing{Player1: "s1", Player2: "s3"}) {
+		t.Fatalf("final pairing: %+v ok=%v", pairs, ok)
+	}
+
+	tr.Games = append(tr.Games, models.TournamentGame{Round: 2, Board: 1, Game: 1, Player1: "s1", Player2: "s3", Result: models.TournamentResultPlayer2})
+	standings := KnockoutStandings(tr)
+	order := []string{"s3", "s1", "s2"}
+	for i, username := range order {
+		if standings[i].Username != username || standings[i].Rank != i+1 {
+			t.Fatalf("rank %d: got %s, want %s (%+v)", i+1, standings[i].Username, username, standings)
+		}
+	}
+	if standings[0].Seed != 3 {
+		t.Errorf("champion seed = %d, want 3", standings[0].Seed)
+	}
+}
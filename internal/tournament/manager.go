@@ -0,0 +1,608 @@
+package tournament
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/rating"
+	"connect-four/internal/repository"
+)
+
+// Errors returned by tournament operations
+var (
+	ErrNotFound          = errors.New("tournament not found")
+	ErrInvalidFormat     = errors.New("format must be swiss, round_robin or single_elimination")
+	ErrInvalidName       = errors.New("name is required (max 100 chars)")
+	ErrInvalidSeeding    = errors.New("seeding must be rating or wins")
+	ErrInvalidMatch      = errors.New("gamesPerMatch must be 1 to 7 and tiebreaks 0 to 5")
+	ErrNotRegistering    = errors.New("tournament is not open for registration")
+	ErrAlreadyRegistered = errors.New("player already registered")
+	ErrNotEnoughPlayers  = errors.New("at least two players are needed")
+)
+
+// GameHost starts tournament games on the live server and reaches players
+type GameHost interface {
+	// Available reports whether a player is connected and not in a game
+	Available(username string) bool
+	// StartTournamentGame starts a live game and returns its ID
+	StartTournamentGame(player1, player2 string, info models.TournamentInfoPayload) (uuid.UUID, error)
+	// Notify sends a message to a player if they are connected
+	Notify(username string, msgType models.WSMessageType, payload interface{})
+}
+
+// Knockout match defaults
+const (
+	DefaultGamesPerMatch = 1
+	DefaultTiebreaks     = 1
+	maxGamesPerMatch     = 7
+	maxTiebreaks         = 5
+)
+
+// Settings describe a new tournament. Rounds only applies to Swiss; 0 picks
+// a count from the field size when the tournament starts. Seeding,
+// GamesPerMatch and Tiebreaks only apply to single elimination.
+type Settings struct {
+	Name          string
+	Format        models.TournamentFormat
+	Rounds        int
+	Seeding       models.TournamentSeeding
+	GamesPerMatch int
+	Tiebreaks     int
+}
+
+// Manager runs tournaments: it pairs rounds, starts their games after a
+// short delay and advances when every game of the round has a result.
+// All state lives in the database, so any instance can pick up a result
+// and a restarted server carries on where it stopped (see Resume).
+type Manager struct {
+	repo       *repository.TournamentRepository
+	playerRepo *repository.PlayerRepository
+	host       GameHost
+	roundDelay time.Duration
+
+	// Serializes round changes so a round can't advance twice
+	mu sync.Mutex
+}
+
+// NewManager creates a tournament manager
+func NewManager(repo *repository.TournamentRepository, playerRepo *repository.PlayerRepository, host GameHost, roundDelay time.Duration) *Manager {
+	return &Manager{repo: repo, playerRepo: playerRepo, host: host, roundDelay: roundDelay}
+}
+
+// Create opens a new tournament for registration
+func (m *Manager) Create(ctx context.Context, s Settings) (*models.Tournament, error) {
+	name := strings.TrimSpace(s.Name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidName
+	}
+
+	t := &models.Tournament{
+		Name:          name,
+		Format:        s.Format,
+		Rounds:        s.Rounds,
+		GamesPerMatch: 1,
+		Status:        models.TournamentStatusRegistering,
+	}
+	switch s.Format {
+	case models.TournamentFormatSwiss, models.TournamentFormatRoundRobin:
+		if t.Rounds < 0 {
+			t.Rounds = 0
+		}
+	case models.TournamentFormatSingleElimination:
+		switch s.Seeding {
+		case models.TournamentSeedingRating, models.TournamentSeedingWins:
+		case "":
+			s.Seeding = models.TournamentSeedingRating
+		default:
+			return nil, ErrInvalidSeeding
+		}
+		if s.GamesPerMatch < 1 || s.GamesPerMatch > maxGamesPerMatch || s.Tiebreaks < 0 || s.Tiebreaks > maxTiebreaks {
+			return nil, ErrInvalidMatch
+		}
+		t.Rounds = 0
+		t.Seeding = s.Seeding
+		t.GamesPerMatch = s.GamesPerMatch
+		t.Tiebreaks = s.Tiebreaks
+	default:
+		return nil, ErrInvalidFormat
+	}
+
+	if err := m.repo.Create(ctx, t); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("tournamentId", t.ID.String()).Str("name", name).Str("format", string(t.Format)).Msg("Tournament created")
+	return t, nil
+}
+
+// Get returns a tournament with its players and pairings
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.Tournament, error) {
+	t, err := m.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, ErrNotFound
+	}
+	return t, nil
+}
+
+// List returns the most recent tournaments
+func (m *Manager) List(ctx context.Context, limit int) ([]models.Tournament, error) {
+	return m.repo.List(ctx, limit)
+}
+
+// Standings returns the current ranking of a tournament
+func (m *Manager) Standings(ctx context.Context, id uuid.UUID) ([]Standing, error) {
+	t, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return standingsOf(t), nil
+}
+
+// Register adds a player to a tournament that hasn't started
+func (m *Manager) Register(ctx context.Context, id uuid.UUID, username string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != models.TournamentStatusRegistering {
+		return ErrNotRegistering
+	}
+	for _, p := range t.Players {
+		if p.Username == username {
+			return ErrAlreadyRegistered
+		}
+	}
+
+	if err := m.repo.AddPlayer(ctx, &models.TournamentPlayer{TournamentID: id, Username: username}); err != nil {
+		return err
+	}
+
+	log.Info().Str("tournamentId", id.String()).Str("username", username).Msg("Player registered for tournament")
+	return nil
+}
+
+// Start closes registration and pairs the first round
+func (m *Manager) Start(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, err := m.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != models.TournamentStatusRegistering {
+		return ErrNotRegistering
+	}
+	if len(t.Players) < 2 {
+		return ErrNotEnoughPlayers
+	}
+
+	switch t.Format {
+	case models.TournamentFormatRoundRobin:
+		t.Rounds = RoundRobinRounds(len(t.Players))
+	case models.TournamentFormatSwiss:
+		if t.Rounds == 0 || t.Rounds > len(t.Players)-1 {
+			t.Rounds = SwissRounds(len(t.Players))
+		}
+	case models.TournamentFormatSingleElimination:
+		t.Rounds = KnockoutRounds(len(t.Players))
+		if err := m.seed(ctx, t); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	t.Status = models.TournamentStatusRunning
+	t.StartedAt = &now
+
+	log.Info().Str("tournamentId", id.String()).Int("players", len(t.Players)).Int("rounds", t.Rounds).Msg("Tournament started")
+	return m.startRound(ctx, t, 1)
+}
+
+// GameFinished records the result of a live game if it belongs to a
+// tournament. winner is game.Empty for a draw; abandoned is set when the
+// loser left the match rather than resigning the game, which in a knockout
+// concedes the whole match. A resignation only loses the one game.
+func (m *Manager) GameFinished(ctx context.Context, gameID uuid.UUID, winner game.Cell, abandoned bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, err := m.repo.GetGameByGameID(ctx, gameID)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", gameID.String()).Msg("Failed to look up tournament game")
+		return
+	}
+	if g == nil || g.Result != models.TournamentResultPending {
+		return
+	}
+
+	switch winner {
+	case game.Player1:
+		g.Result = models.TournamentResultPlayer1
+	case game.Player2:
+		g.Result = models.TournamentResultPlayer2
+	default:
+		g.Result = models.TournamentResultDraw
+	}
+	g.Forfeit = abandoned && g.Result != models.TournamentResultDraw
+	if err := m.repo.UpdateGame(ctx, g); err != nil {
+		log.Error().Err(err).Str("gameId", gameID.String()).Msg("Failed to record tournament result")
+		return
+	}
+
+	t, err := m.Get(ctx, g.TournamentID)
+	if err != nil {
+		log.Error().Err(err).Str("tournamentId", g.TournamentID.String()).Msg("Failed to load tournament")
+		return
+	}
+	m.advanceIfComplete(ctx, t)
+}
+
+// Resume picks running tournaments back up after a restart. Live games
+// don't survive a restart, so pairings that were being played are
+// scheduled again; results recorded before the restart still advance the
+// tournament.
+func (m *Manager) Resume(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	running, err := m.repo.ListByStatus(ctx, models.TournamentStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range running {
+		t, err := m.Get(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+
+		var replay []models.TournamentGame
+		for i := range t.Games {
+			g := &t.Games[i]
+			if g.Round != t.CurrentRound || g.Result != models.TournamentResultPending {
+				continue
+			}
+			if g.GameID != nil {
+				g.GameID = nil
+				if err := m.repo.UpdateGame(ctx, g); err != nil {
+					return err
+				}
+			}
+			replay = append(replay, *g)
+		}
+
+		if len(replay) > 0 {
+			m.announce(t, replay)
+			id, round := t.ID, t.CurrentRound
+			time.AfterFunc(m.roundDelay, func() { m.launchRound(id, round) })
+		}
+		m.advanceIfComplete(ctx, t)
+
+		log.Info().Str("tournamentId", t.ID.String()).Int("round", t.CurrentRound).Int("games", len(replay)).Msg("Tournament resumed")
+	}
+	return nil
+}
+
+// seed ranks a knockout field by rating or career wins; players with equal
+// records keep registration order. Caller must hold m.mu.
+func (m *Manager) seed(ctx context.Context, t *models.Tournament) error {
+	strength := make(map[string]int, len(t.Players))
+	for _, p := range t.Players {
+		player, err := m.playerRepo.GetByUsername(ctx, p.Username)
+		if err != nil {
+			return err
+		}
+		switch {
+		case player == nil && t.Seeding == models.TournamentSeedingRating:
+			strength[p.Username] = rating.DefaultRating
+		case player == nil:
+			strength[p.Username] = 0
+		case t.Seeding == models.TournamentSeedingWins:
+			strength[p.Username] = player.Wins
+		default:
+			strength[p.Username] = player.Rating
+		}
+	}
+
+	sort.SliceStable(t.Players, func(i, j int) bool {
+		return strength[t.Players[i].Username] > strength[t.Players[j].Username]
+	})
+	for i := range t.Players {
+		t.Players[i].Seed = i + 1
+		if err := m.repo.UpdatePlayer(ctx, &t.Players[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startRound pairs a round, stores it and schedules its games. Caller must
+// hold m.mu.
+func (m *Manager) startRound(ctx context.Context, t *models.Tournament, round int) error {
+	var pairings []Pairing
+	switch t.Format {
+	case models.TournamentFormatRoundRobin:
+		pairings = RoundRobinPairings(usernames(t), round)
+	case models.TournamentFormatSingleElimination:
+		if round == 1 {
+			pairings = FirstRoundPairings(seeded(t))
+		} else if next, ok := NextRoundPairings(t, round-1); ok {
+			pairings = next
+		} else {
+			return errors.New("previous knockout round is undecided")
+		}
+	default:
+		pairings = PairSwiss(ComputeStandings(usernames(t), t.Games))
+	}
+
+	games := make([]models.TournamentGame, 0, len(pairings))
+	for i, p := range pairings {
+		g := models.TournamentGame{
+			TournamentID: t.ID,
+			Round:        round,
+			Board:        i + 1,
+			Game:         1,
+			Player1:      p.Player1,
+			Player2:      p.Player2,
+			Result:       models.TournamentResultPending,
+		}
+		if p.Player2 == "" {
+			g.Result = models.TournamentResultBye
+		}
+		games = append(games, g)
+	}
+
+	t.CurrentRound = round
+	if err := m.schedule(ctx, t, games); err != nil {
+		return err
+	}
+
+	log.Info().Str("tournamentId", t.ID.String()).Int("round", round).Int("games", len(games)).Msg("Tournament round paired")
+	return nil
+}
+
+// schedule stores new games of the current round, tells their players and
+// starts them after the round delay. Caller must hold m.mu.
+func (m *Manager) schedule(ctx context.Context, t *models.Tournament, games []models.TournamentGame) error {
+	if err := m.repo.CreateGames(ctx, games); err != nil {
+		return err
+	}
+	if err := m.repo.Update(ctx, t); err != nil {
+		return err
+	}
+	t.Games = append(t.Games, games...)
+
+	m.announce(t, games)
+
+	id, round := t.ID, t.CurrentRound
+	time.AfterFunc(m.roundDelay, func() { m.launchRound(id, round) })
+	return nil
+}
+
+// announce tells every player of the given games who they play next
+func (m *Manager) announce(t *models.Tournament, games []models.TournamentGame) {
+	for _, g := range games {
+		info := gameInfo(t, &g)
+		if g.Result == models.TournamentResultBye {
+			m.host.Notify(g.Player1, models.WSTypeTournamentBye, models.TournamentByePayload{TournamentInfoPayload: info})
+			continue
+		}
+		if g.Result != models.TournamentResultPending {
+			continue
+		}
+		m.host.Notify(g.Player1, models.WSTypeTournamentNextGame, models.TournamentNextGamePayload{
+			TournamentInfoPayload: info,
+			Opponent:              g.Player2,
+			YourColor:             int(game.Player1),
+			StartsIn:              int(m.roundDelay.Seconds()),
+		})
+		m.host.Notify(g.Player2, models.WSTypeTournamentNextGame, models.TournamentNextGamePayload{
+			TournamentInfoPayload: info,
+			Opponent:              g.Player1,
+			YourColor:             int(game.Player2),
+			StartsIn:              int(m.roundDelay.Seconds()),
+		})
+	}
+}
+
+// launchRound starts the pending games of a round. A player who isn't
+// connected or is busy in another game forfeits; if neither player is
+// available the pairing is a double forfeit.
+func (m *Manager) launchRound(id uuid.UUID, round int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx := context.Background()
+	t, err := m.Get(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("tournamentId", id.String()).Msg("Failed to load tournament")
+		return
+	}
+	if t.Status != models.TournamentStatusRunning || t.CurrentRound != round {
+		return
+	}
+
+	for i := range t.Games {
+		g := &t.Games[i]
+		if g.Round != round || g.Result != models.TournamentResultPending || g.GameID != nil {
+			continue
+		}
+
+		p1Ready, p2Ready := m.host.Available(g.Player1), m.host.Available(g.Player2)
+		if p1Ready && p2Ready {
+			gameID, err := m.host.StartTournamentGame(g.Player1, g.Player2, gameInfo(t, g))
+			if err == nil {
+				g.GameID = &gameID
+				if err := m.repo.UpdateGame(ctx, g); err != nil {
+					log.Error().Err(err).Str("gameId", gameID.String()).Msg("Failed to link tournament game")
+				}
+				continue
+			}
+			log.Warn().Err(err).Str("player1", g.Player1).Str("player2", g.Player2).Msg("Failed to start tournament game")
+			p1Ready, p2Ready = m.host.Available(g.Player1), m.host.Available(g.Player2)
+		}
+
+		switch {
+		case p1Ready && !p2Ready:
+			g.Result = models.TournamentResultPlayer1
+		case p2Ready && !p1Ready:
+			g.Result = models.TournamentResultPlayer2
+		default:
+			g.Result = models.TournamentResultDoubleForfeit
+		}
+		g.Forfeit = true
+		if err := m.repo.UpdateGame(ctx, g); err != nil {
+			log.Error().Err(err).Msg("Failed to record tournament forfeit")
+		}
+		log.Info().
+			Str("tournamentId", id.String()).
+			Str("player1", g.Player1).
+			Str("player2", g.Player2).
+			Str("result", string(g.Result)).
+			Msg("Tournament game forfeited (player unavailable)")
+	}
+
+	m.advanceIfComplete(ctx, t)
+}
+
+// advanceIfComplete pairs the next round or finishes the tournament once
+// every game of the current round has a result. Caller must hold m.mu.
+func (m *Manager) advanceIfComplete(ctx context.Context, t *models.Tournament) {
+	if t.Status != models.TournamentStatusRunning {
+		return
+	}
+	if t.Format == models.TournamentFormatSingleElimination && m.continueMatches(ctx, t) {
+		return
+	}
+	for _, g := range t.Games {
+		if g.Round == t.CurrentRound && g.Result == models.TournamentResultPending {
+			return
+		}
+	}
+
+	if t.CurrentRound < t.Rounds {
+		if err := m.startRound(ctx, t, t.CurrentRound+1); err != nil {
+			log.Error().Err(err).Str("tournamentId", t.ID.String()).Msg("Failed to start tournament round")
+		}
+		return
+	}
+	m.finish(ctx, t)
+}
+
+// continueMatches schedules the next game of every knockout match in the
+// current round that is level or not yet clinched. It reports whether any
+// game was added. Caller must hold m.mu.
+func (m *Manager) continueMatches(ctx context.Context, t *models.Tournament) bool {
+	seeds := seedsOf(t)
+	var next []models.TournamentGame
+	for board, match := range matches(t.Games, t.CurrentRound) {
+		state := DecideMatch(match, t.GamesPerMatch, t.Tiebreaks, seeds)
+		if state.Next == nil {
+			continue
+		}
+		next = append(next, models.TournamentGame{
+			TournamentID: t.ID,
+			Round:        t.CurrentRound,
+			Board:        board,
+			Game:         match[len(match)-1].Game + 1,
+			Tiebreak:     state.Tiebreak,
+			Player1:      state.Next.Player1,
+			Player2:      state.Next.Player2,
+			Result:       models.TournamentResultPending,
+		})
+	}
+	if len(next) == 0 {
+		return false
+	}
+	sort.Slice(next, func(i, j int) bool { return next[i].Board < next[j].Board })
+
+	if err := m.schedule(ctx, t, next); err != nil {
+		log.Error().Err(err).Str("tournamentId", t.ID.String()).Msg("Failed to schedule knockout games")
+	}
+	return true
+}
+
+// finish closes a tournament and tells every player where they placed.
+// Caller must hold m.mu.
+func (m *Manager) finish(ctx context.Context, t *models.Tournament) {
+	standings := standingsOf(t)
+
+	now := time.Now()
+	t.Status = models.TournamentStatusFinished
+	t.EndedAt = &now
+	if len(standings) > 0 {
+		t.WinnerUsername = standings[0].Username
+	}
+	if err := m.repo.Update(ctx, t); err != nil {
+		log.Error().Err(err).Str("tournamentId", t.ID.String()).Msg("Failed to finish tournament")
+		return
+	}
+
+	for _, s := range standings {
+		m.host.Notify(s.Username, models.WSTypeTournamentFinished, models.TournamentFinishedPayload{
+			TournamentID: t.ID.String(),
+			Name:         t.Name,
+			Winner:       t.WinnerUsername,
+			Rank:         s.Rank,
+			Points:       s.Points,
+		})
+	}
+
+	log.Info().Str("tournamentId", t.ID.String()).Str("winner", t.WinnerUsername).Msg("Tournament finished")
+}
+
+// usernames lists a tournament's players in registration order
+func usernames(t *models.Tournament) []string {
+	names := make([]string, 0, len(t.Players))
+	for _, p := range t.Players {
+		names = append(names, p.Username)
+	}
+	return names
+}
+
+// seeded lists a tournament's players by seed, best first
+func seeded(t *models.Tournament) []string {
+	players := append([]models.TournamentPlayer(nil), t.Players...)
+	sort.SliceStable(players, func(i, j int) bool { return players[i].Seed < players[j].Seed })
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		names = append(names, p.Username)
+	}
+	return names
+}
+
+// standingsOf ranks a tournament the way its format does
+func standingsOf(t *models.Tournament) []Standing {
+	if t.Format == models.TournamentFormatSingleElimination {
+		return KnockoutStandings(t)
+	}
+	return ComputeStandings(usernames(t), t.Games)
+}
+
+func gameInfo(t *models.Tournament, g *models.TournamentGame) models.TournamentInfoPayload {
+	info := models.TournamentInfoPayload{
+		TournamentID: t.ID.String(),
+		Name:         t.Name,
+		Round:        g.Round,
+	}
+	if t.Format == models.TournamentFormatSingleElimination {
+		info.Game = g.Game
+		info.Tiebreak = g.Tiebreak
+	}
+	return info
+}
@@ -0,0 +1,155 @@
+package tournament
+
+import "sort"
+
+// Pairing is one game of a round; Player2 is empty for a bye
+type Pairing struct {
+	Player1 string
+	Player2 string
+}
+
+// SwissRounds returns the default number of Swiss rounds for a field:
+// enough to separate a single winner, never more than a round robin
+func SwissRounds(players int) int {
+	rounds := 0
+	for n := 1; n < players; n *= 2 {
+		rounds++
+	}
+	if rounds > players-1 {
+		rounds = players - 1
+	}
+	return rounds
+}
+
+// PairSwiss pairs the next Swiss round from the current standings (best
+// first). With an odd field the lowest-ranked player without a bye sits out.
+// Players are paired top-down with the nearest opponent they haven't met;
+// if no such pairing exists, repeat games are allowed as a last resort.
+// Color goes to whoever has moved first less often.
+func PairSwiss(standings []Standing) []Pairing {
+	byUsername := make(map[string]*Standing, len(standings))
+	order := make([]string, 0, len(standings))
+	for i := range standings {
+		byUsername[standings[i].Username] = &standings[i]
+		order = append(order, standings[i].Username)
+	}
+
+	var bye string
+	if len(order)%2 == 1 {
+		bye = order[len(order)-1]
+		for i := len(order) - 1; i >= 0; i-- {
+			if !byUsername[order[i]].HadBye {
+				bye = order[i]
+				break
+			}
+		}
+		order = without(order, bye)
+	}
+
+	met := func(a, b string) bool {
+		for _, opponent := range byUsername[a].Opponents {
+			if opponent == b {
+				return true
+			}
+		}
+		return false
+	}
+
+	pairs, ok := pairRemaining(order, met)
+	if !ok {
+		pairs = pairRemainingAllowRepeats(order)
+	}
+
+	for i, p := range pairs {
+		if byUsername[p.Player1].AsPlayer1 > byUsername[p.Player2].AsPlayer1 {
+			pairs[i] = Pairing{Player1: p.Player2, Player2: p.Player1}
+		}
+	}
+	if bye != "" {
+		pairs = append(pairs, Pairing{Player1: bye})
+	}
+	return pairs
+}
+
+// pairRemaining pairs the first player with the best-ranked opponent that
+// leaves the rest pairable, backtracking when a choice dead-ends
+func pairRemaining(players []string, met func(a, b string) bool) ([]Pairing, bool) {
+	if len(players) == 0 {
+		return nil, true
+	}
+	first := players[0]
+	for i := 1; i < len(players); i++ {
+		if met(first, players[i]) {
+			continue
+		}
+		rest := make([]string, 0, len(players)-2)
+		rest = append(rest, players[1:i]...)
+		rest = append(rest, players[i+1:]...)
+		if pairs, ok := pairRemaining(rest, met); ok {
+			return append([]Pairing{{Player1: first, Player2: players[i]}}, pairs...), true
+		}
+	}
+	return nil, false
+}
+
+// pairRemainingAllowRepeats pairs neighbours in ranking order
+func pairRemainingAllowRepeats(players []string) []Pairing {
+	pairs := make([]Pairing, 0, len(players)/2)
+	for i := 0; i+1 < len(players); i += 2 {
+		pairs = append(pairs, Pairing{Player1: players[i], Player2: players[i+1]})
+	}
+	return pairs
+}
+
+// RoundRobinRounds returns how many rounds a full round robin takes
+func RoundRobinRounds(players int) int {
+	if players%2 == 1 {
+		return players
+	}
+	return players - 1
+}
+
+// RoundRobinPairings returns the pairings of a round (1-based) using the
+// circle method: the first player stays put and the others rotate, so over
+// RoundRobinRounds rounds everyone meets everyone exactly once. Colors
+// alternate between rounds; with an odd field one player has a bye.
+func RoundRobinPairings(players []string, round int) []Pairing {
+	list := append([]string(nil), players...)
+	sort.Strings(list)
+	if len(list)%2 == 1 {
+		list = append(list, "")
+	}
+	n := len(list)
+	if n < 2 {
+		return nil
+	}
+
+	rotated := make([]string, n)
+	rotated[0] = list[0]
+	for i := 1; i < n; i++ {
+		rotated[i] = list[1+(i-1+round-1)%(n-1)]
+	}
+
+	pairs := make([]Pairing, 0, n/2)
+	for i := 0; i < n/2; i++ {
+		a, b := rotated[i], rotated[n-1-i]
+		if round%2 == 0 {
+			a, b = b, a
+		}
+		if a == "" {
+			a, b = b, a
+		}
+		pairs = append(pairs, Pairing{Player1: a, Player2: b})
+	}
+	return pairs
+}
+
+func without(list []string, name string) []string {
+	out := make([]string, 0, len(list))
+	for _, s := range list {
+		if s != name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package tournament
+
+import (
+	"fmt"
+	"testing"
+
+	"connect-four/internal/models"
+)
+
+func pairKey(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	return a + "|" + b
+}
+
+func TestRoundRobinEveryoneMeetsOnce(t *testing.T) {
+	for _, n := range []int{2, 3, 4, 5, 8} {
+		players := make([]string, n)
+		for i := range players {
+			players[i] = fmt.Sprintf("p%d", i)
+		}
+
+		met := make(map[string]int)
+		byes := make(map[string]int)
+		for round := 1; round <= RoundRobinRounds(n); round++ {
+			seen := make(map[string]bool)
+			for _, p := range RoundRobinPairings(players, round) {
+				if seen[p.Player1] || (p.Player2 != "" && seen[p.Player2]) {
+					t.Fatalf("n=%d round %d: player paired twice", n, round)
+				}
+				seen[p.Player1] = true
+				if p.Player2 == "" {
+					byes[p.Player1]++
+					continue
+				}
+				seen[p.Player2] = true
+				met[pairKey(p.Player1, p.Player2)]++
+			}
+		}
+
+		if want := n * (n - 1) / 2; len(met) != want {
+			t.Errorf("n=%d: %d distinct pairings, want %d", n, len(met), want)
+		}
+		for k, c := range met {
+			if c != 1 {
+				t.Errorf("n=%d: %s met %d times", n, k, c)
+			}
+		}
+		if n%2 == 1 {
+			for _, p := range players {
+				if byes[p] != 1 {
+					t.Errorf("n=%d: %s had %d byes, want 1", n, p, byes[p])
+				}
+			}
+		}
+	}
+}
+
+func TestSwissRounds(t *testing.T) {
+	cases := map[int]int{2: 1, 3: 2, 4: 2, 5: 3, 8: 3, 9: 4, 16: 4}
+	for players, want := range cases {
+		if got := SwissRounds(players); got != want {
+			t.Errorf("SwissRounds(%d) = %d, want %d", players, got, want)
+		}
+	}
+}
+
+func TestPairSwissAvoidsRepeatsAndSpreadsByes(t *testing.T) {
+	players := []string{"a", "b", "c", "d", "e"}
+	var games []models.TournamentGame
+
+	for round := 1; round <= 4; round++ {
+		pairs := PairSwiss(ComputeStandings(players, games))
+		for _, p := range pairs {
+			g := models.TournamentGame{Round: round, Player1: p.Player1, Player2: p.Player2, Result: models.TournamentResultPlayer1}
+			if p.Player2 == "" {
+				g.Result = models.TournamentResultBye
+			}
+			games = append(games, g)
+		}
+	}
+
+	met := make(map[string]bool)
+	byes := make(map[string]int)
+	for _, g := range games {
+		if g.Player2 == "" {
+			byes[g.Player1]++
+			continue
+		}
+		k := pairKey(g.Player1, g.Player2)
+		if met[k] {
+			t.Errorf("repeat pairing %s", k)
+		}
+		met[k] = true
+	}
+	for p, n := range byes {
+		if n > 1 {
+			t.Errorf("%s had %d byes", p, n)
+		}
+	}
+}
+
+func TestPairSwissTopDown(t *testing.T) {
+	games := []models.TournamentGame{
+		{Round: 1, Player1: "a", Player2: "b", Result: models.TournamentResultPlayer1},
+		{Round: 1, Player1: "c", Player2: "d", Result: models.TournamentResultPlayer1},
+	}
+	pairs := PairSwiss(ComputeStandings([]string{"a", "b", "c", "d"}, games))
+
+	// Winners meet winners
+	want := map[string]bool{pairKey("a", "c"): true, pairKey("b", "d"): true}
+	for _, p := range pairs {
+		if !want[pairKey(p.Player1, p.Player2)] {
+			t.Errorf("unexpected pairing %s vs %s", p.Player1, p.Player2)
+		}
+	}
+}
+
+func TestPairSwissBalancesColors(t *testing.T) {
+	standings := []Standing{
+		{Username: "a", Points: 1, AsPlayer1: 1},
+		{Username: "b", Points: 1, AsPlayer1: 0},
+	}
+	pairs := PairSwiss(standings)
+	if len(pairs) != 1 || pairs[0].Player1 != "b" {
+		t.Errorf("player who hasn't moved first should get the first move: %+v", pairs)
+	}
+}
@@ -0,0 +1,129 @@
+package tournament
+
+import (
+	"sort"
+
+	"connect-four/internal/models"
+)
+
+// Points awarded per game
+const (
+	PointsWin  = 1.0
+	PointsDraw = 0.5
+)
+
+// Standing is a player's score in a tournament. Ties on points are broken by
+// Buchholz (sum of opponents' points), then Sonneborn-Berger (points of
+// opponents beaten plus half the points of opponents drawn), then wins.
+type Standing struct {
+	Rank            int     `json:"rank"`
+	Username        string  `json:"username"`
+	Points          float64 `json:"points"`
+	Wins            int     `json:"wins"`
+	Draws           int     `json:"draws"`
+	Losses          int     `json:"losses"`
+	Buchholz        float64 `json:"buchholz"`
+	SonnebornBerger float64 `json:"sonnebornBerger"`
+	Seed            int     `json:"seed,omitempty"` // knockout only
+
+	Opponents []string `json:"-"`
+	HadBye    bool     `json:"-"`
+	AsPlayer1 int      `json:"-"` // games moved first, for color balance
+}
+
+// ComputeStandings scores every finished game and returns players ranked
+// best first. Byes count as a win without an opponent; double forfeits count
+// as a loss for both.
+func ComputeStandings(players []string, games []models.TournamentGame) []Standing {
+	byUsername := make(map[string]*Standing, len(players))
+	for _, username := range players {
+		byUsername[username] = &Standing{Username: username}
+	}
+	get := func(username string) *Standing {
+		s, ok := byUsername[username]
+		if !ok {
+			s = &Standing{Username: username}
+			byUsername[username] = s
+		}
+		return s
+	}
+
+	type decided struct {
+		opponent string
+		score    float64
+	}
+	results := make(map[string][]decided)
+
+	for _, g := range games {
+		if g.Result == models.TournamentResultPending {
+			continue
+		}
+		p1 := get(g.Player1)
+		if g.Result == models.TournamentResultBye {
+			p1.Points += PointsWin
+			p1.Wins++
+			p1.HadBye = true
+			continue
+		}
+		p2 := get(g.Player2)
+		p1.Opponents = append(p1.Opponents, p2.Username)
+		p2.Opponents = append(p2.Opponents, p1.Username)
+		p1.AsPlayer1++
+
+		var s1, s2 float64
+		switch g.Result {
+		case models.TournamentResultPlayer1:
+			s1 = PointsWin
+			p1.Wins++
+			p2.Losses++
+		case models.TournamentResultPlayer2:
+			s2 = PointsWin
+			p2.Wins++
+			p1.Losses++
+		case models.TournamentResultDraw:
+			s1, s2 = PointsDraw, PointsDraw
+			p1.Draws++
+			p2.Draws++
+		case models.TournamentResultDoubleForfeit:
+			p1.Losses++
+			p2.Losses++
+		}
+		p1.Points += s1
+		p2.Points += s2
+		results[p1.Username] = append(results[p1.Username], decided{p2.Username, s1})
+		results[p2.Username] = append(results[p2.Username], decided{p1.Username, s2})
+	}
+
+	for username, s := range byUsername {
+		for _, r := range results[username] {
+			opponentPoints := byUsername[r.opponent].Points
+			s.Buchholz += opponentPoints
+			s.SonnebornBerger += r.score * opponentPoints
+		}
+	}
+
+	standings := make([]Standing, 0, len(byUsername))
+	for _, s := range byUsername {
+		standings = append(standings, *s)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Buchholz != b.Buchholz {
+			return a.Buchholz > b.Buchholz
+		}
+		if a.SonnebornBerger != b.SonnebornBerger {
+			return a.SonnebornBerger > b.SonnebornBerger
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Username < b.Username
+	})
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+	return standings
+}
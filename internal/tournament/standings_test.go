@@ -0,0 +1,50 @@
+package tournament
+
+import (
+	"testing"
+
+	"connect-four/internal/models"
+)
+
+func TestComputeStandingsTieBreaks(t *testing.T) {
+	games := []models.TournamentGame{
+		{Round: 1, Player1: "a", Player2: "b", Result: models.TournamentResultPlayer1},
+		{Round: 1, Player1: "c", Player2: "d", Result: models.TournamentResultDraw},
+		{Round: 2, Player1: "a", Player2: "c", Result: models.TournamentResultPlayer2},
+		{Round: 2, Player1: "b", Player2: "d", Result: models.TournamentResultPlayer1},
+	}
+	standings := ComputeStandings([]string{"a", "b", "c", "d"}, games)
+
+	// c: 1.5, a: 1, b: 1, d: 0.5. a and b tie on points; a beat c-level
+	// opposition (Buchholz a = b(1) + c(1.5) = 2.5, b = a(1) + d(0.5) = 1.5)
+	order := []string{"c", "a", "b", "d"}
+	for i, username := range order {
+		if standings[i].Username != username || standings[i].Rank != i+1 {
+			t.Fatalf("rank %d: got %s, want %s (%+v)", i+1, standings[i].Username, username, standings)
+		}
+	}
+	if standings[1].Buchholz != 2.5 || standings[2].Buchholz != 1.5 {
+		t.Errorf("Buchholz a=%v b=%v, want 2.5 and 1.5", standings[1].Buchholz, standings[2].Buchholz)
+	}
+	if standings[0].Points != 1.5 || standings[0].Draws != 1 || standings[0].Wins != 1 {
+		t.Errorf("unexpected score for c: %+v", standings[0])
+	}
+}
+
+func TestComputeStandingsByeAndForfeits(t *testing.T) {
+	games := []models.TournamentGame{
+		{Round: 1, Player1: "a", Result: models.TournamentResultBye},
+		{Round: 1, Player1: "b", Player2: "c", Result: models.TournamentResultDoubleForfeit},
+		{Round: 2, Player1: "b", Player2: "a", Result: models.TournamentResultPending},
+	}
+	standings := ComputeStandings([]string{"a", "b", "c"}, games)
+
+	if standings[0].Username != "a" || standings[0].Points != PointsWin || !standings[0].HadBye {
+		t.Errorf("bye should score a win: %+v", standings[0])
+	}
+	for _, s := range standings[1:] {
+		if s.Points != 0 || s.Losses != 1 {
+			t.Errorf("double forfeit should be a loss for %s: %+v", s.Username, s)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// Errors returned by admin operations
+var (
+	ErrGameNotFound   = errors.New("game not found")
+	ErrGameFinished   = errors.New("game already finished")
+	ErrInvalidResult  = errors.New("result must be draw or the winning seat (player1, player2, ...) still in the game")
+	ErrClientNotFound = errors.New("client not connected")
+)
+
+// SessionPlayer describes one side of a live game for the admin API
+type SessionPlayer struct {
+	Username  string `json:"username"`
+	IsBot     bool   `json:"isBot"`
+	Connected bool   `json:"connected"`
+	Dropped   int64  `json:"dropped"` // messages dropped on this connection
+}
+
+// SessionSummary is a point-in-time view of an active game. Two-player
+// games fill Player1 and Player2; games for three or four players list
+// every seat in Players, in color order, along with their Rule.
+type SessionSummary struct {
+	GameID      string          `json:"gameId"`
+	Player1     *SessionPlayer  `json:"player1,omitempty"`
+	Player2     *SessionPlayer  `json:"player2,omitempty"`
+	Players     []SessionPlayer `json:"players,omitempty"`
+	Rule        string          `json:"rule,omitempty"`
+	IsBot       bool            `json:"isBot"`
+	Status      game.GameStatus `json:"status"`
+	MoveCount   int             `json:"moveCount"`
+	CurrentTurn int             `json:"currentTurn"`
+	StartedAt   time.Time       `json:"startedAt"`
+}
+
+// ListSessions returns a summary of every active game, multiplayer games
+// included, oldest first
+func (h *Hub) ListSessions() []SessionSummary {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	summaries := make([]SessionSummary, 0, len(h.games)+len(h.multiGames))
+	for _, session := range h.games {
+		g := session.Game
+		p1 := sessionPlayer(g.Player1, session.Player1)
+		p2 := sessionPlayer(g.Player2, session.Player2)
+		summaries = append(summaries, SessionSummary{
+			GameID:      g.ID.String(),
+			Player1:     &p1,
+			Player2:     &p2,
+			IsBot:       session.IsBot,
+			Status:      g.GetStatus(),
+			MoveCount:   g.MoveCount(),
+			CurrentTurn: int(g.GetCurrentPlayer()),
+			StartedAt:   g.StartedAt,
+		})
+	}
+
+	for _, session := range h.multiGames {
+		g := session.Game
+		players := make([]SessionPlayer, len(g.Players))
+		for i, info := range g.Players {
+			players[i] = sessionPlayer(info, session.Clients[game.Cell(i+1)])
+		}
+		summaries = append(summaries, SessionSummary{
+			GameID:      g.ID.String(),
+			Players:     players,
+			Rule:        string(g.Rule),
+			Status:      g.GetStatus(),
+			MoveCount:   g.MoveCount(),
+			CurrentTurn: int(g.GetCurrentPlayer()),
+			StartedAt:   g.StartedAt,
+		})
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].StartedAt.Before(summaries[j].StartedAt)
+	})
+	return summaries
+}
+
+// sessionPlayer describes a seat played by info, connected as c (nil if
+// nobody is). Caller must hold h.mu.
+func sessionPlayer(info *game.PlayerInfo, c *Client) SessionPlayer {
+	p := SessionPlayer{
+		Username:  info.Username,
+		IsBot:     info.IsBot,
+		Connected: info.IsBot || (c != nil && !c.closed),
+	}
+	if c != nil {
+		p.Dropped = c.Dropped()
+	}
+	return p
+}
+
+// Kick notifies a connected client and then drops its connection
+func (h *Hub) Kick(username, reason string) error {
+	client := h.GetClient(username)
+	if client == nil {
+		return ErrClientNotFound
+	}
+
+	client.SendMessage(models.WSTypeKicked, models.KickedPayload{Reason: reason})
+	client.Disconnect()
+
+	log.Info().Str("username", username).Str("reason", reason).Msg("Client kicked")
+	return nil
+}
+
+// BroadcastNotice sends a server notice to every connected client and
+// returns how many clients it was sent to
+func (h *Hub) BroadcastNotice(message string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.clients {
+		client.SendMessage(models.WSTypeServerNotice, models.ServerNoticePayload{Message: message})
+	}
+
+	log.Info().Int("recipients", len(h.clients)).Msg("Server notice broadcast")
+	return len(h.clients)
+}
+
+// ForceEndGame ends a live game with the given result and runs the normal
+// game-over flow (notifications, persistence, events, cleanup). In a game
+// for three or four players the result is the seat to put first, any of
+// player1 to player4, or draw for everyone still playing to share the best
+// place left.
+func (h *MessageHandler) ForceEndGame(ctx context.Context, gameID uuid.UUID, result game.GameResult) error {
+	h.hub.mu.RLock()
+	multi := h.hub.multiGames[gameID]
+	h.hub.mu.RUnlock()
+	if multi != nil {
+		return h.forceEndMulti(ctx, multi, result)
+	}
+
+	session := h.hub.GetGameSession(gameID)
+	if session == nil {
+		return ErrGameNotFound
+	}
+
+	switch result {
+	case game.ResultPlayer1Win, game.ResultPlayer2Win, game.ResultDraw:
+	default:
+		return ErrInvalidResult
+	}
+
+	if !session.Game.SetResult(result) {
+		return ErrGameFinished
+	}
+
+	log.Info().Str("gameId", gameID.String()).Str("result", string(result)).Msg("Game force-ended by admin")
+	h.handleGameOver(ctx, session)
+	return nil
+}
+
+// forceEndMulti ends a multiplayer game with result's seat first, or with
+// no winner for a draw
+func (h *MessageHandler) forceEndMulti(ctx context.Context, session *multiSession, result game.GameResult) error {
+	winner := game.Empty
+	if result != game.ResultDraw {
+		for seat := game.Player1; int(seat) <= len(session.Game.Players); seat++ {
+			if string(result) == fmt.Sprintf("player%d", seat) {
+				winner = seat
+			}
+		}
+		if winner == game.Empty {
+			return ErrInvalidResult
+		}
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.Game.IsGameOver() {
+		return ErrGameFinished
+	}
+	if !session.Game.End(winner) {
+		return ErrInvalidResult
+	}
+
+	log.Info().Str("gameId", session.Game.ID.String()).Str("result", string(result)).Msg("Multiplayer game force-ended by admin")
+	h.announcePlacements(ctx, session, game.Empty, placedFinished)
+	return nil
+}
@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+func TestAdminSeesMultiplayerGames(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	bob := newTestClient(h, "bob")
+	session, err := h.hub.createMultiGame([]string{"alice", "bob"}, 3, game.RuleFirstToConnect)
+	if err != nil || session == nil {
+		t.Fatalf("createMultiGame = %v, %v", session, err)
+	}
+
+	sessions := h.hub.ListSessions()
+	if len(sessions) != 1 {
+		t.Fatalf("ListSessions = %+v, want the multiplayer game", sessions)
+	}
+	s := sessions[0]
+	if s.GameID != session.Game.ID.String() || s.Rule != string(game.RuleFirstToConnect) || s.Player1 != nil || len(s.Players) != 3 {
+		t.Fatalf("summary = %+v", s)
+	}
+	if p := s.Players[0]; p.Username != "alice" || !p.Connected || p.IsBot {
+		t.Errorf("seat 1 = %+v", p)
+	}
+	if p := s.Players[2]; !p.IsBot || !p.Connected {
+		t.Errorf("the empty seat should be a bot: %+v", p)
+	}
+
+	ctx := context.Background()
+	if err := h.ForceEndGame(ctx, session.Game.ID, "player4"); !errors.Is(err, ErrInvalidResult) {
+		t.Errorf("ending a 3-seat game for player4 = %v, want ErrInvalidResult", err)
+	}
+	if err := h.ForceEndGame(ctx, session.Game.ID, "player2"); err != nil {
+		t.Fatal(err)
+	}
+
+	var over models.GameOverPayload
+	expect(t, bob, models.WSTypeGameOver, &over)
+	if over.Result != "win" || over.Winner != "bob" || len(over.Placements) != 3 {
+		t.Errorf("bob's game over = %+v", over)
+	}
+	expect(t, alice, models.WSTypeGameOver, &over)
+	if over.Result != "loss" {
+		t.Errorf("alice's result %q, want loss", over.Result)
+	}
+
+	if sessions := h.hub.ListSessions(); len(sessions) != 0 {
+		t.Errorf("a force-ended game is still listed: %+v", sessions)
+	}
+	if err := h.ForceEndGame(ctx, session.Game.ID, "draw"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("ending it again = %v, want ErrGameNotFound", err)
+	}
+}
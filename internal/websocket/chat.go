@@ -0,0 +1,266 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/models"
+)
+
+// botEmoteReplies maps what a player sent to the bot's canned answer
+var botEmoteReplies = map[string]string{
+	"hello":     "hello",
+	"good_luck": "good_luck",
+	"nice_move": "thanks",
+	"oops":      "thinking",
+	"wow":       "thanks",
+	"gg":        "gg",
+	"thanks":    "nice_move",
+}
+
+// NotifySpectators sends a message to everyone watching the game
+func (h *Hub) NotifySpectators(session *GameSession, msgType models.WSMessageType, payload interface{}) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, spectator := range session.Spectators {
+		spectator.SendMessage(msgType, payload)
+	}
+}
+
+// stopSpectating removes a client from the game it is watching. Caller must hold h.mu.
+func (h *Hub) stopSpectating(username string) {
+	gameID, ok := h.spectating[username]
+	if !ok {
+		return
+	}
+	delete(h.spectating, username)
+	if session, exists := h.games[gameID]; exists {
+		delete(session.Spectators, username)
+	}
+}
+
+// handleSpectateGame lets a client watch a game in progress
+func (h *MessageHandler) handleSpectateGame(client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.SpectateGamePayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid spectate payload")
+		return
+	}
+
+	gameID, err := uuid.Parse(req.GameID)
+	if err != nil {
+		client.SendError("Invalid game ID")
+		return
+	}
+
+	h.hub.mu.Lock()
+	defer h.hub.mu.Unlock()
+
+	session, ok := h.hub.games[gameID]
+	if !ok {
+		client.SendError("Game not found")
+		return
+	}
+	if h.hub.playing(client.Username) {
+		client.SendError("Cannot spectate while in a game")
+		return
+	}
+
+	h.hub.stopSpectating(client.Username)
+	session.Spectators[client.Username] = client
+	h.hub.spectating[client.Username] = gameID
+
+	client.SendMessage(models.WSTypeSpectating, models.SpectatingPayload{
+		GameID:      gameID.String(),
+		Player1:     session.Game.Player1.Username,
+		Player2:     session.Game.Player2.Username,
+		Board:       session.Game.Board.ToSlice(),
+		CurrentTurn: int(session.Game.GetCurrentPlayer()),
+		Variant:     string(session.Game.Variant),
+		Cylinder:    session.Game.Cylinder,
+	})
+
+	log.Info().Str("username", client.Username).Str("gameId", gameID.String()).Msg("Spectator joined")
+}
+
+// handleStopSpectating stops watching the current game
+func (h *MessageHandler) handleStopSpectating(client *Client) {
+	h.hub.mu.Lock()
+	h.hub.stopSpectating(client.Username)
+	h.hub.mu.Unlock()
+}
+
+// handleChatMessage relays a filtered chat line to the opponent and spectators
+func (h *MessageHandler) handleChatMessage(ctx context.Context, client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.ChatMessagePayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid chat payload")
+		return
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return
+	}
+	if utf8.RuneCountInString(text) > models.MaxChatLength {
+		client.SendError("Chat message too long")
+		return
+	}
+
+	session := h.findPlayerGame(client.Username)
+	if session == nil {
+		client.SendError("Not in a game")
+		return
+	}
+
+	// Reviewers see what was actually typed; everyone else the filtered text
+	filtered := h.policy.Filter(text)
+	h.saveChat(ctx, session.Game.ID, client.Username, models.ChatKindText, text, filtered != text)
+
+	h.deliverChat(session, client.Username, models.WSTypeChatReceived, models.ChatReceivedPayload{
+		GameID: session.Game.ID.String(),
+		From:   client.Username,
+		Text:   filtered,
+	})
+
+	if session.IsBot {
+		go h.sendBotEmote(session, "")
+	}
+}
+
+// handleEmote relays an emote to the opponent and spectators
+func (h *MessageHandler) handleEmote(ctx context.Context, client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.EmotePayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid emote payload")
+		return
+	}
+
+	if !models.Emotes[req.Emote] {
+		client.SendError("Unknown emote")
+		return
+	}
+
+	session := h.findPlayerGame(client.Username)
+	if session == nil {
+		client.SendError("Not in a game")
+		return
+	}
+
+	h.saveChat(ctx, session.Game.ID, client.Username, models.ChatKindEmote, req.Emote, false)
+
+	h.deliverChat(session, client.Username, models.WSTypeEmoteReceived, models.EmoteReceivedPayload{
+		GameID: session.Game.ID.String(),
+		From:   client.Username,
+		Emote:  req.Emote,
+	})
+
+	if session.IsBot {
+		go h.sendBotEmote(session, req.Emote)
+	}
+}
+
+// handleMutePlayer toggles whether the client receives chat from a player
+func (h *MessageHandler) handleMutePlayer(client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.MutePlayerPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil || req.Username == "" {
+		client.SendError("Invalid mute payload")
+		return
+	}
+
+	h.hub.mu.Lock()
+	defer h.hub.mu.Unlock()
+
+	gameID, ok := h.hub.playerGames[client.Username]
+	if !ok {
+		gameID, ok = h.hub.spectating[client.Username]
+	}
+	session, exists := h.hub.games[gameID]
+	if !ok || !exists {
+		client.SendError("Not in a game")
+		return
+	}
+
+	muted := session.Muted[client.Username]
+	if muted == nil {
+		muted = make(map[string]bool)
+		session.Muted[client.Username] = muted
+	}
+	if req.Muted {
+		muted[req.Username] = true
+	} else {
+		delete(muted, req.Username)
+	}
+}
+
+// deliverChat sends a chat or emote to everyone in the session except the
+// sender and anyone who muted the sender
+func (h *MessageHandler) deliverChat(session *GameSession, sender string, msgType models.WSMessageType, payload interface{}) {
+	h.hub.mu.RLock()
+	defer h.hub.mu.RUnlock()
+
+	recipients := make([]*Client, 0, 2+len(session.Spectators))
+	recipients = append(recipients, session.Player1, session.Player2)
+	for _, spectator := range session.Spectators {
+		recipients = append(recipients, spectator)
+	}
+
+	for _, recipient := range recipients {
+		if recipient == nil || recipient.Username == sender {
+			continue
+		}
+		if session.Muted[recipient.Username][sender] {
+			continue
+		}
+		recipient.SendMessage(msgType, payload)
+	}
+}
+
+// sendBotEmote answers a player's chat or emote with a canned emote
+func (h *MessageHandler) sendBotEmote(session *GameSession, trigger string) {
+	reply, ok := botEmoteReplies[trigger]
+	if !ok {
+		reply = "thinking"
+	}
+
+	time.Sleep(h.hub.botMoveDelay)
+	if session.Game.IsGameOver() {
+		return
+	}
+
+	botName := session.Game.Player2.Username
+	h.saveChat(context.Background(), session.Game.ID, botName, models.ChatKindEmote, reply, false)
+	h.deliverChat(session, botName, models.WSTypeEmoteReceived, models.EmoteReceivedPayload{
+		GameID: session.Game.ID.String(),
+		From:   botName,
+		Emote:  reply,
+	})
+}
+
+// saveChat stores a chat line for later review; failures are logged only
+func (h *MessageHandler) saveChat(ctx context.Context, gameID uuid.UUID, sender string, kind models.ChatKind, content string, filtered bool) {
+	if h.chatRepo == nil {
+		return
+	}
+	err := h.chatRepo.Create(ctx, &models.ChatMessage{
+		GameID:   gameID,
+		Sender:   sender,
+		Kind:     kind,
+		Content:  content,
+		Filtered: filtered,
+	})
+	if err != nil {
+		log.Error().Err(err).Str("gameId", gameID.String()).Msg("Failed to store chat message")
+	}
+}
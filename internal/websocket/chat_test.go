@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/moderation"
+)
+
+// newChatGame starts a game between alice and bob, watched by carol, with
+// "heck" on the profanity list
+func newChatGame(t *testing.T) (h *MessageHandler, alice, bob, carol *Client) {
+	t.Helper()
+	h = newTestHandler(t)
+	path := filepath.Join(t.TempDir(), "profanity.txt")
+	if err := os.WriteFile(path, []byte("heck\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := moderation.NewPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.policy = policy
+
+	alice = newTestClient(h, "alice")
+	bob = newTestClient(h, "bob")
+	carol = newTestClient(h, "carol")
+	session := h.startGame(alice, bob, game.Setup{Variant: game.VariantClassic}, true, nil, nil)
+	send(t, h, carol, models.WSTypeSpectateGame, models.SpectateGamePayload{GameID: session.Game.ID.String()})
+	expect(t, carol, models.WSTypeSpectating, nil)
+	return h, alice, bob, carol
+}
+
+// expectNoChat fails if c has been sent a chat line
+func expectNoChat(t *testing.T, c *Client) {
+	t.Helper()
+	for len(c.send) > 0 {
+		if strings.Contains(string(<-c.send), string(models.WSTypeChatReceived)) {
+			t.Errorf("%s was sent a chat line", c.Username)
+		}
+	}
+}
+
+func TestChatReachesOpponentAndSpectators(t *testing.T) {
+	h, alice, bob, carol := newChatGame(t)
+
+	send(t, h, alice, models.WSTypeChatMessage, models.ChatMessagePayload{Text: "oh  heck,\ngood move"})
+	for _, c := range []*Client{bob, carol} {
+		var chat models.ChatReceivedPayload
+		expect(t, c, models.WSTypeChatReceived, &chat)
+		if chat.From != "alice" || chat.Text != "oh  *****\ngood move" {
+			t.Errorf("%s got %+v, want alice's line filtered", c.Username, chat)
+		}
+	}
+	expectNoChat(t, alice)
+}
+
+func TestChatTooLong(t *testing.T) {
+	h, alice, bob, carol := newChatGame(t)
+
+	send(t, h, alice, models.WSTypeChatMessage, models.ChatMessagePayload{Text: strings.Repeat("é", models.MaxChatLength+1)})
+	var refused models.ErrorPayload
+	expect(t, alice, models.WSTypeError, &refused)
+	if refused.Message != "Chat message too long" {
+		t.Errorf("error = %q", refused.Message)
+	}
+	expectNoChat(t, bob)
+	expectNoChat(t, carol)
+
+	// The limit counts characters, not bytes
+	send(t, h, alice, models.WSTypeChatMessage, models.ChatMessagePayload{Text: strings.Repeat("é", models.MaxChatLength)})
+	expect(t, bob, models.WSTypeChatReceived, nil)
+}
+
+func TestMutedPlayerChat(t *testing.T) {
+	h, alice, bob, carol := newChatGame(t)
+
+	send(t, h, bob, models.WSTypeMutePlayer, models.MutePlayerPayload{Username: "alice", Muted: true})
+	send(t, h, alice, models.WSTypeChatMessage, models.ChatMessagePayload{Text: "gl"})
+	expect(t, carol, models.WSTypeChatReceived, nil)
+	expectNoChat(t, bob)
+
+	// Muting is per listener, and can be undone
+	send(t, h, bob, models.WSTypeMutePlayer, models.MutePlayerPayload{Username: "alice", Muted: false})
+	send(t, h, alice, models.WSTypeChatMessage, models.ChatMessagePayload{Text: "hf"})
+	var chat models.ChatReceivedPayload
+	expect(t, bob, models.WSTypeChatReceived, &chat)
+	if chat.Text != "hf" {
+		t.Errorf("bob got %q after unmuting, want hf", chat.Text)
+	}
+}
@@ -2,12 +2,15 @@ package websocket
 
 import (
 	"encoding/json"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 
+	"connect-four/internal/metrics"
 	"connect-four/internal/models"
+	"connect-four/internal/ratelimit"
 )
 
 const (
@@ -22,6 +25,9 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
+
+	// Consecutive throttled messages before the client is disconnected
+	maxThrottledMessages = 50
 )
 
 // Client represents a connected WebSocket client
@@ -30,16 +36,24 @@ type Client struct {
 	conn     *websocket.Conn
 	send     chan []byte
 	Username string
+	IsBot    bool // connected with a bot account's API key
 	closed   bool
+	dropped  atomic.Int64 // messages dropped because send was full
+
+	// Inbound message budget; nil means unlimited. Only touched by ReadPump.
+	budget    *ratelimit.Bucket
+	throttled int
 }
 
-// NewClient creates a new client instance
-func NewClient(hub *Hub, conn *websocket.Conn, username string) *Client {
+// NewClient creates a new client instance. budget limits how many messages
+// the client may send; pass nil for no limit.
+func NewClient(hub *Hub, conn *websocket.Conn, username string, budget *ratelimit.Bucket) *Client {
 	return &Client{
 		hub:      hub,
 		conn:     conn,
 		send:     make(chan []byte, 256),
 		Username: username,
+		budget:   budget,
 	}
 }
 
@@ -66,6 +80,19 @@ func (c *Client) ReadPump(messageHandler func(*Client, []byte)) {
 			break
 		}
 
+		if c.budget != nil && !c.budget.Allow() {
+			c.throttled++
+			if c.throttled == 1 {
+				c.SendError("Rate limit exceeded, slow down")
+			}
+			if c.throttled >= maxThrottledMessages {
+				log.Warn().Str("username", c.Username).Msg("Disconnecting client for flooding")
+				break
+			}
+			continue
+		}
+		c.throttled = 0
+
 		messageHandler(c, message)
 	}
 }
@@ -119,10 +146,24 @@ func (c *Client) SendMessage(msgType models.WSMessageType, payload interface{})
 	case c.send <- data:
 	default:
 		// Channel full, client might be slow
-		log.Warn().Str("username", c.Username).Msg("Client send channel full")
+		dropped := c.dropped.Add(1)
+		metrics.SendDropped.Inc()
+		log.Warn().Str("username", c.Username).Int64("dropped", dropped).Msg("Client send channel full")
 	}
 }
 
+// Dropped returns how many messages to the client were dropped because
+// its send channel was full
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
+}
+
+// Disconnect forces ReadPump to stop, which unregisters the client. Messages
+// already queued on send are still flushed by WritePump before it closes.
+func (c *Client) Disconnect() {
+	c.conn.SetReadDeadline(time.Now())
+}
+
 // SendError sends an error message to the client
 func (c *Client) SendError(message string) {
 	c.SendMessage(models.WSTypeError, models.ErrorPayload{Message: message})
@@ -0,0 +1,68 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// OnCorrespondenceMove registers what plays correspondence_move messages.
+// Correspondence games live in the database, not the hub; fn returns the
+// game after the move or an error whose text is meant for the player.
+func (h *MessageHandler) OnCorrespondenceMove(fn func(ctx context.Context, gameID uuid.UUID, username string, a game.Action) (*models.CorrespondenceGamePayload, error)) {
+	h.onCorrespondenceMove = fn
+}
+
+// OnConnect registers a callback run whenever a player connects, in a
+// goroutine of its own so it can't hold up the hub
+func (h *MessageHandler) OnConnect(fn func(username string)) {
+	h.hub.onConnect = fn
+}
+
+// Push sends a message to a player and reports whether they were
+// connected to get it
+func (h *MessageHandler) Push(username string, msgType models.WSMessageType, payload interface{}) bool {
+	client := h.hub.GetClient(username)
+	if client == nil {
+		return false
+	}
+	client.SendMessage(msgType, payload)
+	return true
+}
+
+// handleCorrespondenceMove plays a move in one of the player's
+// correspondence games, which don't need them to be in a live game
+func (h *MessageHandler) handleCorrespondenceMove(ctx context.Context, client *Client, payload interface{}) {
+	if h.onCorrespondenceMove == nil {
+		client.SendError("Correspondence games are unavailable")
+		return
+	}
+
+	var move models.CorrespondenceMovePayload
+	payloadBytes, _ := json.Marshal(payload)
+	if err := json.Unmarshal(payloadBytes, &move); err != nil {
+		client.SendError("Invalid move payload")
+		return
+	}
+	gameID, err := uuid.Parse(move.GameID)
+	if err != nil {
+		client.SendError("Invalid game ID")
+		return
+	}
+
+	action := game.Action{
+		Type:   game.MoveType(move.Type),
+		Column: move.Column,
+		Disc:   game.Disc(move.Disc),
+	}
+	g, err := h.onCorrespondenceMove(ctx, gameID, client.Username, action)
+	if err != nil {
+		client.SendError(err.Error())
+		return
+	}
+	client.SendMessage(models.WSTypeCorrespondenceGame, g)
+}
@@ -5,34 +5,81 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"connect-four/internal/bot"
 	"connect-four/internal/game"
 	"connect-four/internal/kafka"
 	"connect-four/internal/matchmaking"
+	"connect-four/internal/metrics"
 	"connect-four/internal/models"
+	"connect-four/internal/moderation"
+	"connect-four/internal/rating"
 	"connect-four/internal/repository"
+	"connect-four/internal/telemetry"
 )
 
+var tracer = telemetry.Tracer("websocket")
+
 // MessageHandler processes incoming WebSocket messages
 type MessageHandler struct {
 	hub           *Hub
 	matchQueue    *matchmaking.Queue
-	botEngine     *bot.Bot
+	lobbies       *matchmaking.Lobbies
+	engines       *bot.Registry
+	hinter        *bot.Bot
 	playerRepo    *repository.PlayerRepository
+	reportRepo    *repository.ReportRepository
+	chatRepo      *repository.ChatRepository
+	gameRepo      *repository.GameRepository
+	seriesRepo    *repository.SeriesRepository
+	puzzleRepo    *repository.PuzzleRepository
+	policy        *moderation.Policy
 	kafkaProducer *kafka.Producer
+
+	// Called after a tournament game ends (see OnGameFinished)
+	onGameFinished func(ctx context.Context, gameID uuid.UUID, winner game.Cell, abandoned bool)
+
+	// Plays correspondence_move (see OnCorrespondenceMove)
+	onCorrespondenceMove func(ctx context.Context, gameID uuid.UUID, username string, a game.Action) (*models.CorrespondenceGamePayload, error)
 }
 
 // NewMessageHandler creates a new message handler
-func NewMessageHandler(hub *Hub, matchQueue *matchmaking.Queue, playerRepo *repository.PlayerRepository, kafkaProducer *kafka.Producer) *MessageHandler {
-	return &MessageHandler{
+func NewMessageHandler(hub *Hub, matchQueue *matchmaking.Queue, lobbies *matchmaking.Lobbies, engines *bot.Registry, playerRepo *repository.PlayerRepository, reportRepo *repository.ReportRepository, chatRepo *repository.ChatRepository, gameRepo *repository.GameRepository, seriesRepo *repository.SeriesRepository, puzzleRepo *repository.PuzzleRepository, policy *moderation.Policy, kafkaProducer *kafka.Producer) *MessageHandler {
+	h := &MessageHandler{
 		hub:           hub,
 		matchQueue:    matchQueue,
-		botEngine:     bot.NewBot(),
+		lobbies:       lobbies,
+		engines:       engines,
+		hinter:        bot.NewBot(),
 		playerRepo:    playerRepo,
+		reportRepo:    reportRepo,
+		chatRepo:      chatRepo,
+		gameRepo:      gameRepo,
+		seriesRepo:    seriesRepo,
+		puzzleRepo:    puzzleRepo,
+		policy:        policy,
 		kafkaProducer: kafkaProducer,
 	}
+
+	// Games the hub forfeits on its own still need the full game-over flow
+	hub.onForfeit = func(session *GameSession) {
+		winnerName, result := gameOutcome(session)
+		h.finishGame(context.Background(), session, winnerName, result)
+	}
+	hub.onSeriesAbandoned = h.abandonSeries
+	hub.onPuzzleAbandoned = func(username string, ps *puzzleSession) {
+		h.recordPuzzle(context.Background(), username, ps, false)
+	}
+	hub.onMultiTimeout = func(session *multiSession, player game.Cell) {
+		h.eliminateMulti(context.Background(), session, player)
+	}
+	lobbies.OnStart(h.startMultiGame)
+
+	return h
 }
 
 // HandleMessage routes incoming messages to appropriate handlers
@@ -43,17 +90,64 @@ func (h *MessageHandler) HandleMessage(client *Client, data []byte) {
 		return
 	}
 
+	ctx, span := tracer.Start(context.Background(), "ws "+string(msg.Type),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("ws.message.type", string(msg.Type)),
+			attribute.String("player.username", client.Username),
+		),
+	)
+	defer span.End()
+
 	switch msg.Type {
 	case models.WSTypeJoinQueue:
 		h.handleJoinQueue(client, msg.Payload)
 	case models.WSTypeMakeMove:
-		h.handleMakeMove(client, msg.Payload)
+		h.handleMakeMove(ctx, client, msg.Payload)
 	case models.WSTypeLeaveGame:
-		h.handleLeaveGame(client)
+		h.handleLeaveGame(ctx, client)
 	case models.WSTypeResumeSession:
 		h.handleResumeSession(client)
 	case models.WSTypeAbandonSession:
 		h.handleAbandonSession(client)
+	case models.WSTypeReportPlayer:
+		h.handleReportPlayer(ctx, client, msg.Payload)
+	case models.WSTypeChatMessage:
+		h.handleChatMessage(ctx, client, msg.Payload)
+	case models.WSTypeEmote:
+		h.handleEmote(ctx, client, msg.Payload)
+	case models.WSTypeMutePlayer:
+		h.handleMutePlayer(client, msg.Payload)
+	case models.WSTypeSpectateGame:
+		h.handleSpectateGame(client, msg.Payload)
+	case models.WSTypeStopSpectating:
+		h.handleStopSpectating(client)
+	case models.WSTypeOfferRematch:
+		h.handleOfferRematch(client, msg.Payload)
+	case models.WSTypeAcceptRematch:
+		h.handleAcceptRematch(ctx, client)
+	case models.WSTypeDeclineRematch:
+		h.handleDeclineRematch(client)
+	case models.WSTypeOfferDraw:
+		h.handleOfferDraw(client)
+	case models.WSTypeRespondDraw:
+		h.handleRespondDraw(ctx, client, msg.Payload)
+	case models.WSTypeRequestTakeback:
+		h.handleRequestTakeback(ctx, client)
+	case models.WSTypeRespondTakeback:
+		h.handleRespondTakeback(client, msg.Payload)
+	case models.WSTypeStartPuzzle:
+		h.handleStartPuzzle(ctx, client, msg.Payload)
+	case models.WSTypePuzzleMove:
+		h.handlePuzzleMove(ctx, client, msg.Payload)
+	case models.WSTypeRequestHint:
+		h.handleRequestHint(client)
+	case models.WSTypeJoinLobby:
+		h.handleJoinLobby(client, msg.Payload)
+	case models.WSTypeLeaveLobby:
+		h.handleLeaveLobby(client)
+	case models.WSTypeCorrespondenceMove:
+		h.handleCorrespondenceMove(ctx, client, msg.Payload)
 	default:
 		client.SendError("Unknown message type")
 	}
@@ -61,9 +155,67 @@ func (h *MessageHandler) HandleMessage(client *Client, data []byte) {
 
 // handleJoinQueue adds a player to the matchmaking queue
 func (h *MessageHandler) handleJoinQueue(client *Client, payload interface{}) {
+	// Queueing again means the player is done with their last opponent
+	h.hub.mu.Lock()
+	h.hub.cancelRematch(client.Username, "Opponent joined matchmaking")
+	_, inMulti := h.hub.multiPlayers[client.Username]
+	h.hub.mu.Unlock()
+	if inMulti {
+		client.SendError("Already in a game")
+		return
+	}
+	h.leaveLobby(client.Username)
+
+	var join models.JoinQueuePayload
+	if payloadBytes, err := json.Marshal(payload); err == nil {
+		json.Unmarshal(payloadBytes, &join)
+	}
+
+	setup := game.Setup{Variant: game.VariantClassic, Cylinder: join.Cylinder}
+	if join.Variant != "" {
+		setup.Variant = game.Variant(join.Variant)
+	}
+	if !setup.Variant.Valid() {
+		client.SendError("Unknown variant")
+		return
+	}
+
+	// Asking for a specific engine skips matchmaking
+	if join.Engine != "" {
+		if !h.engines.Has(join.Engine) {
+			client.SendError("Unknown engine")
+			return
+		}
+		if ownBot(setup) && join.Engine != bot.DefaultEngine {
+			client.SendError("Only the built-in bot plays " + setup.Key())
+			return
+		}
+		if h.findPlayerGame(client.Username) != nil {
+			client.SendError("Already in a game")
+			return
+		}
+		h.matchQueue.RemovePlayer(client.Username)
+		h.startBotGame(client, join.Engine, setup)
+		return
+	}
+
+	if join.Personality != "" && !bot.IsPersonality(join.Personality) {
+		client.SendError("Unknown personality")
+		return
+	}
+
+	// Bots only meet players who opted in to playing them
+	pool := matchmaking.PoolHumans
+	if client.IsBot || join.AllowBots {
+		pool = matchmaking.PoolOpen
+	}
+
 	// Add to matchmaking queue with callbacks
 	h.matchQueue.AddPlayer(
 		client.Username,
+		pool,
+		setup.Key(),
+		join.Casual,
 		// On match with another player
 		func(opponent *matchmaking.Player, isBot bool) {
 			opponentClient := h.hub.GetClient(opponent.Username)
@@ -73,11 +225,20 @@ func (h *MessageHandler) handleJoinQueue(client *Client, payload interface{}) {
 			}
 			// client (the one who was waiting in queue) is Player 1 (first turn)
 			// opponentClient (the one who just joined) is Player 2
-			h.startGame(client, opponentClient, false)
+			h.startGame(client, opponentClient, setup, !join.Casual, nil, nil)
 		},
-		// On timeout - start bot game
+		// On timeout - start a bot game with the chosen personality, or
+		// a random one so bot games don't all play alike. Other variants
+		// and cylinder games have bots of their own.
 		func() {
-			h.startBotGame(client)
+			personality := join.Personality
+			if personality == "" {
+				personality = bot.RandomPersonality()
+			}
+			if ownBot(setup) {
+				personality = bot.DefaultEngine
+			}
+			h.startBotGame(client, personality, setup)
 		},
 	)
 
@@ -87,27 +248,52 @@ func (h *MessageHandler) handleJoinQueue(client *Client, payload interface{}) {
 		Position: pos,
 	})
 
-	log.Info().Str("username", client.Username).Int("position", pos).Msg("Player joined queue")
+	log.Info().Str("username", client.Username).Str("pool", string(pool)).Str("variant", setup.Key()).Bool("casual", join.Casual).Int("position", pos).Msg("Player joined queue")
 }
 
-// startGame initializes a new game between two players
-func (h *MessageHandler) startGame(player1, player2 *Client, isBot bool) {
-	session := h.hub.CreateGame(player1, player2, isBot)
+// startGame initializes a new rated or casual game between two players,
+// optionally as the next game of a series or as a tournament pairing
+func (h *MessageHandler) startGame(player1, player2 *Client, setup game.Setup, rated bool, series *models.Series, tournament *models.TournamentInfoPayload) *GameSession {
+	session := h.hub.CreateGame(player1, player2, false, rated, setup)
+
+	// Set before anyone is told about the game, so no move can race it
+	var seriesPayload *models.SeriesPayload
+	if series != nil {
+		session.Series = series
+		seriesPayload = newSeriesPayload(series)
+	}
+	session.Tournament = tournament
 
 	// Notify Player 1
 	player1.SendMessage(models.WSTypeGameStarted, models.GameStartedPayload{
-		GameID:    session.Game.ID.String(),
-		Opponent:  session.Game.Player2.Username,
-		YourTurn:  true, // Player 1 always goes first
-		YourColor: int(game.Player1),
+		GameID:        session.Game.ID.String(),
+		Opponent:      session.Game.Player2.Username,
+		YourTurn:      true, // Player 1 always goes first
+		YourColor:     int(game.Player1),
+		Rated:         rated,
+		Variant:       string(setup.Variant),
+		Cylinder:      setup.Cylinder,
+		Rows:          session.Game.Board.Rows,
+		Columns:       session.Game.Board.Columns,
+		OpponentIsBot: player2.IsBot,
+		Series:        seriesPayload,
+		Tournament:    tournament,
 	})
 
 	// Notify Player 2
 	player2.SendMessage(models.WSTypeGameStarted, models.GameStartedPayload{
-		GameID:    session.Game.ID.String(),
-		Opponent:  session.Game.Player1.Username,
-		YourTurn:  false,
-		YourColor: int(game.Player2),
+		GameID:        session.Game.ID.String(),
+		Opponent:      session.Game.Player1.Username,
+		YourTurn:      false,
+		YourColor:     int(game.Player2),
+		Rated:         rated,
+		Variant:       string(setup.Variant),
+		Cylinder:      setup.Cylinder,
+		Rows:          session.Game.Board.Rows,
+		Columns:       session.Game.Board.Columns,
+		OpponentIsBot: player1.IsBot,
+		Series:        seriesPayload,
+		Tournament:    tournament,
 	})
 
 	log.Info().
@@ -118,30 +304,110 @@ func (h *MessageHandler) startGame(player1, player2 *Client, isBot bool) {
 
 	// Publish game started event to Kafka
 	if h.kafkaProducer != nil {
-		h.kafkaProducer.PublishGameStarted(context.Background(), session.Game.ID, player1.Username, player2.Username, isBot)
+		h.kafkaProducer.PublishGameStarted(context.Background(), session.Game.ID, player1.Username, player2.Username, false)
 	}
+
+	return session
 }
 
-// startBotGame initializes a game against the bot
-func (h *MessageHandler) startBotGame(client *Client) {
-	session := h.hub.CreateGame(client, nil, true)
+// startBotGame initializes a game against a registered engine, or against
+// the variant's own bot in games that aren't classic or are on a cylinder
+func (h *MessageHandler) startBotGame(client *Client, engineName string, setup game.Setup) {
+	engine := variantBot(setup)
+	if engine == nil {
+		var err error
+		engine, err = h.engines.New(engineName)
+		if err != nil {
+			log.Error().Err(err).Str("engine", engineName).Msg("Failed to start engine")
+			client.SendError("Engine unavailable")
+			return
+		}
+	}
+
+	session := h.hub.CreateGame(client, nil, true, false, setup)
+	h.hub.mu.Lock()
+	session.Engine = engine
+	switch {
+	case ownBot(setup):
+	case bot.IsPersonality(engineName):
+		session.Personality = engineName
+	case engineName != bot.DefaultEngine:
+		session.Game.Player2.Username = engineName
+	}
+	h.hub.mu.Unlock()
 
 	// Notify player
 	client.SendMessage(models.WSTypeGameStarted, models.GameStartedPayload{
-		GameID:    session.Game.ID.String(),
-		Opponent:  "Bot",
-		YourTurn:  true, // Player always goes first against bot
-		YourColor: int(game.Player1),
+		GameID:         session.Game.ID.String(),
+		Opponent:       session.Game.Player2.Username,
+		YourTurn:       true, // Player always goes first against bot
+		YourColor:      int(game.Player1),
+		Variant:        string(setup.Variant),
+		Cylinder:       setup.Cylinder,
+		Rows:           session.Game.Board.Rows,
+		Columns:        session.Game.Board.Columns,
+		OpponentIsBot:  true,
+		BotPersonality: session.Personality,
 	})
 
 	log.Info().
 		Str("gameId", session.Game.ID.String()).
 		Str("player", client.Username).
+		Str("engine", engineName).
 		Msg("Bot game started")
+
+	// Publish game started event to Kafka
+	if h.kafkaProducer != nil {
+		h.kafkaProducer.PublishGameStarted(context.Background(), session.Game.ID, client.Username, session.Game.Player2.Username, true)
+	}
+}
+
+// ownBot reports whether games played by setup have a bot of their own
+// rather than the registered engines, which only play classic games on a
+// flat board
+func ownBot(setup game.Setup) bool {
+	return setup.Cylinder || setup.Variant != game.VariantClassic
+}
+
+// variantBot returns the bot that plays setup, nil unless ownBot. Only the
+// rules bot looks around the edge of a cylinder.
+func variantBot(setup game.Setup) bot.Engine {
+	switch {
+	case !ownBot(setup):
+		return nil
+	case setup.Variant == game.VariantPopOut && !setup.Cylinder:
+		return bot.NewPopOut(bot.PopOutDepth, nil)
+	}
+	rules, _ := game.RulesFor(setup.Variant)
+	return bot.NewRulesBot(rules, bot.RulesDepth)
+}
+
+// newMoveMadePayload describes a move player just made with action, which
+// landed on or was taken from row
+func newMoveMadePayload(g *game.Game, player game.Cell, action game.Action, row int) models.MoveMadePayload {
+	payload := models.MoveMadePayload{
+		Column: action.Column,
+		Row:    row,
+		Player: int(player),
+		Board:  g.Board.ToSlice(),
+		Disc:   string(action.Disc),
+	}
+	if action.Type == game.MovePop {
+		payload.Type = string(game.MovePop)
+	}
+	if !g.IsGameOver() {
+		payload.NextPlayer = int(g.GetCurrentPlayer())
+	}
+	if g.Variant == game.VariantPop10 {
+		payload.Captured = []int{g.Captured(game.Player1), g.Captured(game.Player2)}
+	}
+	return payload
 }
 
 // handleMakeMove processes a player's move
-func (h *MessageHandler) handleMakeMove(client *Client, payload interface{}) {
+func (h *MessageHandler) handleMakeMove(ctx context.Context, client *Client, payload interface{}) {
+	start := time.Now()
+
 	// Parse payload
 	payloadBytes, _ := json.Marshal(payload)
 	var movePayload models.MakeMovePayload
@@ -150,6 +416,11 @@ func (h *MessageHandler) handleMakeMove(client *Client, payload interface{}) {
 		return
 	}
 
+	if multi := h.findMultiGame(client.Username); multi != nil {
+		h.handleMultiMove(ctx, client, multi, movePayload.Column)
+		return
+	}
+
 	// Find the game session
 	session := h.findPlayerGame(client.Username)
 	if session == nil {
@@ -164,22 +435,29 @@ func (h *MessageHandler) handleMakeMove(client *Client, payload interface{}) {
 	}
 
 	// Make the move
-	row, errMsg := session.Game.MakeMove(playerColor, movePayload.Column)
+	action := game.Action{
+		Type:   game.MoveType(movePayload.Type),
+		Column: movePayload.Column,
+		Disc:   game.Disc(movePayload.Disc),
+	}
+	row, errMsg := session.Game.Play(playerColor, action)
 	if errMsg != "" {
 		client.SendMessage(models.WSTypeInvalidMove, models.InvalidMovePayload{
 			Reason: errMsg,
 		})
+		if h.kafkaProducer != nil {
+			h.kafkaProducer.PublishInvalidMove(ctx, session.Game.ID, client.Username, movePayload.Column, errMsg)
+		}
 		return
 	}
 
+	// Moving instead of answering declines the opponent's pending offers
+	h.hub.mu.Lock()
+	h.hub.clearStaleOffers(session, client.Username)
+	h.hub.mu.Unlock()
+
 	// Broadcast move to all players
-	boardState := session.Game.Board.ToSlice()
-	moveMadePayload := models.MoveMadePayload{
-		Column: movePayload.Column,
-		Row:    row,
-		Player: int(playerColor),
-		Board:  boardState,
-	}
+	moveMadePayload := newMoveMadePayload(session.Game, playerColor, action, row)
 
 	client.SendMessage(models.WSTypeMoveMade, moveMadePayload)
 	if session.Player2 != nil {
@@ -188,78 +466,149 @@ func (h *MessageHandler) handleMakeMove(client *Client, payload interface{}) {
 	if session.Player1 != nil && session.Player1.Username != client.Username {
 		session.Player1.SendMessage(models.WSTypeMoveMade, moveMadePayload)
 	}
+	h.hub.NotifySpectators(session, models.WSTypeMoveMade, moveMadePayload)
+	metrics.MoveLatency.Observe(time.Since(start).Seconds())
 
 	// Publish move event to Kafka
 	if h.kafkaProducer != nil {
 		moveNum := len(session.Game.Moves)
-		h.kafkaProducer.PublishGameMove(context.Background(), session.Game.ID, client.Username, movePayload.Column, moveNum)
+		h.kafkaProducer.PublishGameMove(ctx, session.Game.ID, client.Username, movePayload.Column, moveNum)
 	}
 
 	// Check if game is over
 	if session.Game.IsGameOver() {
-		h.handleGameOver(session)
+		h.handleGameOver(ctx, session)
 		return
 	}
 
 	// If bot game and it's bot's turn, make bot move
 	if session.IsBot && session.Game.CurrentTurn == game.Player2 {
-		go h.makeBotMove(session)
+		go h.makeBotMove(ctx, session)
 	}
 }
 
-// makeBotMove executes the bot's move with a small delay
-func (h *MessageHandler) makeBotMove(session *GameSession) {
-	// Add small delay for better UX
-	time.Sleep(h.hub.botMoveDelay)
+// makeBotMove executes the bot's move, taking as long to answer as the
+// position deserves. The engine searches a snapshot of the board within
+// the move budget; if the game ends or the position changes meanwhile,
+// the move is dropped without being played or sent.
+func (h *MessageHandler) makeBotMove(ctx context.Context, session *GameSession) {
+	ctx, span := tracer.Start(ctx, "bot.move", trace.WithAttributes(
+		attribute.String("game.id", session.Game.ID.String()),
+	))
+	defer span.End()
+
+	state, version := session.Game.SnapshotState()
+	board := state.Grid.Board() // nil unless the game is on the classic board
+	thinkTime := h.hub.botMoveDelay
+	if board != nil {
+		thinkTime = bot.ThinkTime(board, game.Player2, h.hub.botMoveDelay)
+		if thinker, ok := session.Engine.(bot.Thinker); ok {
+			thinkTime = thinker.ThinkTime(board, game.Player2, h.hub.botMoveDelay)
+		}
+	}
 
 	// Get bot's move
-	col := h.botEngine.SelectMove(session.Game.Board, game.Player2)
-	if col == -1 {
-		log.Error().Str("gameId", session.Game.ID.String()).Msg("Bot couldn't select move")
+	moveCtx, cancel := context.WithTimeout(session.ctx, h.hub.botMoveBudget)
+	defer cancel()
+	thinkStart := time.Now()
+	var action game.Action
+	var err error
+	switch engine := session.Engine.(type) {
+	case bot.Actor:
+		action, err = engine.Act(moveCtx, state)
+	case bot.Popper:
+		var pop bool
+		action.Column, pop, err = engine.MovePopOut(moveCtx, board, game.Player2)
+		if pop {
+			action.Type = game.MovePop
+		}
+	default:
+		action.Column, err = session.Engine.Move(moveCtx, board, game.Player2)
+	}
+	metrics.BotThinkTime.Observe(time.Since(thinkStart).Seconds())
+	if session.ctx.Err() != nil {
+		log.Debug().Str("gameId", session.Game.ID.String()).Msg("Game ended while the bot was thinking")
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Str("gameId", session.Game.ID.String()).Msg("Bot couldn't select move")
+		h.forfeitBot(ctx, session)
+		return
+	}
+	if !game.Legal(session.Game.Rules, state, action) {
+		log.Error().Int("column", action.Column).Str("type", string(action.Type)).Str("gameId", session.Game.ID.String()).Msg("Bot made invalid move")
+		h.forfeitBot(ctx, session)
 		return
 	}
 
-	// Make the move
-	row, errMsg := session.Game.MakeMove(game.Player2, col)
+	// Time spent searching counts toward the think time
+	if wait := thinkTime - time.Since(thinkStart); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-session.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+
+	// Make the move, unless the position moved on without it
+	row, errMsg := session.Game.PlayAt(game.Player2, action, version)
 	if errMsg != "" {
-		log.Error().Str("error", errMsg).Msg("Bot made invalid move")
+		log.Debug().Str("error", errMsg).Str("gameId", session.Game.ID.String()).Msg("Dropped stale bot move")
 		return
 	}
 
 	// Send move to player
-	boardState := session.Game.Board.ToSlice()
-	session.Player1.SendMessage(models.WSTypeMoveMade, models.MoveMadePayload{
-		Column: col,
-		Row:    row,
-		Player: int(game.Player2),
-		Board:  boardState,
-	})
+	moveMadePayload := newMoveMadePayload(session.Game, game.Player2, action, row)
+	session.Player1.SendMessage(models.WSTypeMoveMade, moveMadePayload)
+	h.hub.NotifySpectators(session, models.WSTypeMoveMade, moveMadePayload)
+
+	// Publish move event to Kafka
+	if h.kafkaProducer != nil {
+		h.kafkaProducer.PublishGameMove(ctx, session.Game.ID, session.Game.Player2.Username, action.Column, len(session.Game.Moves))
+	}
 
 	// Check if game is over
 	if session.Game.IsGameOver() {
-		h.handleGameOver(session)
+		h.handleGameOver(ctx, session)
+		return
+	}
+
+	// Some rules give the bot another move
+	if session.Game.GetCurrentPlayer() == game.Player2 {
+		h.makeBotMove(ctx, session)
 	}
 }
 
-// handleGameOver sends game over messages and cleans up
-func (h *MessageHandler) handleGameOver(session *GameSession) {
-	var winnerName string
-	result := "draw"
+// forfeitBot ends a game the engine can no longer play (crashed, timed out
+// or answered with an illegal move) as a loss for the engine
+func (h *MessageHandler) forfeitBot(ctx context.Context, session *GameSession) {
+	if session.Game.IsGameOver() {
+		return
+	}
+	session.Game.Forfeit(game.Player2)
+	h.handleGameOver(ctx, session)
+}
+
+// gameOutcome returns the winner's name ("draw" for a draw) and the result
+// reported to clients for a finished game
+func gameOutcome(session *GameSession) (winnerName, result string) {
+	result = "draw"
 
 	switch session.Game.Result {
 	case game.ResultPlayer1Win:
 		winnerName = session.Game.Player1.Username
 		result = "win"
 	case game.ResultPlayer2Win:
-		if session.IsBot {
-			winnerName = "Bot"
-		} else {
-			winnerName = session.Game.Player2.Username
-		}
+		winnerName = session.Game.Player2.Username
 		result = "win"
 	case game.ResultDraw:
 		winnerName = "draw"
 		result = "draw"
+	case game.ResultDrawAgreed:
+		winnerName = "draw"
+		result = "draw_agreed"
 	case game.ResultForfeit:
 		if session.Game.Winner == game.Player1 {
 			winnerName = session.Game.Player1.Username
@@ -268,6 +617,14 @@ func (h *MessageHandler) handleGameOver(session *GameSession) {
 		}
 		result = "forfeit"
 	}
+	return winnerName, result
+}
+
+// handleGameOver sends game over messages and cleans up
+func (h *MessageHandler) handleGameOver(ctx context.Context, session *GameSession) {
+	// Stop the bot before anyone hears the game is over
+	session.cancel()
+	winnerName, result := gameOutcome(session)
 
 	gameOverPayload := models.GameOverPayload{
 		Winner:     winnerName,
@@ -308,23 +665,32 @@ func (h *MessageHandler) handleGameOver(session *GameSession) {
 		})
 	}
 
+	h.hub.NotifySpectators(session, models.WSTypeGameOver, gameOverPayload)
+
 	log.Info().
 		Str("gameId", session.Game.ID.String()).
 		Str("winner", winnerName).
 		Str("result", result).
 		Msg("Game ended")
 
+	h.finishGame(ctx, session, winnerName, result)
+}
+
+// finishGame persists a finished game, publishes game.ended, scores any
+// series, opens a rematch window and removes the session from the hub.
+// Used for every way a game can end, including forfeits the hub decides.
+func (h *MessageHandler) finishGame(ctx context.Context, session *GameSession, winnerName, result string) {
 	// Persist game results to database
 	if h.playerRepo != nil {
 		// Create/get player records first
-		p1, err := h.playerRepo.Create(session.Game.Player1.Username)
+		p1, err := h.playerRepo.Create(ctx, session.Game.Player1.Username)
 		if err != nil {
 			log.Error().Err(err).Str("username", session.Game.Player1.Username).Msg("Failed to create/get player")
 		}
 
 		var p2 *models.Player
 		if session.Game.Player2 != nil && !session.IsBot {
-			p2, err = h.playerRepo.Create(session.Game.Player2.Username)
+			p2, err = h.playerRepo.Create(ctx, session.Game.Player2.Username)
 			if err != nil {
 				log.Error().Err(err).Str("username", session.Game.Player2.Username).Msg("Failed to create/get player")
 			}
@@ -335,64 +701,151 @@ func (h *MessageHandler) handleGameOver(session *GameSession) {
 		case game.ResultPlayer1Win, game.ResultForfeit:
 			if session.Game.Winner == game.Player1 {
 				if p1 != nil {
-					if err := h.playerRepo.IncrementWins(p1.ID); err != nil {
+					if err := h.playerRepo.IncrementWins(ctx, p1.ID); err != nil {
 						log.Error().Err(err).Msg("Failed to increment wins")
 					}
 				}
 				if p2 != nil {
-					if err := h.playerRepo.IncrementLosses(p2.ID); err != nil {
+					if err := h.playerRepo.IncrementLosses(ctx, p2.ID); err != nil {
 						log.Error().Err(err).Msg("Failed to increment losses")
 					}
 				}
 			} else if session.Game.Winner == game.Player2 {
 				if p2 != nil {
-					if err := h.playerRepo.IncrementWins(p2.ID); err != nil {
+					if err := h.playerRepo.IncrementWins(ctx, p2.ID); err != nil {
 						log.Error().Err(err).Msg("Failed to increment wins")
 					}
 				}
 				if p1 != nil {
-					if err := h.playerRepo.IncrementLosses(p1.ID); err != nil {
+					if err := h.playerRepo.IncrementLosses(ctx, p1.ID); err != nil {
 						log.Error().Err(err).Msg("Failed to increment losses")
 					}
 				}
 			}
 		case game.ResultPlayer2Win:
 			if p2 != nil {
-				if err := h.playerRepo.IncrementWins(p2.ID); err != nil {
+				if err := h.playerRepo.IncrementWins(ctx, p2.ID); err != nil {
 					log.Error().Err(err).Msg("Failed to increment wins")
 				}
 			}
 			if p1 != nil {
-				if err := h.playerRepo.IncrementLosses(p1.ID); err != nil {
+				if err := h.playerRepo.IncrementLosses(ctx, p1.ID); err != nil {
 					log.Error().Err(err).Msg("Failed to increment losses")
 				}
 			}
-		case game.ResultDraw:
+		case game.ResultDraw, game.ResultDrawAgreed:
 			if p1 != nil {
-				if err := h.playerRepo.IncrementDraws(p1.ID); err != nil {
+				if err := h.playerRepo.IncrementDraws(ctx, p1.ID); err != nil {
 					log.Error().Err(err).Msg("Failed to increment draws")
 				}
 			}
 			if p2 != nil {
-				if err := h.playerRepo.IncrementDraws(p2.ID); err != nil {
+				if err := h.playerRepo.IncrementDraws(ctx, p2.ID); err != nil {
 					log.Error().Err(err).Msg("Failed to increment draws")
 				}
 			}
 		}
 		log.Info().Msg("Game stats persisted to database")
+
+		if session.Rated && p1 != nil && p2 != nil {
+			h.updateRatings(ctx, session, p1, p2)
+		}
+
+		h.saveGameRecord(ctx, session, p1, p2)
 	}
 
 	// Publish game ended event to Kafka
 	if h.kafkaProducer != nil {
-		h.kafkaProducer.PublishGameEnded(context.Background(), session.Game.ID, winnerName, result, 0, len(session.Game.Moves))
+		h.kafkaProducer.PublishGameEnded(ctx, session.Game.ID, winnerName, result, session.Game.Duration(), len(session.Game.Moves))
+	}
+
+	if session.Series != nil {
+		h.scoreSeries(ctx, session)
 	}
 
 	// Cleanup the game session
+	h.hub.mu.Lock()
 	h.hub.cleanupGame(session)
+	if !session.IsBot && session.Tournament == nil {
+		h.hub.openRematch(session)
+	}
+	abandoned := session.Abandoned
+	h.hub.mu.Unlock()
+
+	if session.Tournament != nil && h.onGameFinished != nil {
+		h.onGameFinished(ctx, session.Game.ID, session.Game.Winner, abandoned)
+	}
+}
+
+// updateRatings applies the Elo change of a rated game to both players
+func (h *MessageHandler) updateRatings(ctx context.Context, session *GameSession, p1, p2 *models.Player) {
+	score := 0.5
+	switch session.Game.Winner {
+	case game.Player1:
+		score = 1
+	case game.Player2:
+		score = 0
+	}
+
+	r1, r2 := rating.Update(p1.Rating, p2.Rating, score)
+	if err := h.playerRepo.UpdateRating(ctx, p1.ID, r1); err != nil {
+		log.Error().Err(err).Str("username", p1.Username).Msg("Failed to update rating")
+	}
+	if err := h.playerRepo.UpdateRating(ctx, p2.ID, r2); err != nil {
+		log.Error().Err(err).Str("username", p2.Username).Msg("Failed to update rating")
+	}
+}
+
+// saveGameRecord stores the finished game with its moves; failures are logged only
+func (h *MessageHandler) saveGameRecord(ctx context.Context, session *GameSession, p1, p2 *models.Player) {
+	if h.gameRepo == nil || p1 == nil {
+		return
+	}
+
+	moves, err := json.Marshal(session.Game.Moves)
+	if err != nil {
+		log.Error().Err(err).Str("gameId", session.Game.ID.String()).Msg("Failed to encode moves")
+		return
+	}
+
+	record := &models.GameRecord{
+		ID:              session.Game.ID,
+		Player1ID:       p1.ID,
+		IsBotGame:       session.IsBot,
+		BotPersonality:  session.Personality,
+		Variant:         string(session.Game.Variant),
+		Cylinder:        session.Game.Cylinder,
+		Result:          models.GameResultType(session.Game.Result),
+		Moves:           string(moves),
+		DurationSeconds: session.Game.Duration(),
+		StartedAt:       session.Game.StartedAt,
+		EndedAt:         session.Game.EndedAt,
+	}
+	if p2 != nil {
+		record.Player2ID = &p2.ID
+	}
+	switch {
+	case session.Game.Winner == game.Player1:
+		record.WinnerID = &p1.ID
+	case session.Game.Winner == game.Player2 && p2 != nil:
+		record.WinnerID = &p2.ID
+	}
+	if session.Series != nil && session.Series.ID != uuid.Nil {
+		record.SeriesID = &session.Series.ID
+	}
+
+	if err := h.gameRepo.Create(ctx, record); err != nil {
+		log.Error().Err(err).Str("gameId", session.Game.ID.String()).Msg("Failed to store game record")
+	}
 }
 
 // handleLeaveGame handles voluntary game exit (forfeit)
-func (h *MessageHandler) handleLeaveGame(client *Client) {
+func (h *MessageHandler) handleLeaveGame(ctx context.Context, client *Client) {
+	if multi := h.findMultiGame(client.Username); multi != nil {
+		h.eliminateMulti(ctx, multi, multi.Game.Seat(client.Username))
+		return
+	}
+
 	session := h.findPlayerGame(client.Username)
 	if session == nil {
 		return
@@ -406,7 +859,7 @@ func (h *MessageHandler) handleLeaveGame(client *Client) {
 
 	// Forfeit the game
 	session.Game.Forfeit(playerColor)
-	h.handleGameOver(session)
+	h.handleGameOver(ctx, session)
 }
 
 // findPlayerGame finds the game session for a player
@@ -430,6 +883,14 @@ func (h *MessageHandler) handleResumeSession(client *Client) {
 	h.hub.mu.Lock()
 	defer h.hub.mu.Unlock()
 
+	if gameID, exists := h.hub.multiPlayers[client.Username]; exists {
+		if session, ok := h.hub.multiGames[gameID]; ok {
+			h.hub.handleMultiReconnection(client, session)
+			log.Info().Str("username", client.Username).Str("gameId", gameID.String()).Msg("Session resumed")
+			return
+		}
+	}
+
 	gameID, exists := h.hub.playerGames[client.Username]
 	if !exists {
 		client.SendError("No active session found")
@@ -450,6 +911,12 @@ func (h *MessageHandler) handleResumeSession(client *Client) {
 
 // handleAbandonSession abandons an existing session and allows fresh matchmaking
 func (h *MessageHandler) handleAbandonSession(client *Client) {
+	if multi := h.findMultiGame(client.Username); multi != nil {
+		h.eliminateMulti(context.Background(), multi, multi.Game.Seat(client.Username))
+		log.Info().Str("username", client.Username).Msg("Session abandoned")
+		return
+	}
+
 	h.hub.mu.Lock()
 
 	gameID, exists := h.hub.playerGames[client.Username]
@@ -466,6 +933,7 @@ func (h *MessageHandler) handleAbandonSession(client *Client) {
 		if session.Game.Player2 != nil && session.Game.Player2.Username == client.Username {
 			playerColor = game.Player2
 		}
+		session.Abandoned = true
 		session.Game.Forfeit(playerColor)
 
 		// Notify opponent if present
@@ -481,17 +949,80 @@ func (h *MessageHandler) handleAbandonSession(client *Client) {
 			})
 		}
 
-		// Clean up game
-		delete(h.hub.games, gameID)
-		delete(h.hub.playerGames, client.Username)
-		if session.Game.Player1.Username != client.Username {
-			delete(h.hub.playerGames, session.Game.Player1.Username)
-		}
-		if session.Game.Player2 != nil && session.Game.Player2.Username != client.Username {
-			delete(h.hub.playerGames, session.Game.Player2.Username)
-		}
+		totalMoves := len(session.Game.Moves)
+		h.hub.publish(func(p *kafka.Producer) {
+			p.PublishSessionAbandoned(context.Background(), gameID, client.Username, totalMoves)
+		})
 	}
 
 	h.hub.mu.Unlock()
+
+	if ok {
+		winnerName, result := gameOutcome(session)
+		h.finishGame(context.Background(), session, winnerName, result)
+	}
 	log.Info().Str("username", client.Username).Msg("Session abandoned")
 }
+
+// handleReportPlayer stores a report against another player for moderator review
+func (h *MessageHandler) handleReportPlayer(ctx context.Context, client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.ReportPlayerPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid report payload")
+		return
+	}
+
+	if req.Username == "" || req.Username == client.Username {
+		client.SendError("Invalid player to report")
+		return
+	}
+	if !models.ReportReasons[req.Reason] {
+		client.SendError("Invalid report reason")
+		return
+	}
+	if len(req.Details) > 500 {
+		client.SendError("Report details too long")
+		return
+	}
+
+	report := &models.PlayerReport{
+		ReporterUsername: client.Username,
+		ReportedUsername: req.Username,
+		Reason:           req.Reason,
+		Details:          req.Details,
+		Status:           models.ReportStatusOpen,
+	}
+
+	if req.GameID != "" {
+		gameID, err := uuid.Parse(req.GameID)
+		if err != nil {
+			client.SendError("Invalid game ID")
+			return
+		}
+		report.GameID = &gameID
+	} else if session := h.findPlayerGame(client.Username); session != nil {
+		gameID := session.Game.ID
+		report.GameID = &gameID
+	}
+
+	if h.reportRepo == nil {
+		client.SendError("Reporting is unavailable")
+		return
+	}
+	if err := h.reportRepo.Create(ctx, report); err != nil {
+		log.Error().Err(err).Str("username", client.Username).Msg("Failed to store report")
+		client.SendError("Failed to submit report")
+		return
+	}
+
+	client.SendMessage(models.WSTypeReportReceived, models.ReportReceivedPayload{
+		ReportID: report.ID.String(),
+	})
+
+	log.Info().
+		Str("reporter", client.Username).
+		Str("reported", req.Username).
+		Str("reason", req.Reason).
+		Msg("Player reported")
+}
@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"connect-four/internal/bot"
+	"connect-four/internal/matchmaking"
+	"connect-four/internal/models"
+)
+
+// newTestHandler creates a message handler with no database or Kafka, a
+// running matchmaking queue, bots that move without pausing, takebacks
+// disabled in rated games and three hints a game
+func newTestHandler(t *testing.T) *MessageHandler {
+	t.Helper()
+	hub := NewHub(time.Minute, time.Minute, 0, time.Second, 0, 3, nil)
+	queue := matchmaking.NewQueue(time.Minute, nil)
+	queue.Start()
+	t.Cleanup(queue.Stop)
+	return NewMessageHandler(hub, queue, matchmaking.NewLobbies(time.Minute), bot.NewRegistry(), nil, nil, nil, nil, nil, nil, nil, nil)
+}
+
+// newTestClient registers a client with no connection; what the server
+// sends it waits on its send channel
+func newTestClient(h *MessageHandler, username string) *Client {
+	c := &Client{hub: h.hub, send: make(chan []byte, 256), Username: username}
+	h.hub.mu.Lock()
+	h.hub.clients[username] = c
+	h.hub.mu.Unlock()
+	return c
+}
+
+// send handles a message from c as if it had come over its connection
+func send(t *testing.T, h *MessageHandler, c *Client, msgType models.WSMessageType, payload interface{}) {
+	t.Helper()
+	data, err := json.Marshal(models.WSMessage{Type: msgType, Payload: payload})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.HandleMessage(c, data)
+}
+
+// expect reads c's messages until one of type msgType, which it decodes
+// into payload. Fails if none arrives within a few seconds.
+func expect(t *testing.T, c *Client, msgType models.WSMessageType, payload interface{}) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case data := <-c.send:
+			var msg struct {
+				Type    models.WSMessageType `json:"type"`
+				Payload json.RawMessage      `json:"payload"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Type != msgType {
+				continue
+			}
+			if payload != nil {
+				if err := json.Unmarshal(msg.Payload, payload); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return
+		case <-timeout:
+			t.Fatalf("%s never sent %s", c.Username, msgType)
+		}
+	}
+}
+
+func TestCasualPlayersQueueApart(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	bob := newTestClient(h, "bob")
+	carol := newTestClient(h, "carol")
+
+	send(t, h, alice, models.WSTypeJoinQueue, models.JoinQueuePayload{Casual: true})
+	expect(t, alice, models.WSTypeQueueJoined, nil)
+	send(t, h, bob, models.WSTypeJoinQueue, models.JoinQueuePayload{})
+	expect(t, bob, models.WSTypeQueueJoined, nil)
+	send(t, h, carol, models.WSTypeJoinQueue, models.JoinQueuePayload{Casual: true})
+
+	var started models.GameStartedPayload
+	expect(t, carol, models.WSTypeGameStarted, &started)
+	if started.Opponent != "alice" || started.Rated {
+		t.Fatalf("carol's game = %+v, want a casual game against alice", started)
+	}
+	expect(t, alice, models.WSTypeGameStarted, &started)
+	if started.Opponent != "carol" || started.Rated {
+		t.Fatalf("alice's game = %+v, want a casual game against carol", started)
+	}
+	if session := h.findPlayerGame("bob"); session != nil {
+		t.Errorf("bob asked for a rated game but was matched into %s", session.Game.ID)
+	}
+	session := h.findPlayerGame("alice")
+	if session == nil || session.Rated {
+		t.Fatal("alice should be in a casual game")
+	}
+
+	// Takebacks are disabled in rated games here, but casual games allow them
+	first, second := alice, carol
+	if !started.YourTurn {
+		first, second = carol, alice
+	}
+	send(t, h, first, models.WSTypeMakeMove, models.MakeMovePayload{Column: 3})
+	send(t, h, first, models.WSTypeRequestTakeback, nil)
+	var request models.OfferPayload
+	expect(t, second, models.WSTypeTakebackRequested, &request)
+	if request.From != first.Username {
+		t.Errorf("takeback requested by %q, want %q", request.From, first.Username)
+	}
+}
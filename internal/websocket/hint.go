@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// handleRequestHint suggests a move for the player to move, with the bot's
+// reason for it. Only casual and bot games allow hints, up to the per-game
+// quota.
+func (h *MessageHandler) handleRequestHint(client *Client) {
+	session := h.findPlayerGame(client.Username)
+	if session == nil || session.Game.GetStatus() != game.GameStatusInProgress {
+		client.SendError("Not in a game")
+		return
+	}
+	if session.Rated {
+		client.SendError("Hints are only available in casual and bot games")
+		return
+	}
+	if session.Game.Variant != game.VariantClassic || session.Game.Cylinder {
+		client.SendError("Hints are only available in classic games")
+		return
+	}
+	color := colorOf(session, client.Username)
+	if session.Game.GetCurrentPlayer() != color {
+		client.SendError("Not your turn")
+		return
+	}
+
+	h.hub.mu.Lock()
+	used := session.hintsUsed[client.Username]
+	if used >= h.hub.hintsPerGame {
+		h.hub.mu.Unlock()
+		if h.hub.hintsPerGame == 0 {
+			client.SendError("Hints are disabled")
+		} else {
+			client.SendError("No hints left in this game")
+		}
+		return
+	}
+	session.hintsUsed[client.Username] = used + 1
+	h.hub.mu.Unlock()
+
+	board, _ := session.Game.Snapshot()
+	hint := h.hinter.Explain(board, color)
+	client.SendMessage(models.WSTypeHint, models.HintPayload{
+		Column:    hint.Column,
+		Row:       hint.Row,
+		Reason:    string(hint.Reason),
+		Text:      hint.Text,
+		HintsLeft: h.hub.hintsPerGame - used - 1,
+	})
+
+	log.Info().
+		Str("username", client.Username).
+		Str("gameId", session.Game.ID.String()).
+		Str("reason", string(hint.Reason)).
+		Msg("Hint given")
+}
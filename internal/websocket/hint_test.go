@@ -0,0 +1,55 @@
+package websocket
+
+import (
+	"testing"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+func TestHintInCasualGame(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	bob := newTestClient(h, "bob")
+
+	send(t, h, alice, models.WSTypeJoinQueue, models.JoinQueuePayload{Casual: true})
+	expect(t, alice, models.WSTypeQueueJoined, nil)
+	send(t, h, bob, models.WSTypeJoinQueue, models.JoinQueuePayload{Casual: true})
+	var started models.GameStartedPayload
+	expect(t, alice, models.WSTypeGameStarted, &started)
+	expect(t, bob, models.WSTypeGameStarted, nil)
+
+	first, second := alice, bob
+	if !started.YourTurn {
+		first, second = bob, alice
+	}
+	// first stacks three in column 0 while second plays out of the way
+	for _, c := range []*Client{first, second, first, second, first} {
+		col := 0
+		if c == second {
+			col = 6
+		}
+		send(t, h, c, models.WSTypeMakeMove, models.MakeMovePayload{Column: col})
+	}
+
+	send(t, h, second, models.WSTypeRequestHint, nil)
+	var hint models.HintPayload
+	expect(t, second, models.WSTypeHint, &hint)
+	if hint.Column != 0 || hint.Reason != "blocks_threat" || hint.HintsLeft != 2 {
+		t.Errorf("hint = %+v, want a block in column 0 with 2 hints left", hint)
+	}
+}
+
+func TestNoHintInRatedGame(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	bob := newTestClient(h, "bob")
+	h.startGame(alice, bob, game.Setup{Variant: game.VariantClassic}, true, nil, nil)
+
+	send(t, h, alice, models.WSTypeRequestHint, nil)
+	var refused models.ErrorPayload
+	expect(t, alice, models.WSTypeError, &refused)
+	if refused.Message != "Hints are only available in casual and bot games" {
+		t.Errorf("error = %q", refused.Message)
+	}
+}
@@ -1,6 +1,7 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 	"time"
@@ -8,7 +9,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 
+	"connect-four/internal/bot"
 	"connect-four/internal/game"
+	"connect-four/internal/kafka"
+	"connect-four/internal/metrics"
 	"connect-four/internal/models"
 )
 
@@ -23,6 +27,21 @@ type Hub struct {
 	// Player to game mapping
 	playerGames map[string]uuid.UUID
 
+	// Spectator to watched game mapping
+	spectating map[string]uuid.UUID
+
+	// Pending rematches; both players of a finished game map to the same entry
+	rematches map[string]*rematch
+
+	// Puzzles being solved, by username
+	puzzles map[string]*puzzleSession
+
+	// Active games for three or four players by game ID, and the game each
+	// human seat is in. Kept apart from games so nothing built for two
+	// players mistakes them for its own.
+	multiGames   map[uuid.UUID]*multiSession
+	multiPlayers map[string]uuid.UUID
+
 	// Matchmaking queue
 	matchQueue chan *Client
 
@@ -39,6 +58,26 @@ type Hub struct {
 	matchmakingTimeout time.Duration
 	reconnectTimeout   time.Duration
 	botMoveDelay       time.Duration
+	botMoveBudget      time.Duration
+	ratedTakebackLimit int
+	hintsPerGame       int
+
+	// Analytics event publisher (optional)
+	kafkaProducer *kafka.Producer
+
+	// Set by the MessageHandler: onForfeit runs the game-over flow for games
+	// the hub forfeits on disconnect timeout, onSeriesAbandoned persists a
+	// series that can't continue, onPuzzleAbandoned records a puzzle left
+	// unfinished and onMultiTimeout eliminates a player who didn't come back
+	// to a multiplayer game. All are called without h.mu held.
+	onForfeit         func(session *GameSession)
+	onSeriesAbandoned func(series *models.Series)
+	onPuzzleAbandoned func(username string, ps *puzzleSession)
+	onMultiTimeout    func(session *multiSession, player game.Cell)
+
+	// Runs in its own goroutine after a client registers (see
+	// MessageHandler.OnConnect)
+	onConnect func(username string)
 }
 
 // GameSession wraps a game with its connected clients
@@ -47,20 +86,68 @@ type GameSession struct {
 	Player1 *Client
 	Player2 *Client // nil if bot game
 	IsBot   bool
+
+	// Engine playing Player2 in a bot game, nil otherwise, and its
+	// personality if it is the built-in bot playing one
+	Engine      bot.Engine
+	Personality string
+
+	// Cancelled when the game ends, so the bot stops thinking and a move
+	// it was working on is dropped
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Spectators watching the game, by username. Guarded by Hub.mu.
+	Spectators map[string]*Client
+
+	// Muted[a][b] means a doesn't receive chat or emotes from b. Guarded by Hub.mu.
+	Muted map[string]map[string]bool
+
+	// Series this game belongs to, nil for a standalone game
+	Series *models.Series
+
+	// Tournament round this game was paired for, nil outside tournaments
+	Tournament *models.TournamentInfoPayload
+
+	// Abandoned is set when the loser walked away from the match, timing
+	// out after a disconnect or abandoning the session, rather than
+	// resigning this game. Guarded by Hub.mu.
+	Abandoned bool
+
+	// Rated games count toward player stats and limit takebacks
+	Rated bool
+
+	// Pending draw offer / takeback request (username, empty if none),
+	// move count at each player's last draw offer, takebacks and hints
+	// used. Guarded by Hub.mu.
+	DrawOfferedBy       string
+	TakebackRequestedBy string
+	lastDrawOffer       map[string]int
+	takebacksUsed       map[string]int
+	hintsUsed           map[string]int
 }
 
 // NewHub creates a new Hub instance
-func NewHub(matchmakingTimeout, reconnectTimeout, botMoveDelay time.Duration) *Hub {
+func NewHub(matchmakingTimeout, reconnectTimeout, botMoveDelay, botMoveBudget time.Duration, ratedTakebackLimit, hintsPerGame int, kafkaProducer *kafka.Producer) *Hub {
 	return &Hub{
 		clients:            make(map[string]*Client),
 		games:              make(map[uuid.UUID]*GameSession),
 		playerGames:        make(map[string]uuid.UUID),
+		spectating:         make(map[string]uuid.UUID),
+		rematches:          make(map[string]*rematch),
+		puzzles:            make(map[string]*puzzleSession),
+		multiGames:         make(map[uuid.UUID]*multiSession),
+		multiPlayers:       make(map[string]uuid.UUID),
 		matchQueue:         make(chan *Client, 100),
 		register:           make(chan *Client),
 		unregister:         make(chan *Client),
 		matchmakingTimeout: matchmakingTimeout,
 		reconnectTimeout:   reconnectTimeout,
 		botMoveDelay:       botMoveDelay,
+		botMoveBudget:      botMoveBudget,
+		ratedTakebackLimit: ratedTakebackLimit,
+		hintsPerGame:       hintsPerGame,
+		kafkaProducer:      kafkaProducer,
 	}
 }
 
@@ -75,6 +162,9 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.handleRegister(client)
+			if h.onConnect != nil {
+				go h.onConnect(client.Username)
+			}
 
 		case client := <-h.unregister:
 			h.handleUnregister(client)
@@ -88,18 +178,18 @@ func (h *Hub) handleRegister(client *Client) {
 	defer h.mu.Unlock()
 
 	h.clients[client.Username] = client
+	metrics.ConnectedClients.Set(float64(len(h.clients)))
+	h.publish(func(p *kafka.Producer) {
+		p.PublishPlayerConnected(context.Background(), client.Username)
+	})
 
 	// Check if player has an active game session
 	if gameID, exists := h.playerGames[client.Username]; exists {
 		if session, ok := h.games[gameID]; ok {
 			// Send existing session notification to let user choose
-			opponentName := "Bot"
-			if session.Game.Player2 != nil && !session.IsBot {
-				if session.Game.Player1.Username == client.Username {
-					opponentName = session.Game.Player2.Username
-				} else {
-					opponentName = session.Game.Player1.Username
-				}
+			opponentName := session.Game.Player2.Username
+			if session.Game.Player2.Username == client.Username {
+				opponentName = session.Game.Player1.Username
 			}
 			client.SendMessage(models.WSTypeExistingSession, models.ExistingSessionPayload{
 				GameID:   gameID.String(),
@@ -110,6 +200,16 @@ func (h *Hub) handleRegister(client *Client) {
 			return
 		}
 	}
+	if gameID, exists := h.multiPlayers[client.Username]; exists {
+		if session, ok := h.multiGames[gameID]; ok {
+			client.SendMessage(models.WSTypeExistingSession, models.ExistingSessionPayload{
+				GameID:  gameID.String(),
+				Players: session.seats(),
+			})
+			log.Info().Str("username", client.Username).Str("gameId", gameID.String()).Msg("Existing session found")
+			return
+		}
+	}
 
 	log.Info().Str("username", client.Username).Msg("Client registered")
 }
@@ -120,16 +220,38 @@ func (h *Hub) handleUnregister(client *Client) {
 
 	if _, ok := h.clients[client.Username]; ok {
 		delete(h.clients, client.Username)
+		metrics.ConnectedClients.Set(float64(len(h.clients)))
 
 		// Mark client as closed to prevent further sends
 		client.closed = true
 
 		// Check if player was in a game (before closing channel)
+		var activeGame *uuid.UUID
 		if gameID, exists := h.playerGames[client.Username]; exists {
 			if session, ok := h.games[gameID]; ok {
+				activeGame = &gameID
 				h.handleDisconnection(client, session)
 			}
 		}
+		if gameID, exists := h.multiPlayers[client.Username]; exists {
+			if session, ok := h.multiGames[gameID]; ok {
+				activeGame = &gameID
+				h.handleMultiDisconnection(client, session)
+			}
+		}
+		h.publish(func(p *kafka.Producer) {
+			p.PublishPlayerDisconnected(context.Background(), client.Username, activeGame)
+		})
+
+		h.stopSpectating(client.Username)
+		h.cancelRematch(client.Username, "Opponent disconnected")
+
+		if ps, ok := h.puzzles[client.Username]; ok {
+			delete(h.puzzles, client.Username)
+			if h.onPuzzleAbandoned != nil {
+				go h.onPuzzleAbandoned(client.Username, ps)
+			}
+		}
 
 		// Close the send channel AFTER handling disconnection
 		close(client.send)
@@ -154,8 +276,18 @@ func (h *Hub) handleReconnection(client *Client, session *GameSession) {
 		session.Player1 = client
 	}
 
+	// Capture how long the player was away before clearing the timestamp
+	var offlineFor time.Duration
+	if info := session.Game.GetPlayerInfo(playerColor); info != nil && info.DisconnectedAt != nil {
+		offlineFor = time.Since(*info.DisconnectedAt)
+	}
+
 	// Mark as reconnected
 	session.Game.SetReconnected(playerColor)
+	gameID := session.Game.ID
+	h.publish(func(p *kafka.Producer) {
+		p.PublishPlayerReconnected(context.Background(), client.Username, gameID, offlineFor)
+	})
 
 	// Send current game state
 	client.SendMessage(models.WSTypeGameState, models.GameStatePayload{
@@ -165,6 +297,8 @@ func (h *Hub) handleReconnection(client *Client, session *GameSession) {
 		YourColor:   int(playerColor),
 		YourTurn:    session.Game.CurrentTurn == playerColor,
 		Opponent:    session.Game.GetOpponentInfo(playerColor).Username,
+		Variant:     string(session.Game.Variant),
+		Cylinder:    session.Game.Cylinder,
 	})
 
 	// Notify opponent
@@ -211,14 +345,15 @@ func (h *Hub) startReconnectTimer(session *GameSession, disconnectedPlayer game.
 	time.Sleep(h.reconnectTimeout)
 
 	h.mu.Lock()
-	defer h.mu.Unlock()
 
 	// Check if game still exists and player still disconnected
 	if session.Game.Status != game.GameStatusDisconnected {
+		h.mu.Unlock()
 		return
 	}
 
 	// Forfeit the game
+	session.Abandoned = true
 	session.Game.Forfeit(disconnectedPlayer)
 
 	// Notify the connected opponent
@@ -235,21 +370,83 @@ func (h *Hub) startReconnectTimer(session *GameSession, disconnectedPlayer game.
 		})
 	}
 
+	log.Info().Str("gameId", session.Game.ID.String()).Msg("Game forfeited due to disconnect timeout")
+
+	// Persistence, events and cleanup happen in the game-over flow when wired
+	if h.onForfeit != nil {
+		h.mu.Unlock()
+		h.onForfeit(session)
+		return
+	}
+
+	var winnerName string
+	if winnerInfo != nil {
+		winnerName = winnerInfo.Username
+	}
+	gameID, duration, totalMoves := session.Game.ID, session.Game.Duration(), len(session.Game.Moves)
+	h.publish(func(p *kafka.Producer) {
+		p.PublishGameEnded(context.Background(), gameID, winnerName, "forfeit", duration, totalMoves)
+	})
+
 	// Cleanup
 	h.cleanupGame(session)
-
-	log.Info().Str("gameId", session.Game.ID.String()).Msg("Game forfeited due to disconnect timeout")
+	h.mu.Unlock()
 }
 
 // cleanupGame removes a finished game from tracking
 func (h *Hub) cleanupGame(session *GameSession) {
+	session.cancel()
 	delete(h.games, session.Game.ID)
 	if session.Game.Player1 != nil {
 		delete(h.playerGames, session.Game.Player1.Username)
 	}
-	if session.Game.Player2 != nil {
+	if session.Game.Player2 != nil && !session.IsBot {
 		delete(h.playerGames, session.Game.Player2.Username)
 	}
+	for username := range session.Spectators {
+		delete(h.spectating, username)
+	}
+	if session.Engine != nil {
+		// Stopping an external process can block; don't hold the lock for it
+		go func(engine bot.Engine, gameID string) {
+			if err := engine.Close(); err != nil {
+				log.Warn().Err(err).Str("gameId", gameID).Msg("Bot engine crashed")
+			}
+		}(session.Engine, session.Game.ID.String())
+	}
+	h.updateGameMetrics()
+}
+
+// playing reports whether a player has a seat in an active game of either
+// kind. Caller must hold h.mu.
+func (h *Hub) playing(username string) bool {
+	_, inGame := h.playerGames[username]
+	_, inMulti := h.multiPlayers[username]
+	return inGame || inMulti
+}
+
+// updateGameMetrics refreshes the active game gauges. Caller must hold h.mu.
+func (h *Hub) updateGameMetrics() {
+	var pvp, bot int
+	for _, session := range h.games {
+		if session.IsBot {
+			bot++
+		} else {
+			pvp++
+		}
+	}
+	metrics.ActiveGames.WithLabelValues(metrics.GameTypePvP).Set(float64(pvp))
+	metrics.ActiveGames.WithLabelValues(metrics.GameTypeBot).Set(float64(bot))
+	metrics.ActiveGames.WithLabelValues(metrics.GameTypeMulti).Set(float64(len(h.multiGames)))
+}
+
+// publish hands an analytics event to the Kafka producer without blocking
+// the caller, which is usually holding the hub lock
+func (h *Hub) publish(fn func(p *kafka.Producer)) {
+	if h.kafkaProducer == nil {
+		return
+	}
+	go fn(h.kafkaProducer)
 }
 
 // GetClient returns a client by username
@@ -282,10 +479,12 @@ func (h *Hub) BroadcastToGame(gameID uuid.UUID, msgType models.WSMessageType, pa
 	if session.Player2 != nil {
 		session.Player2.SendMessage(msgType, payload)
 	}
+	h.NotifySpectators(session, msgType, payload)
 }
 
-// CreateGame creates a new game session
-func (h *Hub) CreateGame(player1, player2 *Client, isBot bool) *GameSession {
+// CreateGame creates a new game session played by setup's rules and board,
+// rated or casual
+func (h *Hub) CreateGame(player1, player2 *Client, isBot, rated bool, setup game.Setup) *GameSession {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -312,13 +511,23 @@ func (h *Hub) CreateGame(player1, player2 *Client, isBot bool) *GameSession {
 		}
 	}
 
-	g := game.NewGame(p1Info, p2Info)
+	g := game.NewSetupGame(p1Info, p2Info, setup)
+	ctx, cancel := context.WithCancel(context.Background())
 
 	session := &GameSession{
-		Game:    g,
-		Player1: player1,
-		Player2: player2,
-		IsBot:   isBot,
+		Game:       g,
+		Player1:    player1,
+		Player2:    player2,
+		IsBot:      isBot,
+		Spectators: make(map[string]*Client),
+		Muted:      make(map[string]map[string]bool),
+		Rated:      rated,
+		ctx:        ctx,
+		cancel:     cancel,
+
+		lastDrawOffer: make(map[string]int),
+		takebacksUsed: make(map[string]int),
+		hintsUsed:     make(map[string]int),
 	}
 
 	h.games[g.ID] = session
@@ -326,6 +535,7 @@ func (h *Hub) CreateGame(player1, player2 *Client, isBot bool) *GameSession {
 	if player2 != nil {
 		h.playerGames[player2.Username] = g.ID
 	}
+	h.updateGameMetrics()
 
 	return session
 }
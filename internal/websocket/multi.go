@@ -0,0 +1,537 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/bot"
+	"connect-four/internal/game"
+	"connect-four/internal/matchmaking"
+	"connect-four/internal/models"
+)
+
+// Reasons a seat of a multiplayer game got its place
+const (
+	placedConnected = "connected"
+	placedLeft      = "left"
+	placedFinished  = "finished"
+)
+
+// multiSession wraps a game for three or four players with the clients of
+// its human seats. Bots play the other seats.
+type multiSession struct {
+	Game *game.MultiGame
+
+	// Clients of the human seats by color. Guarded by Hub.mu.
+	Clients map[game.Cell]*Client
+
+	// Plays every bot seat; the bot keeps no state between moves
+	bot *bot.MultiBot
+
+	// Cancelled when the game ends, so a bot waiting to move gives up
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Serializes moves and eliminations with the messages announcing them,
+	// so every client sees them in the order they happened. announced
+	// marks the seats whose place has been sent; botsPlaying is set while
+	// a goroutine plays the bot seats. Guarded by mu.
+	mu          sync.Mutex
+	announced   map[game.Cell]bool
+	botsPlaying bool
+}
+
+// seats lists every seat in color order
+func (s *multiSession) seats() []models.SeatPayload {
+	seats := make([]models.SeatPayload, len(s.Game.Players))
+	for i, p := range s.Game.Players {
+		seats[i] = models.SeatPayload{Username: p.Username, Color: i + 1, IsBot: p.IsBot}
+	}
+	return seats
+}
+
+// broadcastMulti sends a message to every connected human seat
+func (h *Hub) broadcastMulti(session *multiSession, msgType models.WSMessageType, payload interface{}) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(session.Clients))
+	for _, c := range session.Clients {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.SendMessage(msgType, payload)
+	}
+}
+
+// createMultiGame seats the players in a new multiplayer game. Players who
+// went offline or started another game since joining the lobby are
+// replaced by bots, as are the empty seats. Returns nil if no human is
+// left to play.
+func (h *Hub) createMultiGame(usernames []string, seats int, rule game.MultiRule) (*multiSession, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := make(map[game.Cell]*Client)
+	players := make([]*game.PlayerInfo, 0, seats)
+	for _, username := range usernames {
+		client := h.clients[username]
+		if client == nil || client.closed || h.playing(username) {
+			continue
+		}
+		players = append(players, &game.PlayerInfo{ID: uuid.New(), Username: username, Connected: true})
+		clients[game.Cell(len(players))] = client
+	}
+	if len(clients) == 0 {
+		return nil, nil
+	}
+	for n := 1; len(players) < seats; n++ {
+		players = append(players, &game.PlayerInfo{ID: uuid.New(), Username: fmt.Sprintf("Bot %d", n), IsBot: true})
+	}
+
+	g, err := game.NewMultiGame(players, rule)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	session := &multiSession{
+		Game:      g,
+		Clients:   clients,
+		bot:       bot.NewMultiBot(),
+		ctx:       ctx,
+		cancel:    cancel,
+		announced: make(map[game.Cell]bool),
+	}
+
+	h.multiGames[g.ID] = session
+	for _, c := range clients {
+		h.multiPlayers[c.Username] = g.ID
+	}
+	h.updateGameMetrics()
+	return session, nil
+}
+
+// cleanupMultiGame removes a finished multiplayer game from tracking.
+// Caller must hold h.mu.
+func (h *Hub) cleanupMultiGame(session *multiSession) {
+	session.cancel()
+	delete(h.multiGames, session.Game.ID)
+	for _, p := range session.Game.Players {
+		if !p.IsBot {
+			delete(h.multiPlayers, p.Username)
+		}
+	}
+	h.updateGameMetrics()
+}
+
+// handleMultiDisconnection marks a player of a multiplayer game offline and
+// gives them the reconnect timeout to come back. Caller must hold h.mu.
+func (h *Hub) handleMultiDisconnection(client *Client, session *multiSession) {
+	player := session.Game.Seat(client.Username)
+	session.Game.SetConnected(player, false)
+	delete(session.Clients, player)
+
+	for _, c := range session.Clients {
+		c.SendMessage(models.WSTypeOpponentDisconnected, models.OpponentDisconnectedPayload{
+			Timeout:  int(h.reconnectTimeout.Seconds()),
+			Username: client.Username,
+		})
+	}
+
+	go h.startMultiReconnectTimer(session, player)
+
+	log.Info().Str("username", client.Username).Str("gameId", session.Game.ID.String()).Msg("Player disconnected from multiplayer game")
+}
+
+// startMultiReconnectTimer waits for reconnection or eliminates the player
+func (h *Hub) startMultiReconnectTimer(session *multiSession, player game.Cell) {
+	time.Sleep(h.reconnectTimeout)
+
+	if session.Game.IsGameOver() || session.Game.IsConnected(player) || session.Game.Place(player) != 0 {
+		return
+	}
+	log.Info().Str("gameId", session.Game.ID.String()).Int("player", int(player)).Msg("Player eliminated due to disconnect timeout")
+	if h.onMultiTimeout != nil {
+		h.onMultiTimeout(session, player)
+	}
+}
+
+// handleMultiReconnection puts a player back in their seat and sends them
+// the game as it stands. Caller must hold h.mu.
+func (h *Hub) handleMultiReconnection(client *Client, session *multiSession) {
+	h.clients[client.Username] = client
+	player := session.Game.Seat(client.Username)
+	session.Clients[player] = client
+	session.Game.SetConnected(player, true)
+
+	grid, turn := session.Game.Snapshot()
+	client.SendMessage(models.WSTypeGameState, models.GameStatePayload{
+		GameID:      session.Game.ID.String(),
+		Board:       grid.ToSlice(),
+		CurrentTurn: int(turn),
+		YourColor:   int(player),
+		YourTurn:    turn == player,
+		Players:     session.seats(),
+	})
+
+	for seat, c := range session.Clients {
+		if seat != player {
+			c.SendMessage(models.WSTypeOpponentReconnected, models.OpponentReconnectedPayload{Username: client.Username})
+		}
+	}
+
+	log.Info().Str("username", client.Username).Str("gameId", session.Game.ID.String()).Msg("Player reconnected to multiplayer game")
+}
+
+// findMultiGame finds the multiplayer game a player has a seat in
+func (h *MessageHandler) findMultiGame(username string) *multiSession {
+	h.hub.mu.RLock()
+	defer h.hub.mu.RUnlock()
+
+	if gameID, ok := h.hub.multiPlayers[username]; ok {
+		return h.hub.multiGames[gameID]
+	}
+	return nil
+}
+
+// handleJoinLobby seats a player in the lobby for the table they asked for
+func (h *MessageHandler) handleJoinLobby(client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.JoinLobbyPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid lobby payload")
+		return
+	}
+	if req.Seats < game.MinMultiPlayers || req.Seats > game.MaxMultiPlayers {
+		client.SendError(fmt.Sprintf("Lobbies have %d to %d seats", game.MinMultiPlayers, game.MaxMultiPlayers))
+		return
+	}
+	if !game.MultiRule(req.Rule).Valid() {
+		client.SendError("Unknown rule")
+		return
+	}
+
+	h.hub.mu.Lock()
+	if h.hub.playing(client.Username) {
+		h.hub.mu.Unlock()
+		client.SendError("Already in a game")
+		return
+	}
+	h.hub.cancelRematch(client.Username, "Opponent joined a lobby")
+	h.hub.mu.Unlock()
+
+	// A player waits for one kind of game at a time
+	h.matchQueue.RemovePlayer(client.Username)
+	h.leaveLobby(client.Username)
+
+	lobby, ok := h.lobbies.Join(client.Username, matchmaking.Table{Seats: req.Seats, Rule: req.Rule})
+	if !ok {
+		client.SendError("Already in a lobby")
+		return
+	}
+	if len(lobby.Players) < lobby.Table.Seats {
+		h.notifyLobby(lobby)
+	}
+}
+
+// handleLeaveLobby takes a player out of their lobby
+func (h *MessageHandler) handleLeaveLobby(client *Client) {
+	h.leaveLobby(client.Username)
+}
+
+// leaveLobby takes a player out of any lobby and tells the players still
+// waiting
+func (h *MessageHandler) leaveLobby(username string) {
+	if lobby, ok := h.lobbies.Leave(username); ok && len(lobby.Players) > 0 {
+		h.notifyLobby(lobby)
+	}
+}
+
+// notifyLobby sends the lobby's state to everyone waiting in it
+func (h *MessageHandler) notifyLobby(lobby matchmaking.Lobby) {
+	remaining := h.hub.matchmakingTimeout - time.Since(lobby.OpenedAt)
+	if remaining < 0 {
+		remaining = 0
+	}
+	update := models.LobbyPayload{
+		Seats:   lobby.Table.Seats,
+		Rule:    lobby.Table.Rule,
+		Players: lobby.Players,
+		Timeout: int(remaining.Seconds()),
+	}
+	for _, username := range lobby.Players {
+		if c := h.hub.GetClient(username); c != nil {
+			c.SendMessage(models.WSTypeLobbyUpdate, update)
+		}
+	}
+}
+
+// startMultiGame starts the game of a lobby that filled up or timed out.
+// Bots take the empty seats and those of players who went missing since
+// they joined, so the count the lobby reports isn't needed.
+func (h *MessageHandler) startMultiGame(lobby matchmaking.Lobby, _ int) {
+	session, err := h.hub.createMultiGame(lobby.Players, lobby.Table.Seats, game.MultiRule(lobby.Table.Rule))
+	if err != nil {
+		log.Error().Err(err).Int("seats", lobby.Table.Seats).Str("rule", lobby.Table.Rule).Msg("Failed to start multiplayer game")
+		return
+	}
+	if session == nil {
+		log.Info().Int("seats", lobby.Table.Seats).Msg("Lobby emptied before its game could start")
+		return
+	}
+
+	g := session.Game
+	seats := session.seats()
+	h.hub.mu.RLock()
+	for player, c := range session.Clients {
+		c.SendMessage(models.WSTypeGameStarted, models.GameStartedPayload{
+			GameID:    g.ID.String(),
+			YourTurn:  player == game.Player1,
+			YourColor: int(player),
+			Players:   seats,
+			Rows:      g.Grid.Rows,
+			Columns:   g.Grid.Columns,
+			Rule:      string(g.Rule),
+		})
+	}
+	h.hub.mu.RUnlock()
+
+	log.Info().
+		Str("gameId", g.ID.String()).
+		Int("seats", len(g.Players)).
+		Int("humans", len(session.Clients)).
+		Str("rule", string(g.Rule)).
+		Msg("Multiplayer game started")
+
+	h.playMultiBots(session)
+}
+
+// handleMultiMove processes a move in a multiplayer game
+func (h *MessageHandler) handleMultiMove(ctx context.Context, client *Client, session *multiSession, col int) {
+	player := session.Game.Seat(client.Username)
+	if errMsg := h.applyMultiMove(ctx, session, player, col); errMsg != "" {
+		client.SendMessage(models.WSTypeInvalidMove, models.InvalidMovePayload{Reason: errMsg})
+		return
+	}
+	h.playMultiBots(session)
+}
+
+// applyMultiMove plays a move and announces it, any places it decided and
+// the end of the game. Returns an error message if the move was illegal.
+func (h *MessageHandler) applyMultiMove(ctx context.Context, session *multiSession, player game.Cell, col int) string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	row, errMsg := session.Game.MakeMove(player, col)
+	if errMsg != "" {
+		return errMsg
+	}
+
+	grid, next := session.Game.Snapshot()
+	if session.Game.IsGameOver() {
+		next = game.Empty
+	}
+	h.hub.broadcastMulti(session, models.WSTypeMoveMade, models.MoveMadePayload{
+		Column:     col,
+		Row:        row,
+		Player:     int(player),
+		Board:      grid.ToSlice(),
+		NextPlayer: int(next),
+	})
+	h.announcePlacements(ctx, session, player, placedConnected)
+	return ""
+}
+
+// eliminateMulti takes a player who left or timed out out of the game.
+// They hear their place and are then free to play something else.
+func (h *MessageHandler) eliminateMulti(ctx context.Context, session *multiSession, player game.Cell) {
+	session.mu.Lock()
+	if !session.Game.Eliminate(player) {
+		session.mu.Unlock()
+		return
+	}
+	h.announcePlacements(ctx, session, player, placedLeft)
+
+	h.hub.mu.Lock()
+	delete(session.Clients, player)
+	if h.hub.multiPlayers[session.Game.PlayerInfo(player).Username] == session.Game.ID {
+		delete(h.hub.multiPlayers, session.Game.PlayerInfo(player).Username)
+	}
+	h.hub.mu.Unlock()
+	session.mu.Unlock()
+
+	h.playMultiBots(session)
+}
+
+// announcePlacements sends player_placed for every seat that got its place
+// since the last announcement: actor for reason, anyone else because the
+// game ended around them. Ends the game once it is over. Caller must hold
+// session.mu.
+func (h *MessageHandler) announcePlacements(ctx context.Context, session *multiSession, actor game.Cell, reason string) {
+	for _, p := range session.Game.Placements() {
+		if p.Place == 0 || session.announced[p.Player] {
+			continue
+		}
+		session.announced[p.Player] = true
+		why := placedFinished
+		if p.Player == actor {
+			why = reason
+		}
+		h.hub.broadcastMulti(session, models.WSTypePlayerPlaced, models.PlacementPayload{
+			Username: session.Game.PlayerInfo(p.Player).Username,
+			Color:    int(p.Player),
+			Place:    p.Place,
+			Reason:   why,
+		})
+	}
+	if session.Game.IsGameOver() {
+		h.handleMultiGameOver(ctx, session)
+	}
+}
+
+// playMultiBots plays the bot seats while it is their turn, pausing like a
+// two-player bot would between moves, until a human's turn or the end of
+// the game. Only one goroutine plays a game's bots at a time.
+func (h *MessageHandler) playMultiBots(session *multiSession) {
+	if !session.botTurn(true) {
+		return
+	}
+	go func() {
+		for {
+			grid, turn := session.Game.Snapshot()
+
+			timer := time.NewTimer(h.hub.botMoveDelay)
+			select {
+			case <-timer.C:
+			case <-session.ctx.Done():
+				timer.Stop()
+				return
+			}
+
+			order := session.Game.TurnOrder()
+			if len(order) > 0 && order[0] == turn {
+				col := session.bot.SelectMove(grid, turn, order[1:])
+				if errMsg := h.applyMultiMove(context.Background(), session, turn, col); errMsg != "" {
+					log.Debug().Str("error", errMsg).Str("gameId", session.Game.ID.String()).Msg("Dropped stale bot move")
+				}
+			}
+			// Otherwise someone left while the bot was waiting; look again
+			if !session.botTurn(false) {
+				return
+			}
+		}
+	}()
+}
+
+// botTurn reports whether a bot is to move in a game still going, claiming
+// the bot goroutine for the caller if so. starting is true for a caller
+// that wants to start it, false for the goroutine itself, which gives it
+// up when it returns false.
+func (s *multiSession) botTurn(starting bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if starting && s.botsPlaying {
+		return false
+	}
+	info := s.Game.PlayerInfo(s.Game.GetCurrentPlayer())
+	s.botsPlaying = !s.Game.IsGameOver() && info != nil && info.IsBot
+	return s.botsPlaying
+}
+
+// multiResult is the game_over result for a seat: a win for a sole first
+// place, a draw for a shared one and a loss otherwise
+func multiResult(placements []game.Placement, player game.Cell) string {
+	firsts, place := 0, 0
+	for _, p := range placements {
+		if p.Place == 1 {
+			firsts++
+		}
+		if p.Player == player {
+			place = p.Place
+		}
+	}
+	switch {
+	case place != 1:
+		return "loss"
+	case firsts == 1:
+		return "win"
+	default:
+		return "draw"
+	}
+}
+
+// handleMultiGameOver sends game over messages, records the results and
+// cleans up. Caller must hold session.mu.
+func (h *MessageHandler) handleMultiGameOver(ctx context.Context, session *multiSession) {
+	session.cancel()
+
+	g := session.Game
+	placements := g.Placements()
+	payloads := make([]models.PlacementPayload, len(placements))
+	winnerName := "draw"
+	for i, p := range placements {
+		payloads[i] = models.PlacementPayload{
+			Username: g.PlayerInfo(p.Player).Username,
+			Color:    int(p.Player),
+			Place:    p.Place,
+		}
+		if multiResult(placements, p.Player) == "win" {
+			winnerName = payloads[i].Username
+		}
+	}
+	finalBoard := g.Grid.ToSlice()
+
+	h.hub.mu.RLock()
+	for player, c := range session.Clients {
+		c.SendMessage(models.WSTypeGameOver, models.GameOverPayload{
+			Winner:     winnerName,
+			Result:     multiResult(placements, player),
+			FinalBoard: finalBoard,
+			Placements: payloads,
+		})
+	}
+	h.hub.mu.RUnlock()
+
+	log.Info().
+		Str("gameId", g.ID.String()).
+		Str("winner", winnerName).
+		Int("moves", len(g.Moves)).
+		Msg("Multiplayer game ended")
+
+	// Bots and ratings are left out: Elo is a two-player measure
+	if h.playerRepo != nil {
+		for _, p := range placements {
+			info := g.PlayerInfo(p.Player)
+			if info.IsBot {
+				continue
+			}
+			player, err := h.playerRepo.Create(ctx, info.Username)
+			if err != nil {
+				log.Error().Err(err).Str("username", info.Username).Msg("Failed to create/get player")
+				continue
+			}
+			switch multiResult(placements, p.Player) {
+			case "win":
+				err = h.playerRepo.IncrementWins(ctx, player.ID)
+			case "draw":
+				err = h.playerRepo.IncrementDraws(ctx, player.ID)
+			default:
+				err = h.playerRepo.IncrementLosses(ctx, player.ID)
+			}
+			if err != nil {
+				log.Error().Err(err).Str("username", info.Username).Msg("Failed to update stats")
+			}
+		}
+	}
+
+	h.hub.mu.Lock()
+	h.hub.cleanupMultiGame(session)
+	h.hub.mu.Unlock()
+}
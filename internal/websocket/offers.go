@@ -0,0 +1,239 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// colorOf returns the color a player has in a session
+func colorOf(session *GameSession, username string) game.Cell {
+	if session.Game.Player2 != nil && session.Game.Player2.Username == username {
+		return game.Player2
+	}
+	return game.Player1
+}
+
+// opponentOf returns the connected client playing against username, if any
+func opponentOf(session *GameSession, username string) *Client {
+	if colorOf(session, username) == game.Player1 {
+		return session.Player2
+	}
+	return session.Player1
+}
+
+// clearStaleOffers drops a pending draw offer or takeback request once the
+// other player moves instead of answering. Caller must hold h.mu.
+func (h *Hub) clearStaleOffers(session *GameSession, mover string) {
+	if session.DrawOfferedBy != mover {
+		session.DrawOfferedBy = ""
+	}
+	if session.TakebackRequestedBy != mover {
+		session.TakebackRequestedBy = ""
+	}
+}
+
+// handleOfferDraw offers the opponent a draw
+func (h *MessageHandler) handleOfferDraw(client *Client) {
+	session := h.findPlayerGame(client.Username)
+	if session == nil || session.Game.GetStatus() != game.GameStatusInProgress {
+		client.SendError("Not in a game")
+		return
+	}
+
+	if session.IsBot {
+		client.SendMessage(models.WSTypeDrawDeclined, models.OfferDeclinedPayload{
+			By:     session.Game.Player2.Username,
+			Reason: "The bot plays on",
+		})
+		return
+	}
+
+	h.hub.mu.Lock()
+	if session.DrawOfferedBy != "" {
+		h.hub.mu.Unlock()
+		client.SendError("A draw offer is already pending")
+		return
+	}
+	moves := session.Game.MoveCount()
+	if last, ok := session.lastDrawOffer[client.Username]; ok && last == moves {
+		h.hub.mu.Unlock()
+		client.SendError("You can offer a draw once per move")
+		return
+	}
+	session.lastDrawOffer[client.Username] = moves
+	session.DrawOfferedBy = client.Username
+	opponent := opponentOf(session, client.Username)
+	h.hub.mu.Unlock()
+
+	if opponent != nil {
+		opponent.SendMessage(models.WSTypeDrawOffered, models.OfferPayload{From: client.Username})
+	}
+	log.Info().Str("username", client.Username).Str("gameId", session.Game.ID.String()).Msg("Draw offered")
+}
+
+// handleRespondDraw accepts or declines the opponent's draw offer
+func (h *MessageHandler) handleRespondDraw(ctx context.Context, client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.RespondOfferPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid response payload")
+		return
+	}
+
+	session := h.findPlayerGame(client.Username)
+	if session == nil {
+		client.SendError("Not in a game")
+		return
+	}
+
+	h.hub.mu.Lock()
+	offeredBy := session.DrawOfferedBy
+	if offeredBy == "" || offeredBy == client.Username {
+		h.hub.mu.Unlock()
+		client.SendError("No draw offer to respond to")
+		return
+	}
+	session.DrawOfferedBy = ""
+	offerer := opponentOf(session, client.Username)
+	h.hub.mu.Unlock()
+
+	if !req.Accept {
+		if offerer != nil {
+			offerer.SendMessage(models.WSTypeDrawDeclined, models.OfferDeclinedPayload{By: client.Username})
+		}
+		return
+	}
+
+	if !session.Game.AgreeDraw() {
+		client.SendError("Game already finished")
+		return
+	}
+	log.Info().Str("gameId", session.Game.ID.String()).Msg("Draw agreed")
+	h.handleGameOver(ctx, session)
+}
+
+// handleRequestTakeback asks to take back the player's last turn, which
+// some rules make several moves long. Bot games apply it immediately;
+// against a player the opponent has to agree, and rated games only allow as
+// many takebacks as the configured limit.
+func (h *MessageHandler) handleRequestTakeback(ctx context.Context, client *Client) {
+	session := h.findPlayerGame(client.Username)
+	if session == nil || session.Game.GetStatus() != game.GameStatusInProgress {
+		client.SendError("Not in a game")
+		return
+	}
+	color := colorOf(session, client.Username)
+
+	if session.IsBot {
+		// Undo the bot's replies and the player's turn before them
+		if session.Game.GetCurrentPlayer() != color {
+			client.SendError("Wait for the bot to move")
+			return
+		}
+		undone, ok := session.Game.UndoTurn(color)
+		if !ok {
+			client.SendError("No move to take back")
+			return
+		}
+		h.sendTakebackApplied(session, undone)
+		if session.Game.GetCurrentPlayer() != color {
+			go h.makeBotMove(ctx, session)
+		}
+		return
+	}
+
+	if session.Game.GetCurrentPlayer() == color || session.Game.MoveCount() == 0 {
+		client.SendError("You can only take back your own last move")
+		return
+	}
+
+	h.hub.mu.Lock()
+	if session.Rated && session.takebacksUsed[client.Username] >= h.hub.ratedTakebackLimit {
+		h.hub.mu.Unlock()
+		if h.hub.ratedTakebackLimit == 0 {
+			client.SendError("Takebacks are disabled in rated games")
+		} else {
+			client.SendError("No takebacks left in this game")
+		}
+		return
+	}
+	if session.TakebackRequestedBy != "" {
+		h.hub.mu.Unlock()
+		client.SendError("A takeback request is already pending")
+		return
+	}
+	session.TakebackRequestedBy = client.Username
+	opponent := opponentOf(session, client.Username)
+	h.hub.mu.Unlock()
+
+	if opponent != nil {
+		opponent.SendMessage(models.WSTypeTakebackRequested, models.OfferPayload{From: client.Username})
+	}
+	log.Info().Str("username", client.Username).Str("gameId", session.Game.ID.String()).Msg("Takeback requested")
+}
+
+// handleRespondTakeback accepts or declines the opponent's takeback request
+func (h *MessageHandler) handleRespondTakeback(client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.RespondOfferPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid response payload")
+		return
+	}
+
+	session := h.findPlayerGame(client.Username)
+	if session == nil {
+		client.SendError("Not in a game")
+		return
+	}
+
+	h.hub.mu.Lock()
+	requestedBy := session.TakebackRequestedBy
+	if requestedBy == "" || requestedBy == client.Username {
+		h.hub.mu.Unlock()
+		client.SendError("No takeback request to respond to")
+		return
+	}
+	session.TakebackRequestedBy = ""
+	requester := opponentOf(session, client.Username)
+
+	if !req.Accept {
+		h.hub.mu.Unlock()
+		if requester != nil {
+			requester.SendMessage(models.WSTypeTakebackDeclined, models.OfferDeclinedPayload{By: client.Username})
+		}
+		return
+	}
+
+	// The request only covers the requester's own last turn
+	if session.Game.GetCurrentPlayer() != colorOf(session, client.Username) {
+		h.hub.mu.Unlock()
+		client.SendError("Takeback no longer applies")
+		return
+	}
+	undone, ok := session.Game.UndoTurn(colorOf(session, requestedBy))
+	if !ok {
+		h.hub.mu.Unlock()
+		client.SendError("No move to take back")
+		return
+	}
+	session.takebacksUsed[requestedBy]++
+	h.hub.mu.Unlock()
+
+	h.sendTakebackApplied(session, undone)
+}
+
+// sendTakebackApplied tells players and spectators the position after a takeback
+func (h *MessageHandler) sendTakebackApplied(session *GameSession, undone int) {
+	h.hub.BroadcastToGame(session.Game.ID, models.WSTypeTakebackApplied, models.TakebackAppliedPayload{
+		Board:       session.Game.Board.ToSlice(),
+		CurrentTurn: int(session.Game.GetCurrentPlayer()),
+		MovesUndone: undone,
+	})
+	log.Info().Str("gameId", session.Game.ID.String()).Int("moves", undone).Msg("Takeback applied")
+}
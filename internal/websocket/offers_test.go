@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"reflect"
+	"testing"
+
+	"connect-four/internal/bot"
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// awaitTurn reads c's messages until a move by the opponent leaves c's
+// color to move
+func awaitTurn(t *testing.T, c *Client, color game.Cell) {
+	t.Helper()
+	for {
+		var move models.MoveMadePayload
+		expect(t, c, models.WSTypeMoveMade, &move)
+		if move.Player != int(color) && move.NextPlayer == int(color) {
+			return
+		}
+	}
+}
+
+func TestTakebackUndoesWholeTurnAgainstBot(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	h.startBotGame(alice, bot.DefaultEngine, game.Setup{Variant: game.VariantClassic})
+	session := h.findPlayerGame("alice")
+	if session == nil {
+		t.Fatal("no bot game started")
+	}
+
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 0})
+	awaitTurn(t, alice, game.Player1)
+	before, moves := session.Game.Board.ToSlice(), session.Game.MoveCount()
+
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 6})
+	awaitTurn(t, alice, game.Player1)
+
+	// The takeback covers alice's move and the bot's reply to it
+	send(t, h, alice, models.WSTypeRequestTakeback, nil)
+	var applied models.TakebackAppliedPayload
+	expect(t, alice, models.WSTypeTakebackApplied, &applied)
+	if applied.MovesUndone != 2 || applied.CurrentTurn != int(game.Player1) {
+		t.Errorf("takeback = %+v, want 2 moves undone with alice to move", applied)
+	}
+	if !reflect.DeepEqual(applied.Board, before) || session.Game.MoveCount() != moves {
+		t.Errorf("board after the takeback %v, want it as alice's turn began %v", applied.Board, before)
+	}
+
+	// The bot still answers
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 3})
+	awaitTurn(t, alice, game.Player1)
+}
+
+func TestTakebackUndoesSeveralMoveTurnAgainstBot(t *testing.T) {
+	h := newTestHandler(t)
+	alice := newTestClient(h, "alice")
+	h.startBotGame(alice, bot.DefaultEngine, game.Setup{Variant: game.VariantPowerUp})
+	session := h.findPlayerGame("alice")
+	if session == nil {
+		t.Fatal("no bot game started")
+	}
+
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 0})
+	awaitTurn(t, alice, game.Player1)
+	before, moves := session.Game.Board.ToSlice(), session.Game.MoveCount()
+
+	// A double disc and the extra drop it gives are one turn
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 6, Disc: string(game.DiscDouble)})
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 6})
+	awaitTurn(t, alice, game.Player1)
+	played := session.Game.MoveCount() - moves
+	if played < 3 {
+		t.Fatalf("expected both drops and the bot's reply, got %d moves", played)
+	}
+
+	send(t, h, alice, models.WSTypeRequestTakeback, nil)
+	var applied models.TakebackAppliedPayload
+	expect(t, alice, models.WSTypeTakebackApplied, &applied)
+	if applied.MovesUndone != played || applied.CurrentTurn != int(game.Player1) {
+		t.Errorf("takeback = %+v, want %d moves undone with alice to move", applied, played)
+	}
+	if !reflect.DeepEqual(applied.Board, before) || session.Game.MoveCount() != moves {
+		t.Errorf("board after the takeback %v, want it as alice's turn began %v", applied.Board, before)
+	}
+
+	// The double disc is back, and the bot still answers
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 3, Disc: string(game.DiscDouble)})
+	send(t, h, alice, models.WSTypeMakeMove, models.MakeMovePayload{Column: 3})
+	awaitTurn(t, alice, game.Player1)
+}
@@ -0,0 +1,265 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+	"connect-four/internal/rating"
+	"connect-four/internal/solver"
+)
+
+// puzzleSession is a puzzle a player is solving. The server plays the
+// defending side; it lives in Hub.puzzles and is only touched by its
+// player's own messages.
+type puzzleSession struct {
+	puzzle    *models.Puzzle
+	game      *game.Game
+	attacker  game.Cell
+	movesLeft int  // attacker moves left to win, the next one included
+	rated     bool // first attempt at this puzzle
+}
+
+// handleStartPuzzle starts a puzzle by ID, or the daily puzzle
+func (h *MessageHandler) handleStartPuzzle(ctx context.Context, client *Client, payload interface{}) {
+	if h.puzzleRepo == nil {
+		client.SendError("Puzzles are not available")
+		return
+	}
+
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.StartPuzzlePayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid puzzle payload")
+		return
+	}
+
+	h.hub.mu.RLock()
+	busy := h.hub.playing(client.Username)
+	h.hub.mu.RUnlock()
+	if busy {
+		client.SendError("Finish your game before starting a puzzle")
+		return
+	}
+
+	var puzzle *models.Puzzle
+	var err error
+	if req.PuzzleID == "" {
+		puzzle, err = h.puzzleRepo.Daily(ctx, time.Now())
+	} else {
+		id, parseErr := uuid.Parse(req.PuzzleID)
+		if parseErr != nil {
+			client.SendError("Invalid puzzle ID")
+			return
+		}
+		puzzle, err = h.puzzleRepo.GetByID(ctx, id)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("username", client.Username).Msg("Failed to load puzzle")
+		client.SendError("Failed to load puzzle")
+		return
+	}
+	if puzzle == nil {
+		client.SendError("Puzzle not found")
+		return
+	}
+
+	board, err := game.DecodeBoard(puzzle.Position)
+	if err != nil {
+		log.Error().Err(err).Str("puzzleId", puzzle.ID.String()).Msg("Stored puzzle position is invalid")
+		client.SendError("Failed to load puzzle")
+		return
+	}
+
+	attacker := game.Cell(puzzle.ToMove)
+	you := &game.PlayerInfo{Username: client.Username, Connected: true}
+	defender := &game.PlayerInfo{Username: "Puzzle", IsBot: true, Connected: true}
+	p1, p2 := you, defender
+	if attacker == game.Player2 {
+		p1, p2 = defender, you
+	}
+
+	attempted, err := h.puzzleRepo.HasAttempted(ctx, puzzle.ID, client.Username)
+	if err != nil {
+		log.Error().Err(err).Str("username", client.Username).Msg("Failed to check puzzle attempts")
+	}
+	ps := &puzzleSession{
+		puzzle:    puzzle,
+		game:      game.NewGameFromBoard(p1, p2, board, attacker),
+		attacker:  attacker,
+		movesLeft: puzzle.Moves,
+		rated:     err == nil && !attempted,
+	}
+
+	playerRating := rating.DefaultRating
+	if player, err := h.playerRepo.GetByUsername(ctx, client.Username); err == nil && player != nil {
+		playerRating = player.PuzzleRating
+	} else {
+		// Guests can play puzzles but have no rating to change
+		ps.rated = false
+	}
+
+	h.hub.mu.Lock()
+	previous := h.hub.puzzles[client.Username]
+	h.hub.puzzles[client.Username] = ps
+	h.hub.mu.Unlock()
+	if previous != nil {
+		h.recordPuzzle(ctx, client.Username, previous, false)
+	}
+
+	client.SendMessage(models.WSTypePuzzleStarted, models.PuzzleStartedPayload{
+		PuzzleID:     puzzle.ID.String(),
+		Board:        board.ToSlice(),
+		YourColor:    int(attacker),
+		Moves:        puzzle.Moves,
+		Rating:       puzzle.Rating,
+		Rated:        ps.rated,
+		PlayerRating: playerRating,
+	})
+
+	log.Info().Str("username", client.Username).Str("puzzleId", puzzle.ID.String()).Bool("rated", ps.rated).Msg("Puzzle started")
+}
+
+// handlePuzzleMove checks a puzzle move and answers with the best defence.
+// Any move that still forces a win in time is accepted, not just the
+// stored solution.
+func (h *MessageHandler) handlePuzzleMove(ctx context.Context, client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var move models.MakeMovePayload
+	if err := json.Unmarshal(payloadBytes, &move); err != nil {
+		client.SendError("Invalid move payload")
+		return
+	}
+
+	h.hub.mu.RLock()
+	ps := h.hub.puzzles[client.Username]
+	h.hub.mu.RUnlock()
+	if ps == nil {
+		client.SendError("No puzzle in progress")
+		return
+	}
+
+	board, _ := ps.game.Snapshot()
+	winning := solver.WinningMoves(board, ps.attacker, ps.movesLeft)
+
+	if _, errMsg := ps.game.MakeMove(ps.attacker, move.Column); errMsg != "" {
+		client.SendMessage(models.WSTypeInvalidMove, models.InvalidMovePayload{Reason: errMsg})
+		return
+	}
+
+	if ps.game.IsGameOver() {
+		h.finishPuzzle(ctx, client, ps, ps.game.Winner == ps.attacker, winning)
+		return
+	}
+	if !containsColumn(winning, move.Column) {
+		h.finishPuzzle(ctx, client, ps, false, winning)
+		return
+	}
+
+	ps.movesLeft--
+	defender := opponentColor(ps.attacker)
+	board, _ = ps.game.Snapshot()
+	reply := solver.Defend(board, defender, ps.movesLeft)
+	row, errMsg := ps.game.MakeMove(defender, reply)
+	if errMsg != "" || ps.game.IsGameOver() {
+		// The stored line was wrong; don't hold it against the player
+		log.Warn().Str("puzzleId", ps.puzzle.ID.String()).Str("reason", errMsg).Msg("Puzzle defence ended the game")
+		h.finishPuzzle(ctx, client, ps, true, nil)
+		return
+	}
+
+	client.SendMessage(models.WSTypePuzzleReply, models.PuzzleReplyPayload{
+		Column:    reply,
+		Row:       row,
+		Board:     ps.game.Board.ToSlice(),
+		MovesLeft: ps.movesLeft,
+	})
+}
+
+// finishPuzzle ends a puzzle session, records the attempt and reports it
+func (h *MessageHandler) finishPuzzle(ctx context.Context, client *Client, ps *puzzleSession, solved bool, solution []int) {
+	h.hub.mu.Lock()
+	if h.hub.puzzles[client.Username] == ps {
+		delete(h.hub.puzzles, client.Username)
+	}
+	h.hub.mu.Unlock()
+
+	change, playerRating := h.recordPuzzle(ctx, client.Username, ps, solved)
+
+	result := models.PuzzleFinishedPayload{
+		PuzzleID:     ps.puzzle.ID.String(),
+		Solved:       solved,
+		Rated:        ps.rated,
+		RatingChange: change,
+		PlayerRating: playerRating,
+	}
+	if !solved {
+		result.Solution = solution
+	}
+	client.SendMessage(models.WSTypePuzzleFinished, result)
+
+	log.Info().Str("username", client.Username).Str("puzzleId", ps.puzzle.ID.String()).Bool("solved", solved).Int("ratingChange", change).Msg("Puzzle finished")
+}
+
+// recordPuzzle stores an attempt and, for a player's first attempt, moves
+// the player's and the puzzle's ratings. Returns the player's rating change
+// and their puzzle rating afterwards.
+func (h *MessageHandler) recordPuzzle(ctx context.Context, username string, ps *puzzleSession, solved bool) (int, int) {
+	playerRating := rating.DefaultRating
+	player, err := h.playerRepo.GetByUsername(ctx, username)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to load player for puzzle rating")
+	}
+	if player != nil {
+		playerRating = player.PuzzleRating
+	}
+
+	change := 0
+	puzzleRating := ps.puzzle.Rating
+	if ps.rated && player != nil {
+		score := 0.0
+		if solved {
+			score = 1
+		}
+		newPlayer, newPuzzle := rating.Update(playerRating, puzzleRating, score)
+		change = newPlayer - playerRating
+		playerRating, puzzleRating = newPlayer, newPuzzle
+
+		if err := h.playerRepo.UpdatePuzzleRating(ctx, player.ID, playerRating); err != nil {
+			log.Error().Err(err).Str("username", username).Msg("Failed to update puzzle rating")
+		}
+	}
+
+	attempt := &models.PuzzleAttempt{
+		PuzzleID:     ps.puzzle.ID,
+		Username:     username,
+		Solved:       solved,
+		Rated:        ps.rated,
+		RatingChange: change,
+	}
+	if err := h.puzzleRepo.RecordAttempt(ctx, attempt, puzzleRating); err != nil {
+		log.Error().Err(err).Str("username", username).Msg("Failed to record puzzle attempt")
+	}
+	return change, playerRating
+}
+
+func containsColumn(cols []int, col int) bool {
+	for _, c := range cols {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+func opponentColor(player game.Cell) game.Cell {
+	if player == game.Player1 {
+		return game.Player2
+	}
+	return game.Player1
+}
@@ -0,0 +1,253 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// rematch tracks two players who just finished a game against each other and
+// may play again. Both usernames map to the same entry in Hub.rematches.
+type rematch struct {
+	player1, player2 string         // seats in the game that just ended
+	setup            game.Setup     // the rematch keeps the rules and board
+	rated            bool           // and is rated if the game was
+	series           *models.Series // unfinished series the next game continues
+	offeredBy        string         // empty until someone offers
+	bestOf           int
+}
+
+// opponent returns the other player of the rematch
+func (r *rematch) opponent(username string) string {
+	if username == r.player1 {
+		return r.player2
+	}
+	return r.player1
+}
+
+// openRematch lets the players of a finished game offer each other a rematch.
+// Caller must hold h.mu.
+func (h *Hub) openRematch(session *GameSession) {
+	r := &rematch{
+		player1: session.Game.Player1.Username,
+		player2: session.Game.Player2.Username,
+		setup:   session.Game.Setup(),
+		rated:   session.Rated,
+	}
+	if session.Series != nil && session.Series.Status == models.SeriesStatusInProgress {
+		r.series = session.Series
+	}
+
+	h.cancelRematch(r.player1, "Opponent started another game")
+	h.cancelRematch(r.player2, "Opponent started another game")
+	h.rematches[r.player1] = r
+	h.rematches[r.player2] = r
+}
+
+// cancelRematch drops a player's pending rematch, tells the opponent why and
+// abandons the series it would have continued. Caller must hold h.mu.
+func (h *Hub) cancelRematch(username, reason string) {
+	r, ok := h.rematches[username]
+	if !ok {
+		return
+	}
+	h.removeRematch(r)
+
+	if opponent := h.clients[r.opponent(username)]; opponent != nil {
+		opponent.SendMessage(models.WSTypeRematchDeclined, models.RematchDeclinedPayload{
+			Username: username,
+			Reason:   reason,
+		})
+	}
+	if r.series != nil && h.onSeriesAbandoned != nil {
+		go h.onSeriesAbandoned(r.series)
+	}
+}
+
+// removeRematch forgets a rematch for both players. Caller must hold h.mu.
+func (h *Hub) removeRematch(r *rematch) {
+	if h.rematches[r.player1] == r {
+		delete(h.rematches, r.player1)
+	}
+	if h.rematches[r.player2] == r {
+		delete(h.rematches, r.player2)
+	}
+}
+
+// handleOfferRematch offers the last opponent another game, or a new series
+func (h *MessageHandler) handleOfferRematch(client *Client, payload interface{}) {
+	payloadBytes, _ := json.Marshal(payload)
+	var req models.OfferRematchPayload
+	if err := json.Unmarshal(payloadBytes, &req); err != nil {
+		client.SendError("Invalid rematch payload")
+		return
+	}
+
+	h.hub.mu.Lock()
+	defer h.hub.mu.Unlock()
+
+	r, ok := h.hub.rematches[client.Username]
+	if !ok {
+		client.SendError("No recent opponent to rematch")
+		return
+	}
+
+	opponent := h.hub.clients[r.opponent(client.Username)]
+	if opponent == nil {
+		h.hub.cancelRematch(client.Username, "Opponent left")
+		client.SendError("Opponent is no longer online")
+		return
+	}
+
+	bestOf := 1
+	switch {
+	case r.series != nil:
+		bestOf = r.series.BestOf
+	case req.BestOf > 1:
+		if !models.SeriesLengths[req.BestOf] {
+			client.SendError("Series must be best of 3, 5 or 7")
+			return
+		}
+		bestOf = req.BestOf
+	}
+
+	r.offeredBy = client.Username
+	r.bestOf = bestOf
+
+	offer := models.RematchOfferedPayload{From: client.Username, BestOf: bestOf}
+	if r.series != nil {
+		offer.SeriesID = r.series.ID.String()
+	}
+	opponent.SendMessage(models.WSTypeRematchOffered, offer)
+
+	log.Info().Str("from", client.Username).Str("to", opponent.Username).Int("bestOf", bestOf).Msg("Rematch offered")
+}
+
+// handleAcceptRematch starts the next game with colors swapped
+func (h *MessageHandler) handleAcceptRematch(ctx context.Context, client *Client) {
+	h.hub.mu.Lock()
+
+	r, ok := h.hub.rematches[client.Username]
+	if !ok || r.offeredBy == "" || r.offeredBy == client.Username {
+		h.hub.mu.Unlock()
+		client.SendError("No rematch offer to accept")
+		return
+	}
+
+	offerer := h.hub.clients[r.offeredBy]
+	if offerer == nil {
+		h.hub.cancelRematch(client.Username, "Opponent left")
+		h.hub.mu.Unlock()
+		client.SendError("Opponent is no longer online")
+		return
+	}
+	if h.hub.playing(client.Username) || h.hub.playing(offerer.Username) {
+		h.hub.mu.Unlock()
+		client.SendError("A player is already in another game")
+		return
+	}
+
+	h.hub.removeRematch(r)
+	h.hub.mu.Unlock()
+
+	h.matchQueue.RemovePlayer(client.Username)
+	h.matchQueue.RemovePlayer(offerer.Username)
+
+	// Whoever moved second last game moves first now
+	clients := map[string]*Client{client.Username: client, offerer.Username: offerer}
+	first, second := clients[r.player2], clients[r.player1]
+
+	series := r.series
+	if series == nil && r.bestOf > 1 {
+		series = &models.Series{
+			Player1Username: first.Username,
+			Player2Username: second.Username,
+			BestOf:          r.bestOf,
+			Status:          models.SeriesStatusInProgress,
+		}
+		if h.seriesRepo != nil {
+			if err := h.seriesRepo.Create(ctx, series); err != nil {
+				log.Error().Err(err).Msg("Failed to store series")
+			}
+		}
+		log.Info().
+			Str("seriesId", series.ID.String()).
+			Str("player1", first.Username).
+			Str("player2", second.Username).
+			Int("bestOf", series.BestOf).
+			Msg("Series started")
+	}
+
+	h.startGame(first, second, r.setup, r.rated, series, nil)
+}
+
+// handleDeclineRematch turns down a rematch offer, ending any series
+func (h *MessageHandler) handleDeclineRematch(client *Client) {
+	h.hub.mu.Lock()
+	h.hub.cancelRematch(client.Username, "Rematch declined")
+	h.hub.mu.Unlock()
+}
+
+// scoreSeries adds a finished game to its series and reports the running score
+func (h *MessageHandler) scoreSeries(ctx context.Context, session *GameSession) {
+	series := session.Series
+
+	var winner string
+	switch session.Game.Winner {
+	case game.Player1:
+		winner = session.Game.Player1.Username
+	case game.Player2:
+		winner = session.Game.Player2.Username
+	}
+	series.RecordGame(winner)
+
+	if h.seriesRepo != nil {
+		if err := h.seriesRepo.Update(ctx, series); err != nil {
+			log.Error().Err(err).Str("seriesId", series.ID.String()).Msg("Failed to update series")
+		}
+	}
+
+	update := newSeriesPayload(series)
+	if session.Player1 != nil {
+		session.Player1.SendMessage(models.WSTypeSeriesUpdate, update)
+	}
+	if session.Player2 != nil {
+		session.Player2.SendMessage(models.WSTypeSeriesUpdate, update)
+	}
+	h.hub.NotifySpectators(session, models.WSTypeSeriesUpdate, update)
+
+	if update.Finished {
+		log.Info().Str("seriesId", series.ID.String()).Str("winner", series.WinnerUsername).Msg("Series finished")
+	}
+}
+
+// abandonSeries marks a series that can't continue as abandoned
+func (h *MessageHandler) abandonSeries(series *models.Series) {
+	series.Abandon()
+	if h.seriesRepo != nil {
+		if err := h.seriesRepo.Update(context.Background(), series); err != nil {
+			log.Error().Err(err).Str("seriesId", series.ID.String()).Msg("Failed to update series")
+		}
+	}
+	log.Info().Str("seriesId", series.ID.String()).Msg("Series abandoned")
+}
+
+// newSeriesPayload converts a series to its wire representation
+func newSeriesPayload(series *models.Series) *models.SeriesPayload {
+	return &models.SeriesPayload{
+		SeriesID:    series.ID.String(),
+		BestOf:      series.BestOf,
+		Player1:     series.Player1Username,
+		Player2:     series.Player2Username,
+		Player1Wins: series.Player1Wins,
+		Player2Wins: series.Player2Wins,
+		Draws:       series.Draws,
+		GamesPlayed: series.GamesPlayed(),
+		Finished:    series.Status != models.SeriesStatusInProgress,
+		Winner:      series.WinnerUsername,
+	}
+}
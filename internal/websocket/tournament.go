@@ -0,0 +1,74 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+// ErrPlayerBusy is returned when a player is already in a game
+var ErrPlayerBusy = errors.New("player is already in a game")
+
+// OnGameFinished registers a callback for the end of every tournament game.
+// winner is game.Empty for a draw; abandoned is set when the loser left the
+// match, timing out after disconnecting or abandoning the session, and not
+// when they merely resigned the game. It runs after the session is cleaned up.
+func (h *MessageHandler) OnGameFinished(fn func(ctx context.Context, gameID uuid.UUID, winner game.Cell, abandoned bool)) {
+	h.onGameFinished = fn
+}
+
+// Available reports whether a player is connected and free to start a game
+func (h *MessageHandler) Available(username string) bool {
+	h.hub.mu.RLock()
+	defer h.hub.mu.RUnlock()
+
+	client, ok := h.hub.clients[username]
+	if !ok || client.closed {
+		return false
+	}
+	return !h.hub.playing(username)
+}
+
+// StartTournamentGame starts a live game for a tournament pairing, taking
+// both players out of matchmaking and any pending rematch
+func (h *MessageHandler) StartTournamentGame(player1, player2 string, info models.TournamentInfoPayload) (uuid.UUID, error) {
+	h.hub.mu.Lock()
+	p1, p2 := h.hub.clients[player1], h.hub.clients[player2]
+	if p1 == nil || p2 == nil {
+		h.hub.mu.Unlock()
+		return uuid.Nil, ErrClientNotFound
+	}
+	if h.hub.playing(player1) || h.hub.playing(player2) {
+		h.hub.mu.Unlock()
+		return uuid.Nil, ErrPlayerBusy
+	}
+	h.hub.stopSpectating(player1)
+	h.hub.stopSpectating(player2)
+	h.hub.cancelRematch(player1, "Opponent started a tournament game")
+	h.hub.cancelRematch(player2, "Opponent started a tournament game")
+	h.hub.mu.Unlock()
+
+	h.matchQueue.RemovePlayer(player1)
+	h.matchQueue.RemovePlayer(player2)
+
+	session := h.startGame(p1, p2, game.Setup{Variant: game.VariantClassic}, true, nil, &info)
+
+	log.Info().
+		Str("tournamentId", info.TournamentID).
+		Int("round", info.Round).
+		Str("gameId", session.Game.ID.String()).
+		Msg("Tournament game started")
+	return session.Game.ID, nil
+}
+
+// Notify sends a message to a player if they are connected
+func (h *MessageHandler) Notify(username string, msgType models.WSMessageType, payload interface{}) {
+	if client := h.hub.GetClient(username); client != nil {
+		client.SendMessage(msgType, payload)
+	}
+}
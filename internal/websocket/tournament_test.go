@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"connect-four/internal/game"
+	"connect-four/internal/models"
+)
+
+func TestTournamentResignationIsNotAbandonment(t *testing.T) {
+	tests := []struct {
+		name      string
+		leave     models.WSMessageType
+		abandoned bool
+	}{
+		{"resigning the game", models.WSTypeLeaveGame, false},
+		{"abandoning the session", models.WSTypeAbandonSession, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			alice := newTestClient(h, "alice")
+			newTestClient(h, "bob")
+
+			type finished struct {
+				gameID    uuid.UUID
+				winner    game.Cell
+				abandoned bool
+			}
+			results := make(chan finished, 1)
+			h.OnGameFinished(func(_ context.Context, gameID uuid.UUID, winner game.Cell, abandoned bool) {
+				results <- finished{gameID, winner, abandoned}
+			})
+
+			info := models.TournamentInfoPayload{TournamentID: uuid.New().String(), Name: "Cup", Round: 1, Game: 1}
+			gameID, err := h.StartTournamentGame("alice", "bob", info)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expect(t, alice, models.WSTypeGameStarted, nil)
+
+			send(t, h, alice, tt.leave, nil)
+			var got finished
+			select {
+			case got = <-results:
+			case <-time.After(5 * time.Second):
+				t.Fatal("The tournament was never told the game ended")
+			}
+			if got.gameID != gameID || got.winner != game.Player2 {
+				t.Fatalf("finished %+v, want bob to win %s", got, gameID)
+			}
+			if got.abandoned != tt.abandoned {
+				t.Errorf("abandoned = %v, want %v", got.abandoned, tt.abandoned)
+			}
+		})
+	}
+}